@@ -0,0 +1,47 @@
+// Command backfill-roles gives the Firebase accounts created before the roles existed the role claim of their rows:
+// driver for the accounts in the users table, restaurant for the owners of a restaurant and restaurant_staff for the
+// other members. Accounts that already have a role are left alone, so it can be run again safely.
+//
+// It reads the same configuration as the API, e.g.
+//
+//	DB_CONNECTION_STRING=... FIREBASE_CONFIG_JSON=... go run ./cmd/backfill-roles
+package main
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/app/tamra/services"
+	"Tamra/internal/pkg/utils"
+	"Tamra/internal/pkg/utils/firebase"
+	"fmt"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	err := godotenv.Load()
+	if err != nil {
+		fmt.Println("Error loading .env file")
+	}
+
+	config := utils.GetConfig()
+	logger := utils.NewLogger(config.LogLevel)
+
+	db, err := utils.NewDB(config.DBConn)
+	if err != nil {
+		logrus.Panic("Failed to connect to the database: ", err)
+	}
+	defer db.Close()
+
+	firebaseAuthClient, err := firebase.NewFirebaseApp(config.FirebaseConfigJSON).FetchFirebaseAuthClient()
+	if err != nil {
+		logrus.Panic("Failed to initialize firebase auth: ", err)
+	}
+
+	authService := services.NewAuthService(services.NewFirebaseAuthProvider(firebaseAuthClient), repositories.NewUserRepository(db), repositories.NewRestaurantRepository(db), logger)
+	assigned, err := authService.BackfillRoles()
+	if err != nil {
+		logger.WithError(err).Fatalf("Failed to backfill the roles, %d accounts got a role before the failure", assigned)
+	}
+	logger.Infof("Backfilled the roles, %d accounts got a role", assigned)
+}
@@ -10,8 +10,10 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"Tamra/internal/pkg/utils/firebase"
+	"Tamra/internal/pkg/utils/sms"
 	"fmt"
 
 	"github.com/aws/aws-lambda-go/lambda"
@@ -59,17 +61,46 @@ func main() {
 	// Get the logger
 	logger := utils.NewLogger(config.LogLevel)
 
-	logger.Info("Configuration values before initializing firebase auth", config.FirebaseConfigJSON)
+	logger.Info("Initializing firebase")
 	firebaseApp := firebase.NewFirebaseApp(config.FirebaseConfigJSON)
 
-	firebaseAuthClient, err := firebaseApp.FetchFirebaseAuthClient()
-	if err != nil {
-		logrus.Panic("Failed to initialize firebase auth: ", err)
+	// Tokens are signed locally during development and in the tests, so they don't need a Firebase project
+	var tokenVerifier middleware.TokenVerifier
+	var authProvider services.AuthProvider
+	var localTokenIssuer *middleware.LocalTokenIssuer
+	if config.AuthProvider == "local" {
+		if config.Stage == "prod" || config.LocalAuthSecret == "" {
+			logrus.Panic("The local auth provider needs a secret and can't be used in production")
+		}
+		localTokenIssuer = middleware.NewLocalTokenIssuer(config.LocalAuthSecret, config.LocalAuthTokenTTL)
+		tokenVerifier = localTokenIssuer
+		authProvider = services.NewInMemoryAuthProvider()
+	} else {
+		firebaseAuthClient, err := firebaseApp.FetchFirebaseAuthClient()
+		if err != nil {
+			logrus.Panic("Failed to initialize firebase auth: ", err)
+		}
+		tokenVerifier = middleware.NewFirebaseTokenVerifier(firebaseAuthClient, config.AuthCheckRevoked, config.AuthRevocationCacheTTL)
+		authProvider = services.NewFirebaseAuthProvider(firebaseAuthClient)
 	}
 
-	firebaseMessagingClient, err := firebaseApp.FetchFirebaseMessagingClient()
-	if err != nil {
-		logrus.Panic("Failed to initialize firebase messaging client: ", err)
+	// Push notifications are only logged when developing locally without FCM credentials
+	var pushChannel services.NotificationChannel
+	if config.NotificationProvider == "log" {
+		pushChannel = services.NewLogChannel(services.ChannelPush, logger)
+	} else {
+		firebaseMessagingClient, err := firebaseApp.FetchFirebaseMessagingClient()
+		if err != nil {
+			logrus.Panic("Failed to initialize firebase messaging client: ", err)
+		}
+		pushChannel = services.NewFCMChannel(firebaseMessagingClient)
+	}
+
+	notificationChannels := []services.NotificationChannel{pushChannel}
+	if config.SMSGatewayURL != "" {
+		notificationChannels = append(notificationChannels, services.NewSMSChannel(sms.NewHTTPGateway(config.SMSGatewayURL, config.SMSGatewayAPIKey, config.SMSSender)))
+	} else if config.NotificationProvider == "log" {
+		notificationChannels = append(notificationChannels, services.NewLogChannel(services.ChannelSMS, logger))
 	}
 
 	// Get the validator
@@ -77,24 +108,63 @@ func main() {
 
 	userRepository := repositories.NewUserRepository(db)
 	restaurantRepository := repositories.NewRestaurantRepository(db)
+	organizationRepository := repositories.NewOrganizationRepository(db)
+	auditRepository := repositories.NewAuditRepository(db)
 	orderRepository := repositories.NewOrderRepository(db)
+	webhookRepository := repositories.NewWebhookRepository(db)
+	notificationRepository := repositories.NewNotificationRepository(db)
 
-	notificationService := services.NewNotificationService(logger, firebaseMessagingClient)
-	userService := services.NewUserService(userRepository, logger)
-	restaurantService := services.NewRestaurantService(restaurantRepository, logger)
-	orderService := services.NewOrderService(orderRepository, userRepository, notificationService, logger)
+	notificationService := services.NewNotificationService(notificationRepository, logger, services.DefaultNotificationPolicy(config.SMSFallbackDelay), notificationChannels...)
+	// Order events are shared between instances through Postgres, unless we only run a single instance
+	var orderEventBroker services.OrderEventBroker
+	var postgresOrderEventBroker *services.PostgresOrderEventBroker
+	if config.OrderEventsBackend == "memory" {
+		orderEventBroker = services.NewInProcessOrderEventBroker(logger)
+	} else {
+		postgresOrderEventBroker = services.NewPostgresOrderEventBroker(db, logger)
+		orderEventBroker = postgresOrderEventBroker
+	}
 
-	userHandler := handlers.NewUserHandler(userService, validator, logger)
+	penaltyPolicy := services.DefaultPenaltyPolicy()
+	if len(config.PenaltyCooldowns) > 0 {
+		penaltyPolicy = services.PenaltyPolicy{Cooldowns: config.PenaltyCooldowns, Window: config.PenaltyWindow}
+	}
+	penaltyService := services.NewPenaltyService(userRepository, notificationService, penaltyPolicy, logger)
+
+	locationPolicy := services.LocationPolicy{MinInterval: config.LocationMinInterval, MaxAccuracy: float64(config.LocationMaxAccuracy), MaxAge: config.LocationMaxAge}
+	authService := services.NewAuthService(authProvider, userRepository, restaurantRepository, logger)
+	userService := services.NewUserService(userRepository, orderRepository, penaltyService, authService, locationPolicy, logger)
+	restaurantService := services.NewRestaurantService(restaurantRepository, userRepository, authService, logger)
+	organizationService := services.NewOrganizationService(organizationRepository, restaurantRepository, logger)
+	auditService := services.NewAuditService(auditRepository, logger)
+	webhookService := services.NewWebhookService(webhookRepository, logger)
+	orderService := services.NewOrderService(orderRepository, userRepository, restaurantRepository, notificationService, penaltyService, orderEventBroker, webhookService, config.LocationTrailRetention, repositories.DispatchOptions{RankByScore: config.DispatchRankByScore}, logger)
+
+	userHandler := handlers.NewUserHandler(userService, validator, logger, config)
 	restaurantHandler := handlers.NewRestaurantHandler(restaurantService, validator, logger, config)
-	orderHandler := handlers.NewOrderHandler(orderService, validator, logger)
+	orderHandler := handlers.NewOrderHandler(orderService, organizationService, validator, logger)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService, restaurantService, orderService, validator, logger)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, validator, logger)
+	adminHandler := handlers.NewAdminHandler(userService, restaurantService, orderService, authService, auditService, validator, logger, config)
+	authHandler := handlers.NewAuthHandler(authService, localTokenIssuer, validator, logger)
 
-	userAuthMiddleware := middleware.UserAuthMiddleware(firebaseAuthClient, logger)
-	restaurantAuthMiddleware := middleware.RestaurantAuthMiddleware(firebaseAuthClient, logger)
+	// The routers decide which roles can use each route
+	requireRole := func(roles ...string) func(http.Handler) http.Handler {
+		return middleware.RequireRole(tokenVerifier, logger, roles...)
+	}
+
+	// Restaurant accounts act for the restaurant they are a member of
+	resolveRestaurant := func(memberRoles ...string) func(http.Handler) http.Handler {
+		return middleware.ResolveRestaurant(restaurantService, logger, memberRoles...)
+	}
 
 	logger.Info("Starting the server")
-	userRouter := routes.NewUserRouter(userHandler, userAuthMiddleware, logger)
-	restaurantRouter := routes.NewRestaurantRouter(restaurantHandler, restaurantAuthMiddleware, userAuthMiddleware, logger)
-	orderRouter := routes.NewOrderRouter(orderHandler, restaurantAuthMiddleware, userAuthMiddleware, logger)
+	userRouter := routes.NewUserRouter(userHandler, requireRole, logger)
+	restaurantRouter := routes.NewRestaurantRouter(restaurantHandler, webhookHandler, requireRole, resolveRestaurant, logger)
+	organizationRouter := routes.NewOrganizationRouter(organizationHandler, requireRole, resolveRestaurant, logger)
+	orderRouter := routes.NewOrderRouter(orderHandler, requireRole, resolveRestaurant, logger)
+	adminRouter := routes.NewAdminRouter(adminHandler, requireRole, middleware.AuditAdminActions(auditService, logger), logger)
+	authRouter := routes.NewAuthRouter(authHandler, requireRole, logger)
 	docsRouter := routes.NewDocsRouter(logger)
 
 	logger.Info("Creating a new chi router")
@@ -110,7 +180,10 @@ func main() {
 
 	r.Mount("/users", userRouter.GetRouter())
 	r.Mount("/restaurants", restaurantRouter.GetRouter())
+	r.Mount("/organizations", organizationRouter.GetRouter())
 	r.Mount("/orders", orderRouter.GetRouter())
+	r.Mount("/admin", adminRouter.GetRouter())
+	r.Mount("/auth", authRouter.GetRouter())
 	r.Mount("/docs", docsRouter.GetRouter())
 
 	logger.Info("Mounting the subrouter to the parent router")
@@ -121,13 +194,77 @@ func main() {
 
 	versionedRouter.Mount("/api/v1", r)
 
-	if os.Getenv("LAMBDA_TASK_ROOT") != "" {
+	// Jobs that have to run periodically. Long running instances run them on tickers, on Lambda the jobs function
+	// is invoked by scheduled events naming the job to run (see serverless.yml)
+	scheduledJobs := map[string]scheduledJob{
+		// Expire the orders drivers didn't respond to, so their events are published even if nobody lists the orders
+		"expire_stale_orders": {interval: time.Minute, run: orderService.ExpireStaleOrders},
+		// Retry the failed webhook deliveries and the ones whose first attempt didn't finish
+		"process_webhook_deliveries": {interval: 30 * time.Second, run: webhookService.ProcessDueDeliveries},
+		// Send new orders by SMS when the driver didn't acknowledge them in time
+		"send_notification_fallbacks": {interval: 15 * time.Second, run: notificationService.SendDueFallbacks},
+		// Location trails are only returned within the retention period, purging them deletes them for good
+		"purge_location_trails": {interval: time.Hour, run: orderService.PurgeLocationTrails},
+	}
+
+	if os.Getenv("LAMBDA_TASK_ROOT") != "" && os.Getenv("_HANDLER") == "jobs" {
+		// The jobs function shares the binary with the API, only its handler differs
+		lambda.Start(func(event scheduledJobEvent) error {
+			return runScheduledJob(scheduledJobs, event.Job, logger)
+		})
+	} else if os.Getenv("LAMBDA_TASK_ROOT") != "" {
 		// If we are running on AWS Lambda, we use the chiadapter to convert the chi router to a lambda handler
 		chiLambda := chiadapter.New(versionedRouter)
 		lambda.Start(chiLambda.Proxy)
 	} else {
+		// Streams are only served by long running instances, Lambda instances only publish the events
+		if postgresOrderEventBroker != nil {
+			go func() {
+				err := postgresOrderEventBroker.Listen(config.DBConn)
+				if err != nil {
+					logger.WithError(err).Error("Stopped listening to order events")
+				}
+			}()
+		}
+
+		for name, job := range scheduledJobs {
+			go func(name string, job scheduledJob) {
+				for range time.Tick(job.interval) {
+					// Errors are already logged by the job runner
+					_ = runScheduledJob(scheduledJobs, name, logger)
+				}
+			}(name, job)
+		}
+
 		// If we are not running on AWS Lambda, we start the server using the port from the configuration
 		strPort := ":" + strconv.Itoa(config.Port)
 		http.ListenAndServe(strPort, versionedRouter)
 	}
 }
+
+// scheduledJob is a job that runs every interval
+type scheduledJob struct {
+	interval time.Duration
+	run      func() error
+}
+
+// scheduledJobEvent is the input of the scheduled events invoking the jobs function
+type scheduledJobEvent struct {
+	Job string `json:"job"`
+}
+
+// runScheduledJob runs the job with the given name and logs its failure
+func runScheduledJob(jobs map[string]scheduledJob, name string, logger logrus.FieldLogger) error {
+	job, ok := jobs[name]
+	if !ok {
+		logger.Errorf("Unknown scheduled job %q", name)
+		return fmt.Errorf("unknown scheduled job %q", name)
+	}
+
+	err := job.run()
+	if err != nil {
+		logger.WithError(err).Errorf("Scheduled job %s failed", name)
+		return err
+	}
+	return nil
+}
@@ -15,56 +15,56 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/orders": {
-            "post": {
+        "/admin/audit-logs": {
+            "get": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Create a new order with the given request body",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a page of the changes made through the admin routes, the newest first. Failed attempts are logged too, with their status code",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "admin"
                 ],
-                "summary": "Create a new order",
+                "summary": "Get the admin audit logs",
                 "parameters": [
                     {
-                        "description": "Create Order Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateOrderRequest"
-                        }
+                        "type": "string",
+                        "description": "Firebase UID of the admin",
+                        "name": "admin_uid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of the page, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of logs to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created Order",
+                    "200": {
+                        "description": "Audit logs",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/models.Page-models_AdminAuditLog"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "404": {
-                        "description": "no user to receive order",
+                        "description": "invalid limit or offset",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to create order",
+                        "description": "failed to get audit logs",
                         "schema": {
                             "type": "string"
                         }
@@ -72,36 +72,88 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/restaurant": {
+        "/admin/orders": {
             "get": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Get all orders for a restaurant",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a page of the orders matching the filters, the newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "admin"
+                ],
+                "summary": "Search the orders",
+                "parameters": [
+                    {
+                        "enum": [
+                            "PENDING",
+                            "ACCEPTED",
+                            "REJECTED",
+                            "CANCELLED",
+                            "EXPIRED",
+                            "FULFILLED"
+                        ],
+                        "type": "string",
+                        "description": "State of the orders",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Code of the order",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Restaurant of the orders",
+                        "name": "restaurant_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Driver of the orders",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time the orders were created at or after",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time the orders were created before",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of the page, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of orders to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all orders for a restaurant",
                 "responses": {
                     "200": {
-                        "description": "Restaurant Orders",
+                        "description": "Orders",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Order"
-                            }
+                            "$ref": "#/definitions/models.Page-models_Order"
                         }
                     },
-                    "404": {
-                        "description": "order not found",
+                    "400": {
+                        "description": "invalid filter",
                         "schema": {
                             "type": "string"
                         }
@@ -115,32 +167,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/user": {
-            "get": {
+        "/admin/orders/{orderID}/cancel": {
+            "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Get all orders for a user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Cancel an order that isn't fulfilled or cancelled yet on behalf of its restaurant, the driver is notified like when the restaurant cancels it",
                 "tags": [
-                    "orders"
+                    "admin"
+                ],
+                "summary": "Force cancel an order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "orderID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all orders for a user",
                 "responses": {
                     "200": {
-                        "description": "User Orders",
+                        "description": "order cancelled",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Order"
-                            }
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "404": {
@@ -149,8 +207,14 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "409": {
+                        "description": "order can't be cancelled in its current state",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "failed to get orders",
+                        "description": "failed to cancel order",
                         "schema": {
                             "type": "string"
                         }
@@ -158,48 +222,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}/accept": {
-            "patch": {
+        "/admin/orders/{orderID}/reassign": {
+            "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Accept a order",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Expire an order that isn't fulfilled or cancelled yet on behalf of its restaurant and send it to another driver. The current driver is penalized unless they rejected it",
                 "tags": [
-                    "orders"
+                    "admin"
                 ],
-                "summary": "Accept a order",
+                "summary": "Force reassign an order",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Order ID",
-                        "name": "id",
+                        "name": "orderID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "order reassigned",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "invalid order ID",
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found or no user to receive it",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "order can't be reassigned in its current state",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to accept order",
+                        "description": "failed to reassign order",
                         "schema": {
                             "type": "string"
                         }
@@ -207,48 +277,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}/fulfill": {
-            "post": {
+        "/admin/restaurants": {
+            "get": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Fulfill a order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a page of the restaurants matching the filters, the newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "admin"
                 ],
-                "summary": "Fulfill a order",
+                "summary": "Search the restaurants",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the ID or name of the restaurants",
+                        "name": "q",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Organization of the restaurants",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of the page, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of restaurants to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Restaurants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Page-models_RestaurantResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid order ID",
+                        "description": "invalid filter",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to fulfill order",
+                        "description": "failed to get restaurants",
                         "schema": {
                             "type": "string"
                         }
@@ -256,48 +340,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}/reject": {
-            "patch": {
+        "/admin/users": {
+            "get": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Reject a order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a page of the drivers matching the filters, the newest first. Use the PENDING_APPROVAL status to get the drivers waiting for an approval",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "admin"
                 ],
-                "summary": "Reject a order",
+                "summary": "Search the drivers",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the ID or phone of the drivers",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "PENDING_APPROVAL",
+                            "APPROVED",
+                            "SUSPENDED"
+                        ],
+                        "type": "string",
+                        "description": "Status of the drivers",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether the drivers are on duty",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "BICYCLE",
+                            "MOTORCYCLE",
+                            "CAR",
+                            "VAN"
+                        ],
+                        "type": "string",
+                        "description": "Vehicle of the drivers",
+                        "name": "vehicle_type",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Size of the page, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of drivers to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Users",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Page-models_UserResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid order ID",
+                        "description": "invalid filter",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to reject order",
+                        "description": "failed to get users",
                         "schema": {
                             "type": "string"
                         }
@@ -305,48 +426,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{order_id}/cancel": {
-            "patch": {
+        "/admin/users/{userID}/activate": {
+            "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Cancel a order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Put a driver on duty for them, so they receive orders if they are approved",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "admin"
                 ],
-                "summary": "Cancel a order",
+                "summary": "Put a driver on duty",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Order ID",
-                        "name": "order_id",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Activated user",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.UserResponse"
                         }
                     },
-                    "400": {
-                        "description": "invalid order ID",
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to cancel order",
+                        "description": "failed to activate user",
                         "schema": {
                             "type": "string"
                         }
@@ -354,48 +472,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{order_id}/reassign": {
+        "/admin/users/{userID}/approve": {
             "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Reassign a order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Approve a driver so they start receiving orders. The driver has to provide their vehicle type and upload their ID photo first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "admin"
                 ],
-                "summary": "Reassign a order",
+                "summary": "Approve a driver",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Order ID",
-                        "name": "order_id",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Approved user",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "invalid order ID",
+                    "409": {
+                        "description": "invalid documents",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to reassign order",
+                        "description": "failed to approve user",
                         "schema": {
                             "type": "string"
                         }
@@ -403,50 +524,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants": {
+        "/admin/users/{userID}/deactivate": {
             "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Create a new restaurant with the given request body",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Put a driver off duty for them, e.g. when they forgot to, so they stop receiving orders",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
                 ],
-                "summary": "Create a new restaurant",
+                "summary": "Put a driver off duty",
                 "parameters": [
                     {
-                        "description": "Create Restaurant Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateRestaurantRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created Restaurant",
+                    "200": {
+                        "description": "Deactivated user",
                         "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
+                            "$ref": "#/definitions/models.UserResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to create restaurant",
+                        "description": "failed to deactivate user",
                         "schema": {
                             "type": "string"
                         }
@@ -454,30 +570,63 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/logo/uploadurl": {
-            "get": {
+        "/admin/users/{userID}/role": {
+            "put": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Get a signed URL to upload a restaurant logo to the S3 bucket",
+                "description": "Set the role of any account, e.g. to make someone an admin or restaurant staff. The account has to refresh its token to get the new role",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "admin"
+                ],
+                "summary": "Set the role of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Firebase UID of the account",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Assign Role Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminAssignRoleRequest"
+                        }
+                    }
                 ],
-                "summary": "Get a signed URL to upload a restaurant logo",
                 "responses": {
                     "200": {
-                        "description": "Presigned URL",
+                        "description": "Assigned role",
                         "schema": {
-                            "$ref": "#/definitions/models.RestaurantLogoUploadResponse"
+                            "$ref": "#/definitions/models.RoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to get upload URL",
+                        "description": "failed to assign role",
                         "schema": {
                             "type": "string"
                         }
@@ -485,75 +634,100 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/me": {
-            "get": {
+        "/admin/users/{userID}/signout": {
+            "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Get a restaurant by the user ID",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Revoke the tokens of all the sessions of a driver or restaurant, e.g. when a device is lost or a staff member leaves",
                 "tags": [
-                    "restaurants"
+                    "admin"
+                ],
+                "summary": "Sign an account out of all its sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Firebase UID of the account",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get a restaurant",
                 "responses": {
-                    "200": {
-                        "description": "Restaurant",
-                        "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
-                        }
+                    "204": {
+                        "description": "Signed out"
                     },
                     "404": {
-                        "description": "Restaurant not found",
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to get restaurant",
+                        "description": "failed to sign out",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/users/{userID}/suspend": {
+            "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Delete a restaurant",
+                "description": "Suspend a driver so they stop receiving orders until they are approved again",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "restaurants"
+                    "admin"
+                ],
+                "summary": "Suspend a driver",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Delete a restaurant",
                 "responses": {
-                    "204": {
-                        "description": "Restaurant deleted",
+                    "200": {
+                        "description": "Suspended user",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete restaurant",
+                        "description": "failed to suspend user",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/auth/role": {
+            "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Update a restaurant with the given request body",
+                "description": "Pick whether the signed in account is a driver or a restaurant. The role can only be picked once, and the token has to be refreshed afterwards to contain it",
                 "consumes": [
                     "application/json"
                 ],
@@ -561,25 +735,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "auth"
                 ],
-                "summary": "Update a restaurant",
+                "summary": "Pick the role of the account",
                 "parameters": [
                     {
-                        "description": "Update Restaurant Request",
+                        "description": "Assign Role Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateRestaurantRequest"
+                            "$ref": "#/definitions/models.AssignRoleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Updated Restaurant",
+                        "description": "Assigned role",
                         "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
+                            "$ref": "#/definitions/models.RoleResponse"
                         }
                     },
                     "400": {
@@ -588,8 +762,14 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "409": {
+                        "description": "role doesn't match the account",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to update restaurant",
+                        "description": "Failed to assign role",
                         "schema": {
                             "type": "string"
                         }
@@ -597,45 +777,76 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurantID}": {
-            "get": {
+        "/auth/signout": {
+            "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Get a restaurant by the restaurant ID",
+                "description": "Revoke the tokens of all the sessions of the signed in account, on every device. The tokens stop working right away if the revocation check is enabled, when they expire otherwise",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign out of all the sessions",
+                "responses": {
+                    "204": {
+                        "description": "Signed out"
+                    },
+                    "500": {
+                        "description": "Failed to sign out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token": {
+            "post": {
+                "description": "Issue a token for any account, signed by the local auth provider. Only available during local development and in the tests, the route doesn't exist when Firebase is used",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "auth"
                 ],
-                "summary": "Get a restaurant by ID",
+                "summary": "Issue a local token",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
-                        "in": "path",
-                        "required": true
+                        "description": "Issue Token Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.IssueTokenRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Restaurant",
+                        "description": "Issued token",
                         "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
+                            "$ref": "#/definitions/models.IssueTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Restaurant not found",
+                        "description": "not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to get restaurant",
+                        "description": "Failed to issue token",
                         "schema": {
                             "type": "string"
                         }
@@ -643,14 +854,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/orders": {
             "post": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Create a user",
+                "description": "Create a new order with the given request body. Restaurants that own an organization can send the order from one of its branches",
                 "consumes": [
                     "application/json"
                 ],
@@ -658,25 +869,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "orders"
                 ],
-                "summary": "Create a user",
+                "summary": "Create a new order",
                 "parameters": [
                     {
-                        "description": "Create User Request",
+                        "description": "Create Order Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateUserRequest"
+                            "$ref": "#/definitions/models.CreateOrderRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created User",
+                        "description": "Created Order",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "$ref": "#/definitions/models.Order"
                         }
                     },
                     "400": {
@@ -685,8 +896,20 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "404": {
+                        "description": "branch not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "restaurant is paused, closed or outside its opening hours",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to create user",
+                        "description": "Failed to create order",
                         "schema": {
                             "type": "string"
                         }
@@ -694,75 +917,88 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
+        "/orders/restaurant": {
             "get": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Get a user by the user ID",
+                "description": "Get all orders for a restaurant",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "orders"
                 ],
-                "summary": "Get a user",
+                "summary": "Get all orders for a restaurant",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Restaurant Orders",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Order"
+                            }
                         }
                     },
                     "404": {
-                        "description": "user not found",
+                        "description": "order not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to get user",
+                        "description": "failed to get orders",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/orders/stream": {
+            "get": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Delete a user",
+                "description": "Stream the events of the caller's orders as Server-Sent Events, so the restaurant and driver apps don't have to poll the order listings. Restaurants that own an organization also receive the events of its branches.\nEvery event is sent with the event type as the SSE event name and the models.OrderEvent as JSON data. A comment is sent periodically to keep the connection open.",
+                "produces": [
+                    "text/event-stream"
+                ],
                 "tags": [
-                    "users"
+                    "orders"
                 ],
-                "summary": "Delete a user",
+                "summary": "Stream order events",
                 "responses": {
                     "200": {
-                        "description": "User deleted",
+                        "description": "Stream of order events",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.OrderEvent"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete user",
+                        "description": "failed to subscribe to order events",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/orders/user": {
+            "get": {
                 "security": [
                     {
                         "jwt": []
                     }
                 ],
-                "description": "Update a user",
+                "description": "Get all orders for a user",
                 "consumes": [
                     "application/json"
                 ],
@@ -770,53 +1006,3948 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Update a user",
-                "parameters": [
-                    {
-                        "description": "Update User Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateUserRequest"
-                        }
-                    }
+                    "orders"
                 ],
+                "summary": "Get all orders for a user",
                 "responses": {
                     "200": {
-                        "description": "Updated User",
+                        "description": "User Orders",
                         "schema": {
-                            "$ref": "#/definitions/models.UserResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Order"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "404": {
+                        "description": "order not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Failed to update user",
+                        "description": "failed to get orders",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateOrderRequest": {
-            "type": "object",
-            "properties": {
-                "description": {
+        },
+        "/orders/{id}/accept": {
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Accept a order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Accept a order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found or not pending",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to accept order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/delivered": {
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Called by the driver app when the new order notification arrives on the device. Only the first acknowledgement is stored",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Acknowledge that an order notification was received",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to acknowledge order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/fulfill": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Fulfill a order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Fulfill a order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "order can't be fulfilled in its current state",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to fulfill order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/reject": {
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Reject a order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Reject a order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found or not pending",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to reject order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/seen": {
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Called by the driver app when the driver opens the order. Also marks the order as delivered if it wasn't already",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Acknowledge that an order was seen",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to acknowledge order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_id}/cancel": {
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Cancel a order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Cancel a order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "order can't be cancelled in its current state",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to cancel order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_id}/location": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the latest position and the path of the driver since they accepted the order. Positions are only kept for the configured retention period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get the location of the driver carrying an order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Order Location",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderLocation"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get order location",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_id}/reassign": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Reassign a order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Reassign a order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "no user to receive order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "order can't be reassigned in its current state",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to reassign order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Create an organization owned by the restaurant, to manage the branches of a chain from the account of the restaurant. The restaurant becomes the first branch of the organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Create Organization Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created organization",
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "already in an organization",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create organization",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/me": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the organization owned by the restaurant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get the organization",
+                "responses": {
+                    "200": {
+                        "description": "Organization",
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    },
+                    "404": {
+                        "description": "organization not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get organization",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/me/branches": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the restaurants of the organization owned by the restaurant, including the restaurant itself",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get the branches of the organization",
+                "responses": {
+                    "200": {
+                        "description": "Branches",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Restaurant"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "organization not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get branches",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Create a restaurant in the organization owned by the restaurant. The orders of the branch are dispatched from its own location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Create a branch",
+                "parameters": [
+                    {
+                        "description": "Create Restaurant Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRestaurantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created branch",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "organization not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create branch",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/me/branches/{branchID}": {
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Delete a branch of the organization. The restaurant that owns the organization is deleted with its account instead",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Delete a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch ID",
+                        "name": "branchID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Branch deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "branch not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete branch",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Update the fields of a branch of the organization that are present in the request body, the others keep their value",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Update a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch ID",
+                        "name": "branchID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Restaurant Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRestaurantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated branch",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "branch not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update branch",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/me/branches/{branchID}/invites": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Invite the account with the email to join a branch of the organization as a manager or cashier, the staff of a branch handle its orders and notifications",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Invite a staff member to a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch ID",
+                        "name": "branchID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Restaurant Invite Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRestaurantInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created invite",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantInvite"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "branch not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create invite",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/me/orders": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the orders of all the branches of the organization owned by the restaurant, the newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get the orders of the organization",
+                "responses": {
+                    "200": {
+                        "description": "Organization orders",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Order"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "organization not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get orders",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Create a new restaurant with the given request body",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Create a new restaurant",
+                "parameters": [
+                    {
+                        "description": "Create Restaurant Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRestaurantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Restaurant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create restaurant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/invites": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the pending invites sent to the verified email of the signed in account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the invites sent to the account",
+                "responses": {
+                    "200": {
+                        "description": "Invites",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RestaurantInvite"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "email not verified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get invites",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/invites/{inviteID}/accept": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Join the restaurant that invited the verified email of the signed in account. Accounts without a role become restaurant staff and have to refresh their token, drivers and restaurant owners can't join a restaurant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Accept an invite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite ID",
+                        "name": "inviteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Membership",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantMember"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "email not verified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "invite not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "already a member of a restaurant or role already assigned",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to accept invite",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/logo/uploadurl": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get a signed URL to upload a restaurant logo to the S3 bucket",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get a signed URL to upload a restaurant logo",
+                "responses": {
+                    "200": {
+                        "description": "Presigned URL",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantLogoUploadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get upload URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get a restaurant by the user ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get a restaurant",
+                "responses": {
+                    "200": {
+                        "description": "Restaurant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get restaurant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Delete the restaurant and its account, signing it out of all its sessions",
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Delete a restaurant",
+                "responses": {
+                    "204": {
+                        "description": "Restaurant deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete restaurant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Update the fields of the restaurant sent in the body, the other ones keep their current value. The longitude and latitude have to be sent together. Pausing the restaurant stops it from creating orders until it is resumed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Update a restaurant",
+                "parameters": [
+                    {
+                        "description": "Update Restaurant Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRestaurantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated Restaurant",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "restaurant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update restaurant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/closures": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the current and upcoming closures of the restaurant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the closures of the restaurant",
+                "responses": {
+                    "200": {
+                        "description": "Closures",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RestaurantClosure"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get closures",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Add a period during which the restaurant can't create orders whatever its opening hours say, e.g. a holiday",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Add a closure",
+                "parameters": [
+                    {
+                        "description": "Create Closure Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRestaurantClosureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created closure",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantClosure"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to create closure",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/closures/{closureID}": {
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Delete a closure of the restaurant, e.g. when it reopens early",
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Delete a closure",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Closure ID",
+                        "name": "closureID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Closure deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "closure not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete closure",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/delivery-zone": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the area the restaurant delivers in. Restaurants without a zone get the drivers whose radius covers them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the delivery zone of the restaurant",
+                "responses": {
+                    "200": {
+                        "description": "Delivery zone",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeliveryZone"
+                        }
+                    },
+                    "404": {
+                        "description": "delivery zone not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get delivery zone",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Replace the area the restaurant delivers in, only the drivers inside it receive its orders. The zone is either a polygon of [longitude, latitude] points, which mustn't cross itself, or a radius in meters around the restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Set the delivery zone of the restaurant",
+                "parameters": [
+                    {
+                        "description": "Set Delivery Zone Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetDeliveryZoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery zone",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeliveryZone"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid polygon",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to set delivery zone",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Remove the delivery zone of the restaurant, its orders then go to the drivers whose radius covers it",
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Delete the delivery zone of the restaurant",
+                "responses": {
+                    "204": {
+                        "description": "Delivery zone deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "delivery zone not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete delivery zone",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/devices": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the devices the restaurant registered for notifications",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the registered devices",
+                "responses": {
+                    "200": {
+                        "description": "Registered Devices",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RestaurantDeviceResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get devices",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Register the FCM token of a restaurant device (mobile app or web push subscription) to be notified when drivers accept, reject or let an order expire",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Register a device for notifications",
+                "parameters": [
+                    {
+                        "description": "Register Device Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRestaurantDeviceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Registered Device",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantDeviceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to register device",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/devices/{deviceID}": {
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Stop sending notifications to a device of the restaurant, e.g. when logging out",
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Unregister a device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "deviceID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Device deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "device not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete device",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/drivers": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the drivers the restaurant prefers or blocked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the preferred and blocked drivers",
+                "parameters": [
+                    {
+                        "enum": [
+                            "PREFERRED",
+                            "BLOCKED"
+                        ],
+                        "type": "string",
+                        "description": "Only return the drivers with this preference",
+                        "name": "preference",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Driver Preferences",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RestaurantDriverPreference"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid preference",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get driver preferences",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/drivers/{userID}": {
+            "put": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Mark a driver as preferred, so they receive the orders of the restaurant before the other drivers in reach, or as blocked, so they never receive them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Prefer or block a driver",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the driver",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Set Driver Preference Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetDriverPreferenceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Driver Preference",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantDriverPreference"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "driver not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set driver preference",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Stop preferring or unblock a driver, they are then dispatched like any other driver",
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Remove a driver preference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the driver",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Driver preference deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "driver preference not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete driver preference",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/invites": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the invites of the restaurant that weren't accepted and didn't expire",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the pending invites of the restaurant",
+                "responses": {
+                    "200": {
+                        "description": "Invites",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RestaurantInvite"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get invites",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Invite the account with the email to join the restaurant as a manager or cashier. The invitee sees the invite once signed in with the email, it expires after a week",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Invite a staff member",
+                "parameters": [
+                    {
+                        "description": "Create Restaurant Invite Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRestaurantInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created invite",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantInvite"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create invite",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/invites/{inviteID}": {
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Cancel a pending invite of the restaurant",
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Cancel an invite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite ID",
+                        "name": "inviteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Invite deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "invite not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete invite",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/members": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the owner and the staff members of the restaurant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the members of the restaurant",
+                "responses": {
+                    "200": {
+                        "description": "Members",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RestaurantMember"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get members",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/members/{uid}": {
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Stop a staff member from acting for the restaurant. The owner can't be removed",
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Remove a staff member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UID of the staff member",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Member removed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "member not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove member",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/opening-hours": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the timezone and weekly opening hours of the restaurant. Restaurants without opening hours take orders at any time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the opening hours of the restaurant",
+                "responses": {
+                    "200": {
+                        "description": "Opening hours",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantOpeningHours"
+                        }
+                    },
+                    "404": {
+                        "description": "restaurant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get opening hours",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Replace the timezone and weekly opening hours of the restaurant. Weekdays go from 0 (Sunday) to 6, times are HH:MM in the restaurant's timezone and a period closing before its open time, e.g. 18:00 to 02:00, closes the next day. Send no periods to take orders at any time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Replace the opening hours of the restaurant",
+                "parameters": [
+                    {
+                        "description": "Update Opening Hours Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRestaurantOpeningHoursRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated opening hours",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantOpeningHours"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "restaurant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update opening hours",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the webhook endpoints registered by the restaurant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get the webhook endpoints",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookEndpointResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get webhooks",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Register a URL that receives the selected order events. Deliveries are signed with the returned secret: the X-Tamra-Signature header is \"sha256=\" followed by the hex encoded HMAC-SHA256 of \"\u003cX-Tamra-Timestamp\u003e.\u003cbody\u003e\"",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook endpoint",
+                "parameters": [
+                    {
+                        "description": "Create Webhook Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatedWebhookEndpointResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/webhooks/{webhookID}": {
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Stop sending events to a webhook endpoint. Its deliveries are deleted as well",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Webhook deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the latest deliveries sent to a webhook endpoint with their state",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get the deliveries of a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get deliveries",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/webhooks/{webhookID}/deliveries/{deliveryID}": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get a delivery with the log of every attempt to send it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "delivery not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get delivery",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/me/webhooks/{webhookID}/deliveries/{deliveryID}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Send a delivery again with the same payload, e.g. after fixing the endpoint. The attempt is logged with the previous ones",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Replay a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delivery replayed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "delivery not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to replay delivery",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/nearby": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get a page of the restaurants within a radius of a location, the closest first, with their distance in meters. The last location and the radius of the driver are used unless other ones are sent, the longitude and latitude have to be sent together",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get the restaurants near a location",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Longitude to search around, the driver's last one by default",
+                        "name": "longitude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude to search around, the driver's last one by default",
+                        "name": "latitude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Radius in meters, the driver's radius by default",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of the page, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of restaurants to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Nearby restaurants",
+                        "schema": {
+                            "$ref": "#/definitions/models.Page-models_NearbyRestaurantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid location, radius or pagination",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get nearby restaurants",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get a restaurant by the restaurant ID, with its opening hours, its current and upcoming closures and whether it takes orders right now",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get a restaurant by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restaurant",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestaurantDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get restaurant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Create a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "description": "Create User Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created User",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get a user by the user ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Delete the user and their account, signing them out of all their sessions",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "responses": {
+                    "200": {
+                        "description": "User deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Update the fields of the user sent in the body, the other ones keep their current value. The longitude and latitude have to be sent together. Changing the vehicle type or the ID photo of an approved driver sends them back to approval",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "description": "Update User Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated User",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/id-photo/uploadurl": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get a signed URL to upload the ID photo of the driver to the S3 bucket. Drivers are approved once an admin checked it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a signed URL to upload the ID photo of the user",
+                "responses": {
+                    "200": {
+                        "description": "Presigned URL",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserIDPhotoUploadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get upload URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/location": {
+            "put": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Lightweight location update for the driver app to call as the driver moves. Updates sent more often than the configured interval, imprecise or old positions are ignored, the response tells whether the location was stored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the location of the user",
+                "parameters": [
+                    {
+                        "description": "Update Location Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateLocationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location update result",
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update location",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/location/batch": {
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Send the positions the driver app buffered while offline. The most recent position that passes the accuracy and age filters is stored, subject to the same throttling as single updates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the location of the user from buffered points",
+                "parameters": [
+                    {
+                        "description": "Update Location Batch Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateLocationBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location update result",
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update location",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the timezone and weekly shifts of the driver. Drivers without shifts can receive orders at any time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the weekly schedule of the user",
+                "responses": {
+                    "200": {
+                        "description": "Schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.DriverSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get schedule",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Replace the timezone and weekly shifts of the driver. Weekdays go from 0 (Sunday) to 6, times are HH:MM in the driver's timezone and a shift ending before its start time, e.g. 22:00 to 06:00, ends the next day. Send no shifts to be available at any time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Replace the weekly schedule of the user",
+                "parameters": [
+                    {
+                        "description": "Update Schedule Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateDriverScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated Schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.DriverSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update schedule",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/stats": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get how the driver handled the orders offered to them over the last 30 days, and the reliability score used by dispatch",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the performance stats of the user",
+                "responses": {
+                    "200": {
+                        "description": "Stats",
+                        "schema": {
+                            "$ref": "#/definitions/models.DriverStats"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get stats",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/time-off": {
+            "get": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Get the current and upcoming time off periods of the driver",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the time off of the user",
+                "responses": {
+                    "200": {
+                        "description": "Time Offs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DriverTimeOff"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get time offs",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Add a period during which the driver doesn't receive orders, whatever their weekly schedule says",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Add time off",
+                "parameters": [
+                    {
+                        "description": "Create Time Off Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateDriverTimeOffRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Time Off",
+                        "schema": {
+                            "$ref": "#/definitions/models.DriverTimeOff"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to create time off",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/time-off/{timeOffID}": {
+            "delete": {
+                "security": [
+                    {
+                        "jwt": []
+                    }
+                ],
+                "description": "Delete a time off period of the driver, e.g. when they come back early",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete time off",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Time Off ID",
+                        "name": "timeOffID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Time off deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "time off not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete time off",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AdminAssignRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "driver",
+                        "restaurant",
+                        "restaurant_staff",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "models.AdminAuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Method and route of the request, e.g. POST /admin/orders/{orderID}/cancel",
+                    "type": "string"
+                },
+                "admin_uid": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "Path of the request, with the IDs of what was changed",
+                    "type": "string"
+                },
+                "request_body": {
+                    "description": "Nil if the request didn't have a JSON body",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AssignRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "driver",
+                        "restaurant"
+                    ]
+                }
+            }
+        },
+        "models.CreateDriverTimeOffRequest": {
+            "type": "object",
+            "required": [
+                "ends_at",
+                "starts_at"
+            ],
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateOrderRequest": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "description": "Optional, branch of the organization of the restaurant the order is sent from",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "required_vehicle": {
+                    "description": "Optional, defaults to the default vehicle of the restaurant",
+                    "type": "string",
+                    "enum": [
+                        "BICYCLE",
+                        "MOTORCYCLE",
+                        "CAR",
+                        "VAN"
+                    ]
+                }
+            }
+        },
+        "models.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateRestaurantClosureRequest": {
+            "type": "object",
+            "required": [
+                "ends_at",
+                "starts_at"
+            ],
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateRestaurantInviteRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "MANAGER",
+                        "CASHIER"
+                    ]
+                }
+            }
+        },
+        "models.CreateRestaurantRequest": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "location_description",
+                "logo_url",
+                "longitude",
+                "name"
+            ],
+            "properties": {
+                "default_vehicle": {
+                    "type": "string",
+                    "enum": [
+                        "BICYCLE",
+                        "MOTORCYCLE",
+                        "CAR",
+                        "VAN"
+                    ]
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "location_description": {
+                    "type": "string"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "fcm_token",
+                "is_active",
+                "latitude",
+                "longitude",
+                "phone",
+                "radius"
+            ],
+            "properties": {
+                "fcm_token": {
+                    "type": "string"
+                },
+                "id_photo_url": {
+                    "description": "The stored_file_url returned by /users/me/id-photo/uploadurl",
+                    "type": "string"
+                },
+                "is_active": {
+                    "description": "Pointer to a bool so the validation library doesn't complain if the value is false",
+                    "type": "boolean"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "max_concurrent_orders": {
+                    "description": "Optional, drivers carry a single order at a time by default",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "radius": {
+                    "type": "integer"
+                },
+                "vehicle_type": {
+                    "type": "string",
+                    "enum": [
+                        "BICYCLE",
+                        "MOTORCYCLE",
+                        "CAR",
+                        "VAN"
+                    ]
+                }
+            }
+        },
+        "models.CreateWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "description": "Deliveries are signed but not encrypted, so only https endpoints are accepted",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatedWebhookEndpointResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DeliveryZone": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "polygon": {
+                    "description": "[longitude, latitude] points of the boundary, the last one is the first one. Only set for polygons",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "radius": {
+                    "description": "In meters, only set for radiuses",
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DriverPenalty": {
+            "type": "object",
+            "properties": {
+                "cooldown_until": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "offense_number": {
+                    "description": "How many penalties the driver got within the penalty window, this one included",
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DriverSchedule": {
+            "type": "object",
+            "properties": {
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DriverShift"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DriverShift": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "description": "HH:MM, the next day when it isn't after StartTime",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "description": "HH:MM",
+                    "type": "string"
+                },
+                "weekday": {
+                    "description": "0 is Sunday",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DriverShiftRequest": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time",
+                "weekday"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "weekday": {
+                    "description": "Pointer so Sunday (0) passes the required validation",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.DriverStats": {
+            "type": "object",
+            "properties": {
+                "acceptance_rate": {
+                    "description": "Nil until the driver receives an order",
+                    "type": "number"
+                },
+                "acceptances": {
+                    "type": "integer"
+                },
+                "expiries": {
+                    "type": "integer"
+                },
+                "fulfilment_rate": {
+                    "description": "Nil until an accepted order is fulfilled, cancelled or expired",
+                    "type": "number"
+                },
+                "fulfilments": {
+                    "type": "integer"
+                },
+                "median_seconds_to_accept": {
+                    "description": "Nil until the driver accepts an order",
+                    "type": "number"
+                },
+                "offers": {
+                    "type": "integer"
+                },
+                "rejections": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "Reliability between 0 and 1, new drivers start at 1",
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DriverTimeOff": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IssueTokenRequest": {
+            "type": "object",
+            "required": [
+                "uid"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "driver",
+                        "restaurant",
+                        "restaurant_staff",
+                        "admin"
+                    ]
+                },
+                "uid": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IssueTokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LocationPoint": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "type": "number"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "recorded_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LocationUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "boolean"
+                },
+                "location_updated_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Why the location wasn't applied, one of THROTTLED (a location was stored less than the minimum interval before), LOW_ACCURACY or STALE (recorded too long ago)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.NearbyRestaurantResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "default_vehicle": {
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "In meters",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_paused": {
+                    "type": "boolean"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "location_description": {
+                    "type": "string"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OpeningPeriod": {
+            "type": "object",
+            "properties": {
+                "close_time": {
+                    "description": "HH:MM, the next day when it isn't after OpenTime",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "open_time": {
+                    "description": "HH:MM",
+                    "type": "string"
+                },
+                "weekday": {
+                    "description": "0 is Sunday",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OpeningPeriodRequest": {
+            "type": "object",
+            "required": [
+                "close_time",
+                "open_time",
+                "weekday"
+            ],
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "open_time": {
+                    "type": "string"
+                },
+                "weekday": {
+                    "description": "Pointer so Sunday (0) passes the required validation",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.Order": {
+            "type": "object",
+            "required": [
+                "code",
+                "restaurant_id",
+                "state",
+                "user_id"
+            ],
+            "properties": {
+                "accepted_at": {
+                    "description": "Set when the driver accepted the order",
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "description": "Set when the driver app received the new order notification",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "required_vehicle": {
+                    "description": "Smallest vehicle able to carry the order, empty if any vehicle can",
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "seen_at": {
+                    "description": "Set when the driver opened the order",
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderEvent": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateRestaurantRequest": {
+        "models.OrderLocation": {
+            "type": "object",
+            "properties": {
+                "latest": {
+                    "description": "Nil until the driver reports a location after accepting the order",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.LocationPoint"
+                        }
+                    ]
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LocationPoint"
+                    }
+                },
+                "state": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_restaurant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Page-models_AdminAuditLog": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AdminAuditLog"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Page-models_NearbyRestaurantResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NearbyRestaurantResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Page-models_Order": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Order"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Page-models_RestaurantResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RestaurantResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Page-models_UserResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RegisterRestaurantDeviceRequest": {
+            "type": "object",
+            "required": [
+                "fcm_token",
+                "platform"
+            ],
+            "properties": {
+                "fcm_token": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string",
+                    "enum": [
+                        "ANDROID",
+                        "IOS",
+                        "WEB"
+                    ]
+                }
+            }
+        },
+        "models.Restaurant": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "location_description",
+                "logo_url",
+                "longitude",
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "default_vehicle": {
+                    "description": "Required vehicle of the orders that don't set one, empty if any vehicle can",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_paused": {
+                    "description": "Paused restaurants can't create orders",
+                    "type": "boolean"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "location_description": {
+                    "type": "string"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RestaurantClosure": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RestaurantDetails": {
             "type": "object",
             "required": [
                 "latitude",
@@ -826,109 +4957,197 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "closed_reason": {
+                    "description": "Why the restaurant is closed, empty when it is open",
+                    "type": "string"
+                },
+                "closures": {
+                    "description": "The current and upcoming closures",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RestaurantClosure"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_vehicle": {
+                    "description": "Required vehicle of the orders that don't set one, empty if any vehicle can",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_open": {
+                    "type": "boolean"
+                },
+                "is_paused": {
+                    "description": "Paused restaurants can't create orders",
+                    "type": "boolean"
+                },
                 "latitude": {
                     "type": "number"
                 },
-                "location_description": {
+                "location_description": {
+                    "type": "string"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "opening_hours": {
+                    "$ref": "#/definitions/models.RestaurantOpeningHours"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RestaurantDeviceResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RestaurantDriverPreference": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "note": {
+                    "description": "Why the driver is preferred or blocked, only seen by the restaurant",
+                    "type": "string"
+                },
+                "preference": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RestaurantInvite": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "accepted_by": {
+                    "description": "UID of the account that accepted the invite, nil while it is pending",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
                     "type": "string"
                 },
-                "logo_url": {
+                "expires_at": {
                     "type": "string"
                 },
-                "longitude": {
-                    "type": "number"
+                "id": {
+                    "type": "integer"
                 },
-                "name": {
+                "restaurant_id": {
                     "type": "string"
                 },
-                "phone_number": {
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateUserRequest": {
+        "models.RestaurantLogoUploadResponse": {
             "type": "object",
-            "required": [
-                "fcm_token",
-                "is_active",
-                "latitude",
-                "longitude",
-                "phone",
-                "radius"
-            ],
             "properties": {
-                "fcm_token": {
+                "description": {
                     "type": "string"
                 },
-                "is_active": {
-                    "description": "Pointer to a bool so the validation library doesn't complain if the value is false",
-                    "type": "boolean"
-                },
-                "latitude": {
-                    "type": "number"
-                },
-                "longitude": {
-                    "type": "number"
-                },
-                "phone": {
+                "presigned_url": {
                     "type": "string"
                 },
-                "radius": {
-                    "type": "integer"
+                "stored_file_url": {
+                    "type": "string"
                 }
             }
         },
-        "models.Order": {
+        "models.RestaurantMember": {
             "type": "object",
-            "required": [
-                "code",
-                "restaurant_id",
-                "state",
-                "user_id"
-            ],
             "properties": {
-                "code": {
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
+                "restaurant_id": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "restaurant_id": {
+                "role": {
                     "type": "string"
                 },
-                "state": {
+                "uid": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                }
+            }
+        },
+        "models.RestaurantOpeningHours": {
+            "type": "object",
+            "properties": {
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OpeningPeriod"
+                    }
                 },
-                "user_id": {
+                "timezone": {
                     "type": "string"
                 }
             }
         },
-        "models.Restaurant": {
+        "models.RestaurantResponse": {
             "type": "object",
-            "required": [
-                "latitude",
-                "location_description",
-                "logo_url",
-                "longitude",
-                "name"
-            ],
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "default_vehicle": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "is_paused": {
+                    "type": "boolean"
+                },
                 "latitude": {
                     "type": "number"
                 },
@@ -952,35 +5171,166 @@ const docTemplate = `{
                 }
             }
         },
-        "models.RestaurantLogoUploadResponse": {
+        "models.RoleResponse": {
             "type": "object",
             "properties": {
-                "description": {
+                "role": {
                     "type": "string"
                 },
-                "presigned_url": {
+                "uid": {
                     "type": "string"
+                }
+            }
+        },
+        "models.SetDeliveryZoneRequest": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "polygon": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 3,
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
                 },
-                "stored_file_url": {
+                "radius": {
+                    "type": "integer",
+                    "maximum": 100000,
+                    "minimum": 100
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "POLYGON",
+                        "RADIUS"
+                    ]
+                }
+            }
+        },
+        "models.SetDriverPreferenceRequest": {
+            "type": "object",
+            "required": [
+                "preference"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "preference": {
+                    "type": "string",
+                    "enum": [
+                        "PREFERRED",
+                        "BLOCKED"
+                    ]
+                }
+            }
+        },
+        "models.UpdateDriverScheduleRequest": {
+            "type": "object",
+            "required": [
+                "shifts",
+                "timezone"
+            ],
+            "properties": {
+                "shifts": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/models.DriverShiftRequest"
+                    }
+                },
+                "timezone": {
                     "type": "string"
                 }
             }
         },
-        "models.UpdateRestaurantRequest": {
+        "models.UpdateLocationBatchRequest": {
+            "type": "object",
+            "required": [
+                "points"
+            ],
+            "properties": {
+                "points": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.UpdateLocationRequest"
+                    }
+                }
+            }
+        },
+        "models.UpdateLocationRequest": {
             "type": "object",
             "required": [
                 "latitude",
-                "location_description",
-                "logo_url",
-                "longitude",
-                "name"
+                "longitude"
             ],
             "properties": {
+                "accuracy": {
+                    "type": "number",
+                    "minimum": 0
+                },
                 "latitude": {
                     "type": "number"
                 },
-                "location_description": {
+                "longitude": {
+                    "type": "number"
+                },
+                "recorded_at": {
+                    "description": "When the device recorded the position, defaults to now",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateRestaurantOpeningHoursRequest": {
+            "type": "object",
+            "required": [
+                "periods",
+                "timezone"
+            ],
+            "properties": {
+                "periods": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/models.OpeningPeriodRequest"
+                    }
+                },
+                "timezone": {
                     "type": "string"
+                }
+            }
+        },
+        "models.UpdateRestaurantRequest": {
+            "type": "object",
+            "properties": {
+                "default_vehicle": {
+                    "description": "An empty string removes the default",
+                    "type": "string",
+                    "enum": [
+                        "BICYCLE",
+                        "MOTORCYCLE",
+                        "CAR",
+                        "VAN"
+                    ]
+                },
+                "is_paused": {
+                    "type": "boolean"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "location_description": {
+                    "type": "string",
+                    "minLength": 1
                 },
                 "logo_url": {
                     "type": "string"
@@ -989,7 +5339,8 @@ const docTemplate = `{
                     "type": "number"
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "minLength": 1
                 },
                 "phone_number": {
                     "type": "string"
@@ -998,16 +5349,12 @@ const docTemplate = `{
         },
         "models.UpdateUserRequest": {
             "type": "object",
-            "required": [
-                "fcm_token",
-                "is_active",
-                "latitude",
-                "longitude",
-                "phone",
-                "radius"
-            ],
             "properties": {
                 "fcm_token": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "id_photo_url": {
                     "type": "string"
                 },
                 "is_active": {
@@ -1019,20 +5366,55 @@ const docTemplate = `{
                 "longitude": {
                     "type": "number"
                 },
+                "max_concurrent_orders": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
                 "phone": {
                     "type": "string"
                 },
                 "radius": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "vehicle_type": {
+                    "type": "string",
+                    "enum": [
+                        "BICYCLE",
+                        "MOTORCYCLE",
+                        "CAR",
+                        "VAN"
+                    ]
+                }
+            }
+        },
+        "models.UserIDPhotoUploadResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "presigned_url": {
+                    "type": "string"
+                },
+                "stored_file_url": {
+                    "type": "string"
                 }
             }
         },
         "models.UserResponse": {
             "type": "object",
             "properties": {
+                "active_penalty": {
+                    "$ref": "#/definitions/models.DriverPenalty"
+                },
                 "id": {
                     "type": "string"
                 },
+                "id_photo_url": {
+                    "type": "string"
+                },
                 "is_active": {
                     "type": "boolean"
                 },
@@ -1042,14 +5424,119 @@ const docTemplate = `{
                 "latitude": {
                     "type": "number"
                 },
+                "location_updated_at": {
+                    "type": "string"
+                },
                 "longitude": {
                     "type": "number"
                 },
+                "max_concurrent_orders": {
+                    "type": "integer"
+                },
                 "phone": {
                     "type": "string"
                 },
                 "radius": {
                     "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "vehicle_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt_count": {
+                    "type": "integer"
+                },
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDeliveryAttempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "webhook_endpoint_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt_number": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "webhook_delivery_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookEndpointResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,613 @@
+package handlers
+
+import (
+	"Tamra/internal/app/tamra/services"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminHandler serves the routes used by the Tamra team to review drivers, look into the data and fix it
+type AdminHandler struct {
+	userService       services.UserService
+	restaurantService services.RestaurantService
+	orderService      services.OrderService
+	authService       services.AuthService
+	auditService      services.AuditService
+	validator         Validator
+	logger            logrus.FieldLogger
+	config            utils.Config
+}
+
+func NewAdminHandler(userService services.UserService, restaurantService services.RestaurantService, orderService services.OrderService, authService services.AuthService, auditService services.AuditService, validator Validator, logger logrus.FieldLogger, config utils.Config) *AdminHandler {
+	return &AdminHandler{userService: userService, restaurantService: restaurantService, orderService: orderService, authService: authService, auditService: auditService, validator: validator, logger: logger, config: config}
+}
+
+// orderStates are the states an order can be in
+var orderStates = []string{"PENDING", "ACCEPTED", "REJECTED", "CANCELLED", "EXPIRED", "FULFILLED"}
+
+// GetUsers godoc
+//
+//	@Summary		Search the drivers
+//	@Description	Get a page of the drivers matching the filters, the newest first. Use the PENDING_APPROVAL status to get the drivers waiting for an approval
+//	@Tags			admin
+//	@Produce		json
+//	@Param			q				query	string	false	"Part of the ID or phone of the drivers"
+//	@Param			status			query	string	false	"Status of the drivers"	Enums(PENDING_APPROVAL, APPROVED, SUSPENDED)
+//	@Param			is_active		query	bool	false	"Whether the drivers are on duty"
+//	@Param			vehicle_type	query	string	false	"Vehicle of the drivers"	Enums(BICYCLE, MOTORCYCLE, CAR, VAN)
+//	@Param			limit			query	int		false	"Size of the page, 50 by default"
+//	@Param			offset			query	int		false	"Number of drivers to skip"
+//	@Security		jwt
+//	@Success		200	{object}	models.Page[models.UserResponse]	"Users"
+//	@Failure		400	{string}	string								"invalid filter"
+//	@Failure		500	{string}	string								"failed to get users"
+//	@Router			/admin/users [get]
+func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to search users.", r.Context().Value(chimiddleware.RequestIDKey))
+	query := r.URL.Query()
+	pagination, err := parsePagination(query)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid pagination", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid limit or offset")
+		return
+	}
+
+	filter := &models.UserFilter{
+		Search:      query.Get("q"),
+		Status:      query.Get("status"),
+		VehicleType: query.Get("vehicle_type"),
+		Pagination:  pagination,
+	}
+
+	if filter.Status != "" && filter.Status != models.UserStatusPendingApproval && filter.Status != models.UserStatusApproved && filter.Status != models.UserStatusSuspended {
+		h.logger.Errorf("Request ID %s: Invalid status %s", r.Context().Value(chimiddleware.RequestIDKey), filter.Status)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid status")
+		return
+	}
+
+	if filter.VehicleType != "" && !slices.Contains(models.VehicleTypes, filter.VehicleType) {
+		h.logger.Errorf("Request ID %s: Invalid vehicle type %s", r.Context().Value(chimiddleware.RequestIDKey), filter.VehicleType)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid vehicle type")
+		return
+	}
+
+	if isActive := query.Get("is_active"); isActive != "" {
+		value, err := strconv.ParseBool(isActive)
+		if err != nil {
+			h.logger.WithError(err).Errorf("Request ID %s: Invalid is_active", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid is_active")
+			return
+		}
+		filter.IsActive = &value
+	}
+
+	users, total, err := h.userService.SearchUsers(filter)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get users", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get users")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.Page[*models.UserResponse]{Items: utils.MapUsersToUserResponses(users), Total: total, Limit: pagination.Limit, Offset: pagination.Offset})
+	h.logger.Infof("Request ID %s: Finished processing request to search users.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// ApproveUser godoc
+//
+//	@Summary		Approve a driver
+//	@Description	Approve a driver so they start receiving orders. The driver has to provide their vehicle type and upload their ID photo first
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path	string	true	"User ID"
+//	@Security		jwt
+//	@Success		200	{object}	models.UserResponse	"Approved user"
+//	@Failure		404	{string}	string				"user not found"
+//	@Failure		409	{string}	string				"missing documents"
+//	@Failure		409	{string}	string				"invalid documents"
+//	@Failure		500	{string}	string				"failed to approve user"
+//	@Router			/admin/users/{userID}/approve [post]
+func (h *AdminHandler) ApproveUser(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to approve user.", r.Context().Value(chimiddleware.RequestIDKey))
+	userID := chi.URLParam(r, "userID")
+
+	user, err := h.userService.ApproveUser(userID, h.config.DriverDocumentsBucket)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
+		if errors.Is(err, utils.ErrMissingDocuments) {
+			h.logger.WithError(err).Errorf("Request ID %s: User is missing documents", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "missing documents")
+			return
+		}
+		if errors.Is(err, utils.ErrInvalidDocuments) {
+			h.logger.WithError(err).Errorf("Request ID %s: User's documents are invalid", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "invalid documents")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to approve user", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to approve user")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(utils.MapUserToUserResponse(user))
+	h.logger.Infof("Request ID %s: Finished processing request to approve user.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// SuspendUser godoc
+//
+//	@Summary		Suspend a driver
+//	@Description	Suspend a driver so they stop receiving orders until they are approved again
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path	string	true	"User ID"
+//	@Security		jwt
+//	@Success		200	{object}	models.UserResponse	"Suspended user"
+//	@Failure		404	{string}	string				"user not found"
+//	@Failure		500	{string}	string				"failed to suspend user"
+//	@Router			/admin/users/{userID}/suspend [post]
+func (h *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to suspend user.", r.Context().Value(chimiddleware.RequestIDKey))
+	userID := chi.URLParam(r, "userID")
+
+	user, err := h.userService.SuspendUser(userID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to suspend user", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to suspend user")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(utils.MapUserToUserResponse(user))
+	h.logger.Infof("Request ID %s: Finished processing request to suspend user.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// AssignRole godoc
+//
+//	@Summary		Set the role of an account
+//	@Description	Set the role of any account, e.g. to make someone an admin or restaurant staff. The account has to refresh its token to get the new role
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	string							true	"Firebase UID of the account"
+//	@Param			request	body	models.AdminAssignRoleRequest	true	"Assign Role Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.RoleResponse	"Assigned role"
+//	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		404	{string}	string				"account not found"
+//	@Failure		500	{string}	string				"failed to assign role"
+//	@Router			/admin/users/{userID}/role [put]
+func (h *AdminHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to assign role.", r.Context().Value(chimiddleware.RequestIDKey))
+	assignRoleRequest := &models.AdminAssignRoleRequest{}
+	err := json.NewDecoder(r.Body).Decode(assignRoleRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(assignRoleRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+	err = h.authService.AssignRole(userID, assignRoleRequest.Role)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Account not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "account not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to assign role", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to assign role")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.RoleResponse{UID: userID, Role: assignRoleRequest.Role})
+	h.logger.Infof("Request ID %s: Finished processing request to assign role.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// SignOutUser godoc
+//
+//	@Summary		Sign an account out of all its sessions
+//	@Description	Revoke the tokens of all the sessions of a driver or restaurant, e.g. when a device is lost or a staff member leaves
+//	@Tags			admin
+//	@Param			userID	path	string	true	"Firebase UID of the account"
+//	@Security		jwt
+//	@Success		204	"Signed out"
+//	@Failure		404	{string}	string	"account not found"
+//	@Failure		500	{string}	string	"failed to sign out"
+//	@Router			/admin/users/{userID}/signout [post]
+func (h *AdminHandler) SignOutUser(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to sign out user.", r.Context().Value(chimiddleware.RequestIDKey))
+	userID := chi.URLParam(r, "userID")
+
+	err := h.authService.SignOut(userID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Account not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "account not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to sign out user", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to sign out")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to sign out user.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// ActivateUser godoc
+//
+//	@Summary		Put a driver on duty
+//	@Description	Put a driver on duty for them, so they receive orders if they are approved
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path	string	true	"User ID"
+//	@Security		jwt
+//	@Success		200	{object}	models.UserResponse	"Activated user"
+//	@Failure		404	{string}	string				"user not found"
+//	@Failure		500	{string}	string				"failed to activate user"
+//	@Router			/admin/users/{userID}/activate [post]
+func (h *AdminHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserActive(w, r, true)
+}
+
+// DeactivateUser godoc
+//
+//	@Summary		Put a driver off duty
+//	@Description	Put a driver off duty for them, e.g. when they forgot to, so they stop receiving orders
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path	string	true	"User ID"
+//	@Security		jwt
+//	@Success		200	{object}	models.UserResponse	"Deactivated user"
+//	@Failure		404	{string}	string				"user not found"
+//	@Failure		500	{string}	string				"failed to deactivate user"
+//	@Router			/admin/users/{userID}/deactivate [post]
+func (h *AdminHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserActive(w, r, false)
+}
+
+func (h *AdminHandler) setUserActive(w http.ResponseWriter, r *http.Request, isActive bool) {
+	action := "deactivate"
+	if isActive {
+		action = "activate"
+	}
+	h.logger.Infof("Request ID %s: Received request to %s user.", r.Context().Value(chimiddleware.RequestIDKey), action)
+	userID := chi.URLParam(r, "userID")
+
+	user, err := h.userService.SetUserActive(userID, isActive)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to %s user", r.Context().Value(chimiddleware.RequestIDKey), action)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "failed to %s user", action)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(utils.MapUserToUserResponse(user))
+	h.logger.Infof("Request ID %s: Finished processing request to %s user.", r.Context().Value(chimiddleware.RequestIDKey), action)
+}
+
+// GetRestaurants godoc
+//
+//	@Summary		Search the restaurants
+//	@Description	Get a page of the restaurants matching the filters, the newest first
+//	@Tags			admin
+//	@Produce		json
+//	@Param			q				query	string	false	"Part of the ID or name of the restaurants"
+//	@Param			organization_id	query	int		false	"Organization of the restaurants"
+//	@Param			limit			query	int		false	"Size of the page, 50 by default"
+//	@Param			offset			query	int		false	"Number of restaurants to skip"
+//	@Security		jwt
+//	@Success		200	{object}	models.Page[models.RestaurantResponse]	"Restaurants"
+//	@Failure		400	{string}	string									"invalid filter"
+//	@Failure		500	{string}	string									"failed to get restaurants"
+//	@Router			/admin/restaurants [get]
+func (h *AdminHandler) GetRestaurants(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to search restaurants.", r.Context().Value(chimiddleware.RequestIDKey))
+	query := r.URL.Query()
+	pagination, err := parsePagination(query)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid pagination", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid limit or offset")
+		return
+	}
+
+	filter := &models.RestaurantFilter{Search: query.Get("q"), Pagination: pagination}
+	if organizationID := query.Get("organization_id"); organizationID != "" {
+		value, err := strconv.Atoi(organizationID)
+		if err != nil {
+			h.logger.WithError(err).Errorf("Request ID %s: Invalid organization ID", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid organization_id")
+			return
+		}
+		filter.OrganizationID = &value
+	}
+
+	restaurants, total, err := h.restaurantService.SearchRestaurants(filter)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get restaurants", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurants")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.Page[*models.RestaurantResponse]{Items: utils.MapRestaurantsToRestaurantResponses(restaurants), Total: total, Limit: pagination.Limit, Offset: pagination.Offset})
+	h.logger.Infof("Request ID %s: Finished processing request to search restaurants.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetOrders godoc
+//
+//	@Summary		Search the orders
+//	@Description	Get a page of the orders matching the filters, the newest first
+//	@Tags			admin
+//	@Produce		json
+//	@Param			state			query	string	false	"State of the orders"	Enums(PENDING, ACCEPTED, REJECTED, CANCELLED, EXPIRED, FULFILLED)
+//	@Param			code			query	string	false	"Code of the order"
+//	@Param			restaurant_id	query	string	false	"Restaurant of the orders"
+//	@Param			user_id			query	string	false	"Driver of the orders"
+//	@Param			created_after	query	string	false	"RFC 3339 time the orders were created at or after"
+//	@Param			created_before	query	string	false	"RFC 3339 time the orders were created before"
+//	@Param			limit			query	int		false	"Size of the page, 50 by default"
+//	@Param			offset			query	int		false	"Number of orders to skip"
+//	@Security		jwt
+//	@Success		200	{object}	models.Page[models.Order]	"Orders"
+//	@Failure		400	{string}	string						"invalid filter"
+//	@Failure		500	{string}	string						"failed to get orders"
+//	@Router			/admin/orders [get]
+func (h *AdminHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to search orders.", r.Context().Value(chimiddleware.RequestIDKey))
+	query := r.URL.Query()
+	pagination, err := parsePagination(query)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid pagination", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid limit or offset")
+		return
+	}
+
+	filter := &models.OrderFilter{
+		State:        query.Get("state"),
+		Code:         query.Get("code"),
+		RestaurantID: query.Get("restaurant_id"),
+		UserID:       query.Get("user_id"),
+		Pagination:   pagination,
+	}
+
+	if filter.State != "" && !slices.Contains(orderStates, filter.State) {
+		h.logger.Errorf("Request ID %s: Invalid state %s", r.Context().Value(chimiddleware.RequestIDKey), filter.State)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid state")
+		return
+	}
+
+	filter.CreatedAfter, err = parseOptionalTime(query, "created_after")
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid created_after", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid created_after")
+		return
+	}
+
+	filter.CreatedBefore, err = parseOptionalTime(query, "created_before")
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid created_before", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid created_before")
+		return
+	}
+
+	orders, total, err := h.orderService.SearchOrders(filter)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get orders", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get orders")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.Page[*models.Order]{Items: orders, Total: total, Limit: pagination.Limit, Offset: pagination.Offset})
+	h.logger.Infof("Request ID %s: Finished processing request to search orders.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// CancelOrder godoc
+//
+//	@Summary		Force cancel an order
+//	@Description	Cancel an order that isn't fulfilled or cancelled yet on behalf of its restaurant, the driver is notified like when the restaurant cancels it
+//	@Tags			admin
+//	@Param			orderID	path	int	true	"Order ID"
+//	@Security		jwt
+//	@Success		200	{string}	string	"order cancelled"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"order not found"
+//	@Failure		409	{string}	string	"order can't be cancelled in its current state"
+//	@Failure		500	{string}	string	"failed to cancel order"
+//	@Router			/admin/orders/{orderID}/cancel [post]
+func (h *AdminHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to force cancel order.", r.Context().Value(chimiddleware.RequestIDKey))
+	orderID, err := strconv.Atoi(chi.URLParam(r, "orderID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	err = h.orderService.ForceCancelOrder(orderID)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidOrderState) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order can't be cancelled in its current state", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "order can't be cancelled in its current state")
+			return
+		}
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "order not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to cancel order", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to cancel order")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "order cancelled")
+	h.logger.Infof("Request ID %s: Finished processing request to force cancel order.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// ReassignOrder godoc
+//
+//	@Summary		Force reassign an order
+//	@Description	Expire an order that isn't fulfilled or cancelled yet on behalf of its restaurant and send it to another driver. The current driver is penalized unless they rejected it
+//	@Tags			admin
+//	@Param			orderID	path	int	true	"Order ID"
+//	@Security		jwt
+//	@Success		200	{string}	string	"order reassigned"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"order not found or no user to receive it"
+//	@Failure		409	{string}	string	"order can't be reassigned in its current state"
+//	@Failure		500	{string}	string	"failed to reassign order"
+//	@Router			/admin/orders/{orderID}/reassign [post]
+func (h *AdminHandler) ReassignOrder(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to force reassign order.", r.Context().Value(chimiddleware.RequestIDKey))
+	orderID, err := strconv.Atoi(chi.URLParam(r, "orderID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	err = h.orderService.ForceReassignOrder(orderID)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidOrderState) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order can't be reassigned in its current state", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "order can't be reassigned in its current state")
+			return
+		}
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order not found or no user to receive it", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "order not found or no user to receive it")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to reassign order", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to reassign order")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "order reassigned")
+	h.logger.Infof("Request ID %s: Finished processing request to force reassign order.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetAuditLogs godoc
+//
+//	@Summary		Get the admin audit logs
+//	@Description	Get a page of the changes made through the admin routes, the newest first. Failed attempts are logged too, with their status code
+//	@Tags			admin
+//	@Produce		json
+//	@Param			admin_uid	query	string	false	"Firebase UID of the admin"
+//	@Param			limit		query	int		false	"Size of the page, 50 by default"
+//	@Param			offset		query	int		false	"Number of logs to skip"
+//	@Security		jwt
+//	@Success		200	{object}	models.Page[models.AdminAuditLog]	"Audit logs"
+//	@Failure		400	{string}	string								"invalid limit or offset"
+//	@Failure		500	{string}	string								"failed to get audit logs"
+//	@Router			/admin/audit-logs [get]
+func (h *AdminHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get audit logs.", r.Context().Value(chimiddleware.RequestIDKey))
+	query := r.URL.Query()
+	pagination, err := parsePagination(query)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid pagination", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid limit or offset")
+		return
+	}
+
+	logs, total, err := h.auditService.GetAdminAuditLogs(&models.AdminAuditLogFilter{AdminUID: query.Get("admin_uid"), Pagination: pagination})
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get audit logs", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get audit logs")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.Page[*models.AdminAuditLog]{Items: logs, Total: total, Limit: pagination.Limit, Offset: pagination.Offset})
+	h.logger.Infof("Request ID %s: Finished processing request to get audit logs.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// parseOptionalTime reads an RFC 3339 time from a query parameter, nil if it isn't set
+func parseOptionalTime(query url.Values, key string) (*time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
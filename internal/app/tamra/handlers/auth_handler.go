@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"Tamra/internal/app/tamra/middleware"
+	"Tamra/internal/app/tamra/services"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+type AuthHandler struct {
+	authService services.AuthService
+	tokenIssuer *middleware.LocalTokenIssuer // Nil unless the local auth provider is used
+	validator   Validator
+	logger      logrus.FieldLogger
+}
+
+func NewAuthHandler(authService services.AuthService, tokenIssuer *middleware.LocalTokenIssuer, validator Validator, logger logrus.FieldLogger) *AuthHandler {
+	return &AuthHandler{authService: authService, tokenIssuer: tokenIssuer, validator: validator, logger: logger}
+}
+
+// AssignRole godoc
+//
+//	@Summary		Pick the role of the account
+//	@Description	Pick whether the signed in account is a driver or a restaurant. The role can only be picked once, and the token has to be refreshed afterwards to contain it
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.AssignRoleRequest	true	"Assign Role Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.RoleResponse	"Assigned role"
+//	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		409	{string}	string				"role already assigned"
+//	@Failure		409	{string}	string				"role doesn't match the account"
+//	@Failure		500	{string}	string				"Failed to assign role"
+//	@Router			/auth/role [post]
+func (h *AuthHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to assign role.", r.Context().Value(chimiddleware.RequestIDKey))
+	assignRoleRequest := &models.AssignRoleRequest{}
+	err := json.NewDecoder(r.Body).Decode(assignRoleRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(assignRoleRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	err = h.authService.AssignOwnRole(firebaseUID, assignRoleRequest.Role)
+	if err != nil {
+		if errors.Is(err, utils.ErrRoleAlreadyAssigned) {
+			h.logger.WithError(err).Errorf("Request ID %s: Role already assigned", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "role already assigned")
+			return
+		}
+		if errors.Is(err, utils.ErrRoleMismatch) {
+			h.logger.WithError(err).Errorf("Request ID %s: Role doesn't match the account", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "role doesn't match the account")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to assign role", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to assign role")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.RoleResponse{UID: firebaseUID, Role: assignRoleRequest.Role})
+	h.logger.Infof("Request ID %s: Finished processing request to assign role.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// IssueToken godoc
+//
+//	@Summary		Issue a local token
+//	@Description	Issue a token for any account, signed by the local auth provider. Only available during local development and in the tests, the route doesn't exist when Firebase is used
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.IssueTokenRequest	true	"Issue Token Request"
+//	@Success		200	{object}	models.IssueTokenResponse	"Issued token"
+//	@Failure		400	{string}	string						"Invalid request body"
+//	@Failure		404	{string}	string						"not found"
+//	@Failure		500	{string}	string						"Failed to issue token"
+//	@Router			/auth/token [post]
+func (h *AuthHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to issue token.", r.Context().Value(chimiddleware.RequestIDKey))
+	if h.tokenIssuer == nil {
+		h.logger.Errorf("Request ID %s: Tokens are only issued by the local auth provider", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "not found")
+		return
+	}
+
+	issueTokenRequest := &models.IssueTokenRequest{}
+	err := json.NewDecoder(r.Body).Decode(issueTokenRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(issueTokenRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	// Like refreshing a Firebase token, the token gets the role the account picked
+	role := issueTokenRequest.Role
+	if role == "" {
+		role, err = h.authService.GetRole(issueTokenRequest.UID)
+		if err != nil {
+			h.logger.WithError(err).Errorf("Request ID %s: Failed to get role", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "failed to issue token")
+			return
+		}
+	}
+
+	claims := map[string]interface{}{}
+	if role != "" {
+		claims[models.RoleClaim] = role
+	}
+	// Local accounts own the emails they claim
+	if issueTokenRequest.Email != "" {
+		claims["email"] = issueTokenRequest.Email
+		claims["email_verified"] = true
+	}
+	if issueTokenRequest.Phone != "" {
+		claims["phone_number"] = issueTokenRequest.Phone
+	}
+
+	token, expiresAt, err := h.tokenIssuer.IssueToken(issueTokenRequest.UID, claims)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to issue token", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to issue token")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.IssueTokenResponse{Token: token, ExpiresAt: expiresAt})
+	h.logger.Infof("Request ID %s: Finished processing request to issue token.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// SignOut godoc
+//
+//	@Summary		Sign out of all the sessions
+//	@Description	Revoke the tokens of all the sessions of the signed in account, on every device. The tokens stop working right away if the revocation check is enabled, when they expire otherwise
+//	@Tags			auth
+//	@Security		jwt
+//	@Success		204	"Signed out"
+//	@Failure		500	{string}	string	"Failed to sign out"
+//	@Router			/auth/signout [post]
+func (h *AuthHandler) SignOut(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to sign out.", r.Context().Value(chimiddleware.RequestIDKey))
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	err := h.authService.SignOut(firebaseUID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to sign out", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to sign out")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to sign out.", r.Context().Value(chimiddleware.RequestIDKey))
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"Tamra/internal/app/tamra/middleware"
 	"Tamra/internal/app/tamra/services"
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
@@ -9,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
@@ -16,19 +18,20 @@ import (
 )
 
 type OrderHandler struct {
-	orderService services.OrderService
-	validator    Validator
-	logger       logrus.FieldLogger
+	orderService        services.OrderService
+	organizationService services.OrganizationService
+	validator           Validator
+	logger              logrus.FieldLogger
 }
 
-func NewOrderHandler(orderService services.OrderService, validator Validator, logger logrus.FieldLogger) *OrderHandler {
-	return &OrderHandler{orderService: orderService, validator: validator, logger: logger}
+func NewOrderHandler(orderService services.OrderService, organizationService services.OrganizationService, validator Validator, logger logrus.FieldLogger) *OrderHandler {
+	return &OrderHandler{orderService: orderService, organizationService: organizationService, validator: validator, logger: logger}
 }
 
 // CreateOrder godoc
 //
 //	@Summary		Create a new order
-//	@Description	Create a new order with the given request body
+//	@Description	Create a new order with the given request body. Restaurants that own an organization can send the order from one of its branches
 //	@Tags			orders
 //	@Accept			json
 //	@Produce		json
@@ -37,6 +40,8 @@ func NewOrderHandler(orderService services.OrderService, validator Validator, lo
 //	@Success		201	{object}	models.Order	"Created Order"
 //	@Failure		400	{string}	string			"Invalid request body"
 //	@Failure		404	{string}	string			"no user to receive order"
+//	@Failure		404	{string}	string			"branch not found"
+//	@Failure		409	{string}	string			"restaurant is paused, closed or outside its opening hours"
 //	@Failure		500	{string}	string			"Failed to create order"
 //	@Router			/orders [post]
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
@@ -63,12 +68,54 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	// It should be loosely coupled and only know about the domain models
 	order := utils.MapCreateOrderRequestToOrder(createOrderRequest)
 
-	firebaseUID := r.Context().Value("UID").(string)
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
 
-	order.RestaurantID = firebaseUID
+	order.RestaurantID = restaurantID
+
+	// The order is dispatched from the branch, which has to belong to the organization of the restaurant
+	if createOrderRequest.BranchID != "" {
+		branch, err := h.organizationService.GetBranch(restaurantID, createOrderRequest.BranchID)
+		if err != nil {
+			if errors.Is(err, utils.ErrNotFound) {
+				h.logger.WithError(err).Errorf("Request ID %s: Branch not found", r.Context().Value(chimiddleware.RequestIDKey))
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, "branch not found")
+				return
+			}
+			h.logger.WithError(err).Errorf("Request ID %s: Failed to get branch", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "failed to create order")
+			return
+		}
+		order.RestaurantID = branch.ID
+	}
 
 	createdOrder, err := h.orderService.CreateOrder(order)
 	if err != nil {
+		if errors.Is(err, utils.ErrRestaurantPaused) {
+			h.logger.WithError(err).Errorf("Request ID %s: Restaurant is paused", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "restaurant is paused, resume it to create orders")
+			return
+		}
+		if errors.Is(err, utils.ErrRestaurantClosed) {
+			h.logger.WithError(err).Errorf("Request ID %s: Restaurant is closed", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "restaurant is closed, orders can't be created during a closure")
+			return
+		}
+		if errors.Is(err, utils.ErrOutsideOpeningHours) {
+			h.logger.WithError(err).Errorf("Request ID %s: Restaurant is outside its opening hours", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "restaurant is outside its opening hours")
+			return
+		}
 		if errors.Is(err, utils.ErrNotFound) {
 			h.logger.WithError(err).Errorf("Request ID %s: No user to receive order", r.Context().Value(chimiddleware.RequestIDKey))
 			w.WriteHeader(http.StatusNotFound)
@@ -100,7 +147,13 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 //	@Router			/orders/user [get]
 func (h *OrderHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to get user orders.", r.Context().Value(chimiddleware.RequestIDKey))
-	fbUserID := r.Context().Value("UID").(string)
+	fbUserID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
 
 	orders, err := h.orderService.GetUserOrders(fbUserID)
 	if err != nil {
@@ -135,9 +188,15 @@ func (h *OrderHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 //	@Router			/orders/restaurant [get]
 func (h *OrderHandler) GetRestaurantOrders(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to get restaurant orders.", r.Context().Value(chimiddleware.RequestIDKey))
-	fbUserID := r.Context().Value("UID").(string)
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
 
-	orders, err := h.orderService.GetRestaurantOrders(fbUserID)
+	orders, err := h.orderService.GetRestaurantOrders(restaurantID)
 	if err != nil {
 		if errors.Is(err, utils.ErrNotFound) {
 			h.logger.WithError(err).Errorf("Request ID %s: Order not found", r.Context().Value(chimiddleware.RequestIDKey))
@@ -235,6 +294,7 @@ func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 //	@Security		jwt
 //	@Success		200	{string}	string	"OK"
 //	@Failure		400	{string}	string	"invalid order ID"
+//	@Failure		404	{string}	string	"order not found or not pending"
 //	@Failure		500	{string}	string	"failed to accept order"
 //	@Router			/orders/{id}/accept [patch]
 func (h *OrderHandler) AcceptOrder(w http.ResponseWriter, r *http.Request) {
@@ -249,10 +309,22 @@ func (h *OrderHandler) AcceptOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Here we would get the user ID from the request context
-	firebaseUID := r.Context().Value("UID").(string)
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
 
 	err = h.orderService.AcceptOrder(id, firebaseUID)
 	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order not found or not pending", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "order not found or not pending")
+			return
+		}
 		h.logger.WithError(err).Errorf("Request ID %s: Failed to accept order", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "failed to accept order")
@@ -275,6 +347,7 @@ func (h *OrderHandler) AcceptOrder(w http.ResponseWriter, r *http.Request) {
 //	@Security		jwt
 //	@Success		200	{string}	string	"OK"
 //	@Failure		400	{string}	string	"invalid order ID"
+//	@Failure		404	{string}	string	"order not found or not pending"
 //	@Failure		500	{string}	string	"failed to reject order"
 //	@Router			/orders/{id}/reject [patch]
 func (h *OrderHandler) RejectOrder(w http.ResponseWriter, r *http.Request) {
@@ -289,10 +362,22 @@ func (h *OrderHandler) RejectOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Here we would get the user ID from the request context
-	firebaseUID := r.Context().Value("UID").(string)
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
 
 	err = h.orderService.RejectOrder(id, firebaseUID)
 	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order not found or not pending", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "order not found or not pending")
+			return
+		}
 		h.logger.WithError(err).Errorf("Request ID %s: Failed to reject order", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "failed to reject order")
@@ -315,6 +400,7 @@ func (h *OrderHandler) RejectOrder(w http.ResponseWriter, r *http.Request) {
 //	@Security		jwt
 //	@Success		200	{string}	string	"OK"
 //	@Failure		400	{string}	string	"invalid order ID"
+//	@Failure		409	{string}	string	"order can't be cancelled in its current state"
 //	@Failure		500	{string}	string	"failed to cancel order"
 //	@Router			/orders/{order_id}/cancel [patch]
 func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
@@ -327,11 +413,23 @@ func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Here we would get the user ID from the request context
-	fbRetaurantUID := r.Context().Value("UID").(string)
+	// Here we would get the restaurant ID from the request context
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
 
-	err = h.orderService.CancelOrder(orderID, fbRetaurantUID)
+	err = h.orderService.CancelOrder(orderID, restaurantID)
 	if err != nil {
+		if errors.Is(err, utils.ErrInvalidOrderState) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order can't be cancelled in its current state", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "order can't be cancelled in its current state")
+			return
+		}
 		if errors.Is(err, utils.ErrOrderNotAccepted) {
 			h.logger.WithError(err).Errorf("Request ID %s: Order not accepted", r.Context().Value(chimiddleware.RequestIDKey))
 			w.WriteHeader(http.StatusBadRequest)
@@ -361,7 +459,7 @@ func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 //	@Security		jwt
 //	@Success		200	{string}	string	"OK"
 //	@Failure		400	{string}	string	"invalid order ID"
-//	@Failure		400	{string}	string	"invalid order ID"
+//	@Failure		409	{string}	string	"order can't be fulfilled in its current state"
 //	@Failure		500	{string}	string	"failed to fulfill order"
 //	@Router			/orders/{id}/fulfill [post]
 func (h *OrderHandler) FulfillOrder(w http.ResponseWriter, r *http.Request) {
@@ -374,11 +472,23 @@ func (h *OrderHandler) FulfillOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Here we would get the user ID from the request context
-	firebaseUID := r.Context().Value("UID").(string)
+	// Here we would get the restaurant ID from the request context
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
 
-	err = h.orderService.FulfillOrder(orderID, firebaseUID)
+	err = h.orderService.FulfillOrder(orderID, restaurantID)
 	if err != nil {
+		if errors.Is(err, utils.ErrInvalidOrderState) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order can't be fulfilled in its current state", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "order can't be fulfilled in its current state")
+			return
+		}
 		if errors.Is(err, utils.ErrOrderNotAccepted) {
 			h.logger.WithError(err).Errorf("Request ID %s: Order not accepted", r.Context().Value(chimiddleware.RequestIDKey))
 			w.WriteHeader(http.StatusBadRequest)
@@ -409,6 +519,7 @@ func (h *OrderHandler) FulfillOrder(w http.ResponseWriter, r *http.Request) {
 //	@Success		200	{string}	string	"OK"
 //	@Failure		400	{string}	string	"invalid order ID"
 //	@Failure		404	{string}	string	"no user to receive order"
+//	@Failure		409	{string}	string	"order can't be reassigned in its current state"
 //	@Failure		500	{string}	string	"failed to reassign order"
 //	@Router			/orders/{order_id}/reassign [post]
 func (h *OrderHandler) ReassignOrder(w http.ResponseWriter, r *http.Request) {
@@ -422,11 +533,23 @@ func (h *OrderHandler) ReassignOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	firebaseUID := r.Context().Value("UID").(string)
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
 
-	err = h.orderService.ReassignOrder(orderID, firebaseUID)
+	err = h.orderService.ReassignOrder(orderID, restaurantID)
 
 	if err != nil {
+		if errors.Is(err, utils.ErrInvalidOrderState) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order can't be reassigned in its current state", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "order can't be reassigned in its current state")
+			return
+		}
 		if errors.Is(err, utils.ErrNotFound) {
 			h.logger.WithError(err).Errorf("Request ID %s: No user to receive order", r.Context().Value(chimiddleware.RequestIDKey))
 			w.WriteHeader(http.StatusNotFound)
@@ -444,3 +567,234 @@ func (h *OrderHandler) ReassignOrder(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "OK")
 	h.logger.Infof("Request ID %s: Finished processing request to reassign order.", r.Context().Value(chimiddleware.RequestIDKey))
 }
+
+// MarkOrderDelivered godoc
+//
+//	@Summary		Acknowledge that an order notification was received
+//	@Description	Called by the driver app when the new order notification arrives on the device. Only the first acknowledgement is stored
+//	@Tags			orders
+//	@Produce		json
+//	@Param			id	path	int	true	"Order ID"
+//	@Security		jwt
+//	@Success		200	{string}	string	"OK"
+//	@Failure		400	{string}	string	"invalid order ID"
+//	@Failure		404	{string}	string	"order not found"
+//	@Failure		500	{string}	string	"failed to acknowledge order"
+//	@Router			/orders/{id}/delivered [patch]
+func (h *OrderHandler) MarkOrderDelivered(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to mark order as delivered.", r.Context().Value(chimiddleware.RequestIDKey))
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	err = h.orderService.MarkOrderDelivered(id, firebaseUID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "order not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to mark order as delivered", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to acknowledge order")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "OK")
+	h.logger.Infof("Request ID %s: Finished processing request to mark order as delivered.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// MarkOrderSeen godoc
+//
+//	@Summary		Acknowledge that an order was seen
+//	@Description	Called by the driver app when the driver opens the order. Also marks the order as delivered if it wasn't already
+//	@Tags			orders
+//	@Produce		json
+//	@Param			id	path	int	true	"Order ID"
+//	@Security		jwt
+//	@Success		200	{string}	string	"OK"
+//	@Failure		400	{string}	string	"invalid order ID"
+//	@Failure		404	{string}	string	"order not found"
+//	@Failure		500	{string}	string	"failed to acknowledge order"
+//	@Router			/orders/{id}/seen [patch]
+func (h *OrderHandler) MarkOrderSeen(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to mark order as seen.", r.Context().Value(chimiddleware.RequestIDKey))
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	err = h.orderService.MarkOrderSeen(id, firebaseUID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "order not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to mark order as seen", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to acknowledge order")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "OK")
+	h.logger.Infof("Request ID %s: Finished processing request to mark order as seen.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// StreamOrderEvents godoc
+//
+//	@Summary		Stream order events
+//	@Description	Stream the events of the caller's orders as Server-Sent Events, so the restaurant and driver apps don't have to poll the order listings. Restaurants that own an organization also receive the events of its branches.
+//	@Description	Every event is sent with the event type as the SSE event name and the models.OrderEvent as JSON data. A comment is sent periodically to keep the connection open.
+//	@Tags			orders
+//	@Produce		text/event-stream
+//	@Security		jwt
+//	@Success		200	{object}	models.OrderEvent	"Stream of order events"
+//	@Failure		500	{string}	string				"streaming not supported"
+//	@Failure		500	{string}	string				"failed to subscribe to order events"
+//	@Router			/orders/stream [get]
+func (h *OrderHandler) StreamOrderEvents(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to stream order events.", r.Context().Value(chimiddleware.RequestIDKey))
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.logger.Errorf("Request ID %s: Response writer doesn't support flushing", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "streaming not supported")
+		return
+	}
+
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	// Restaurant staff stream the orders of the restaurant they work for, drivers their own orders
+	subscriberID := firebaseUID
+	if restaurantID, ok := middleware.RestaurantIDFromContext(r.Context()); ok {
+		subscriberID = restaurantID
+	}
+
+	events, unsubscribe, err := h.orderService.SubscribeToOrderEvents(subscriberID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to subscribe to order events", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to subscribe to order events")
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stop proxies like nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(25 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.logger.Infof("Request ID %s: Order events stream closed by the client.", r.Context().Value(chimiddleware.RequestIDKey))
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.WithError(err).Errorf("Request ID %s: Failed to encode order event", r.Context().Value(chimiddleware.RequestIDKey))
+				continue
+			}
+
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// GetOrderLocation godoc
+//
+//	@Summary		Get the location of the driver carrying an order
+//	@Description	Get the latest position and the path of the driver since they accepted the order. Positions are only kept for the configured retention period
+//	@Tags			orders
+//	@Produce		json
+//	@Param			order_id	path	int	true	"Order ID"
+//	@Security		jwt
+//	@Success		200	{object}	models.OrderLocation	"Order Location"
+//	@Failure		400	{string}	string					"invalid id"
+//	@Failure		404	{string}	string					"order not found"
+//	@Failure		500	{string}	string					"failed to get order location"
+//	@Router			/orders/{order_id}/location [get]
+func (h *OrderHandler) GetOrderLocation(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get order location.", r.Context().Value(chimiddleware.RequestIDKey))
+	orderID, err := strconv.Atoi(chi.URLParam(r, "order_id"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	orderLocation, err := h.orderService.GetOrderLocation(orderID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Order not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "order not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get order location", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get order location")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(orderLocation)
+	h.logger.Infof("Request ID %s: Finished processing request to get order location.", r.Context().Value(chimiddleware.RequestIDKey))
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"Tamra/internal/app/tamra/middleware"
+	"Tamra/internal/app/tamra/services"
+	"Tamra/internal/pkg/models"
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOrderService streams the events published on its broker to every subscriber and records who subscribed
+type fakeOrderService struct {
+	services.OrderService
+	broker       *services.InProcessOrderEventBroker
+	err          error
+	subscriberID chan string
+	unsubscribed chan struct{}
+}
+
+func newFakeOrderService() *fakeOrderService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &fakeOrderService{
+		broker:       services.NewInProcessOrderEventBroker(logger),
+		subscriberID: make(chan string, 1),
+		unsubscribed: make(chan struct{}),
+	}
+}
+
+func (s *fakeOrderService) SubscribeToOrderEvents(fbUID string) (<-chan *models.OrderEvent, func(), error) {
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	events, unsubscribe := s.broker.Subscribe(func(event *models.OrderEvent) bool { return true })
+	s.subscriberID <- fbUID
+	return events, func() {
+		unsubscribe()
+		close(s.unsubscribed)
+	}, nil
+}
+
+// newStreamServer serves StreamOrderEvents to requests sent on behalf of the principal, nil sends them without one
+func newStreamServer(orderService services.OrderService, principal *middleware.Principal) *httptest.Server {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewOrderHandler(orderService, nil, nil, logger)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal != nil {
+			r = r.WithContext(middleware.WithPrincipal(r.Context(), principal))
+		}
+		handler.StreamOrderEvents(w, r)
+	}))
+}
+
+// readEvent returns the next event of the stream as its name and data lines
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestOrderHandler_StreamOrderEvents(t *testing.T) {
+	orderService := newFakeOrderService()
+	server := newStreamServer(orderService, &middleware.Principal{UID: "driver1", Role: models.RoleDriver})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Drivers stream their own orders
+	assert.Equal(t, "driver1", <-orderService.subscriberID)
+
+	occurredAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, orderService.broker.Publish(&models.OrderEvent{Type: models.OrderEventCreated, OrderID: 1, RestaurantID: "restaurant1", UserID: "driver1", OccurredAt: occurredAt}))
+
+	reader := bufio.NewReader(resp.Body)
+	event := readEvent(t, reader)
+	assert.Equal(t, `event: ORDER_CREATED
+data: {"type":"ORDER_CREATED","order_id":1,"restaurant_id":"restaurant1","user_id":"driver1","code":"","state":"","occurred_at":"2024-01-01T12:00:00Z"}`, event)
+
+	// Closing the stream unsubscribes it
+	resp.Body.Close()
+	select {
+	case <-orderService.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream wasn't unsubscribed after the client left")
+	}
+}
+
+func TestOrderHandler_StreamOrderEventsOfRestaurant(t *testing.T) {
+	orderService := newFakeOrderService()
+	server := newStreamServer(orderService, &middleware.Principal{UID: "staff1", Role: models.RoleRestaurantStaff, RestaurantID: "restaurant1"})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Restaurant staff stream the orders of the restaurant they work for
+	assert.Equal(t, "restaurant1", <-orderService.subscriberID)
+}
+
+func TestOrderHandler_StreamOrderEventsFails(t *testing.T) {
+	orderService := newFakeOrderService()
+	orderService.err = errors.New("database is down")
+	server := newStreamServer(orderService, &middleware.Principal{UID: "driver1", Role: models.RoleDriver})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "failed to subscribe to order events", string(body))
+
+	// Requests that didn't go through the authentication have no one to stream the orders of
+	server = newStreamServer(newFakeOrderService(), nil)
+	defer server.Close()
+
+	resp, err = http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
@@ -0,0 +1,462 @@
+package handlers
+
+import (
+	"Tamra/internal/app/tamra/middleware"
+	"Tamra/internal/app/tamra/services"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+type OrganizationHandler struct {
+	organizationService services.OrganizationService
+	restaurantService   services.RestaurantService
+	orderService        services.OrderService
+	validator           Validator
+	logger              logrus.FieldLogger
+}
+
+func NewOrganizationHandler(organizationService services.OrganizationService, restaurantService services.RestaurantService, orderService services.OrderService, validator Validator, logger logrus.FieldLogger) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService, restaurantService: restaurantService, orderService: orderService, validator: validator, logger: logger}
+}
+
+// CreateOrganization godoc
+//
+//	@Summary		Create an organization
+//	@Description	Create an organization owned by the restaurant, to manage the branches of a chain from the account of the restaurant. The restaurant becomes the first branch of the organization
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.CreateOrganizationRequest	true	"Create Organization Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.Organization	"Created organization"
+//	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		409	{string}	string				"already in an organization"
+//	@Failure		500	{string}	string				"Failed to create organization"
+//	@Router			/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to create organization.", r.Context().Value(chimiddleware.RequestIDKey))
+	createOrganizationRequest := &models.CreateOrganizationRequest{}
+	err := json.NewDecoder(r.Body).Decode(createOrganizationRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(createOrganizationRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	organization := &models.Organization{Name: createOrganizationRequest.Name, OwnerRestaurantID: restaurantID}
+	createdOrganization, err := h.organizationService.CreateOrganization(organization)
+	if err != nil {
+		if errors.Is(err, utils.ErrAlreadyInOrganization) {
+			h.logger.WithError(err).Errorf("Request ID %s: Restaurant already in an organization", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "already in an organization")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to create organization", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create organization")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdOrganization)
+	h.logger.Infof("Request ID %s: Finished processing request to create organization.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetOrganization godoc
+//
+//	@Summary		Get the organization
+//	@Description	Get the organization owned by the restaurant
+//	@Tags			organizations
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{object}	models.Organization	"Organization"
+//	@Failure		404	{string}	string				"organization not found"
+//	@Failure		500	{string}	string				"Failed to get organization"
+//	@Router			/organizations/me [get]
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get organization.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	organization, err := h.organizationService.GetOrganization(restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Organization not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "organization not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get organization", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get organization")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+	h.logger.Infof("Request ID %s: Finished processing request to get organization.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetBranches godoc
+//
+//	@Summary		Get the branches of the organization
+//	@Description	Get the restaurants of the organization owned by the restaurant, including the restaurant itself
+//	@Tags			organizations
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.Restaurant	"Branches"
+//	@Failure		404	{string}	string				"organization not found"
+//	@Failure		500	{string}	string				"Failed to get branches"
+//	@Router			/organizations/me/branches [get]
+func (h *OrganizationHandler) GetBranches(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get branches.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	branches, err := h.organizationService.GetBranches(restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Organization not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "organization not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get branches", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get branches")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(branches)
+	h.logger.Infof("Request ID %s: Finished processing request to get branches.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// CreateBranch godoc
+//
+//	@Summary		Create a branch
+//	@Description	Create a restaurant in the organization owned by the restaurant. The orders of the branch are dispatched from its own location
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.CreateRestaurantRequest	true	"Create Restaurant Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.Restaurant	"Created branch"
+//	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		404	{string}	string				"organization not found"
+//	@Failure		500	{string}	string				"Failed to create branch"
+//	@Router			/organizations/me/branches [post]
+func (h *OrganizationHandler) CreateBranch(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to create branch.", r.Context().Value(chimiddleware.RequestIDKey))
+	createRestaurantRequest := &models.CreateRestaurantRequest{}
+	err := json.NewDecoder(r.Body).Decode(createRestaurantRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(createRestaurantRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	branch := utils.MapCreateRestaurantRequestToRestaurant(createRestaurantRequest)
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	createdBranch, err := h.organizationService.CreateBranch(restaurantID, branch)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Organization not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "organization not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to create branch", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create branch")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdBranch)
+	h.logger.Infof("Request ID %s: Finished processing request to create branch.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// UpdateBranch godoc
+//
+//	@Summary		Update a branch
+//	@Description	Update the fields of a branch of the organization that are present in the request body, the others keep their value
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			branchID	path	string							true	"Branch ID"
+//	@Param			request		body	models.UpdateRestaurantRequest	true	"Update Restaurant Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.Restaurant	"Updated branch"
+//	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		404	{string}	string				"branch not found"
+//	@Failure		500	{string}	string				"Failed to update branch"
+//	@Router			/organizations/me/branches/{branchID} [patch]
+func (h *OrganizationHandler) UpdateBranch(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to update branch.", r.Context().Value(chimiddleware.RequestIDKey))
+	branchID := chi.URLParam(r, "branchID")
+
+	updateRestaurantRequest := &models.UpdateRestaurantRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateRestaurantRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(updateRestaurantRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, utils.FormatValidationError(err))
+		return
+	}
+
+	patch := utils.MapUpdateRestaurantRequestToRestaurantPatch(updateRestaurantRequest)
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	updatedBranch, err := h.organizationService.UpdateBranch(restaurantID, branchID, patch)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Branch not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "branch not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to update branch", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to update branch")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedBranch)
+	h.logger.Infof("Request ID %s: Finished processing request to update branch.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteBranch godoc
+//
+//	@Summary		Delete a branch
+//	@Description	Delete a branch of the organization. The restaurant that owns the organization is deleted with its account instead
+//	@Tags			organizations
+//	@Param			branchID	path	string	true	"Branch ID"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Branch deleted"
+//	@Failure		404	{string}	string	"branch not found"
+//	@Failure		500	{string}	string	"Failed to delete branch"
+//	@Router			/organizations/me/branches/{branchID} [delete]
+func (h *OrganizationHandler) DeleteBranch(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete branch.", r.Context().Value(chimiddleware.RequestIDKey))
+	branchID := chi.URLParam(r, "branchID")
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err := h.organizationService.DeleteBranch(restaurantID, branchID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Branch not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "branch not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete branch", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete branch")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete branch.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// CreateBranchInvite godoc
+//
+//	@Summary		Invite a staff member to a branch
+//	@Description	Invite the account with the email to join a branch of the organization as a manager or cashier, the staff of a branch handle its orders and notifications
+//	@Tags			organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			branchID	path	string									true	"Branch ID"
+//	@Param			request		body	models.CreateRestaurantInviteRequest	true	"Create Restaurant Invite Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.RestaurantInvite	"Created invite"
+//	@Failure		400	{string}	string					"Invalid request body"
+//	@Failure		404	{string}	string					"branch not found"
+//	@Failure		500	{string}	string					"Failed to create invite"
+//	@Router			/organizations/me/branches/{branchID}/invites [post]
+func (h *OrganizationHandler) CreateBranchInvite(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to create branch invite.", r.Context().Value(chimiddleware.RequestIDKey))
+	branchID := chi.URLParam(r, "branchID")
+
+	createInviteRequest := &models.CreateRestaurantInviteRequest{}
+	err := json.NewDecoder(r.Body).Decode(createInviteRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(createInviteRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	branch, err := h.organizationService.GetBranch(restaurantID, branchID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Branch not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "branch not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get branch", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create invite")
+		return
+	}
+
+	invite := &models.RestaurantInvite{
+		RestaurantID: branch.ID,
+		Email:        createInviteRequest.Email,
+		Role:         createInviteRequest.Role,
+	}
+
+	createdInvite, err := h.restaurantService.InviteMember(invite)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to create invite", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdInvite)
+	h.logger.Infof("Request ID %s: Finished processing request to create branch invite.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetOrders godoc
+//
+//	@Summary		Get the orders of the organization
+//	@Description	Get the orders of all the branches of the organization owned by the restaurant, the newest first
+//	@Tags			organizations
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.Order	"Organization orders"
+//	@Failure		404	{string}	string			"organization not found"
+//	@Failure		500	{string}	string			"Failed to get orders"
+//	@Router			/organizations/me/orders [get]
+func (h *OrganizationHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get organization orders.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	organization, err := h.organizationService.GetOrganization(restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Organization not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "organization not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get organization", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get orders")
+		return
+	}
+
+	orders, err := h.orderService.GetOrganizationOrders(organization.ID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get orders", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get orders")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(orders)
+	h.logger.Infof("Request ID %s: Finished processing request to get organization orders.", r.Context().Value(chimiddleware.RequestIDKey))
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"Tamra/internal/pkg/models"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// The pages of the lists have defaultPageLimit items unless the client asks for more, up to maxPageLimit
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// parsePagination reads the limit and offset query parameters, the first page by default
+func parsePagination(query url.Values) (models.Pagination, error) {
+	pagination := models.Pagination{Limit: defaultPageLimit}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxPageLimit {
+			return pagination, fmt.Errorf("invalid limit %q", limit)
+		}
+		pagination.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return pagination, fmt.Errorf("invalid offset %q", offset)
+		}
+		pagination.Offset = value
+	}
+	return pagination, nil
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"Tamra/internal/app/tamra/middleware"
 	"Tamra/internal/app/tamra/services"
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
@@ -62,7 +65,7 @@ func (h *RestaurantHandler) CreateRestaurant(w http.ResponseWriter, r *http.Requ
 	// It should be loosely coupled and only know about the domain models
 	restaurant := utils.MapCreateRestaurantRequestToRestaurant(createRestaurantRequest)
 	// Extract the user ID from the request context
-	firebaseUID, ok := r.Context().Value("UID").(string)
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
 	if !ok {
 		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -98,17 +101,16 @@ func (h *RestaurantHandler) CreateRestaurant(w http.ResponseWriter, r *http.Requ
 //	@Router			/restaurants/me [get]
 func (h *RestaurantHandler) GetRestaurant(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to get restaurant.", r.Context().Value(chimiddleware.RequestIDKey))
-	// Extract the user ID from the request context
-	fbUID := r.Context().Value("UID").(string)
-
-	if fbUID == "" {
-		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+	// Extract the restaurant ID from the request context
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "failed to get user ID from request context")
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
 		return
 	}
 
-	restaurant, err := h.restaurantService.GetRestaurant(fbUID)
+	restaurant, err := h.restaurantService.GetRestaurant(restaurantID)
 	if err != nil {
 		if errors.Is(err, utils.ErrNotFound) {
 			h.logger.WithError(err).Errorf("Request ID %s: Restaurant not found", r.Context().Value(chimiddleware.RequestIDKey))
@@ -130,20 +132,20 @@ func (h *RestaurantHandler) GetRestaurant(w http.ResponseWriter, r *http.Request
 // GetRestaurantByID godoc
 //
 //	@Summary		Get a restaurant by ID
-//	@Description	Get a restaurant by the restaurant ID
+//	@Description	Get a restaurant by the restaurant ID, with its opening hours, its current and upcoming closures and whether it takes orders right now
 //	@Tags			restaurants
 //	@Produce		json
 //	@Param			restaurantID	path	string	true	"Restaurant ID"
 //	@Security		jwt
-//	@Success		200	{object}	models.Restaurant	"Restaurant"
-//	@Failure		404	{string}	string				"Restaurant not found"
-//	@Failure		500	{string}	string				"Failed to get restaurant"
+//	@Success		200	{object}	models.RestaurantDetails	"Restaurant"
+//	@Failure		404	{string}	string						"Restaurant not found"
+//	@Failure		500	{string}	string						"Failed to get restaurant"
 //	@Router			/restaurants/{restaurantID} [get]
 func (h *RestaurantHandler) GetRestaurantByID(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to get restaurant by ID.", r.Context().Value(chimiddleware.RequestIDKey))
 	restaurantID := chi.URLParam(r, "restaurantID")
 
-	restaurant, err := h.restaurantService.GetRestaurantByID(restaurantID)
+	restaurant, err := h.restaurantService.GetRestaurantDetails(restaurantID)
 	if err != nil {
 		if errors.Is(err, utils.ErrNotFound) {
 			h.logger.WithError(err).Errorf("Request ID %s: Restaurant not found", r.Context().Value(chimiddleware.RequestIDKey))
@@ -162,10 +164,93 @@ func (h *RestaurantHandler) GetRestaurantByID(w http.ResponseWriter, r *http.Req
 	h.logger.Infof("Request ID %s: Finished processing request to get restaurant by ID.", r.Context().Value(chimiddleware.RequestIDKey))
 }
 
+// maxNearbyRadius is the largest radius in meters the drivers can search for restaurants in
+const maxNearbyRadius = 50000
+
+// GetNearbyRestaurants godoc
+//
+//	@Summary		Get the restaurants near a location
+//	@Description	Get a page of the restaurants within a radius of a location, the closest first, with their distance in meters. The last location and the radius of the driver are used unless other ones are sent, the longitude and latitude have to be sent together
+//	@Tags			restaurants
+//	@Produce		json
+//	@Param			longitude	query	number	false	"Longitude to search around, the driver's last one by default"
+//	@Param			latitude	query	number	false	"Latitude to search around, the driver's last one by default"
+//	@Param			radius		query	int		false	"Radius in meters, the driver's radius by default"
+//	@Param			limit		query	int		false	"Size of the page, 50 by default"
+//	@Param			offset		query	int		false	"Number of restaurants to skip"
+//	@Security		jwt
+//	@Success		200	{object}	models.Page[models.NearbyRestaurantResponse]	"Nearby restaurants"
+//	@Failure		400	{string}	string											"invalid location, radius or pagination"
+//	@Failure		404	{string}	string											"user not found"
+//	@Failure		500	{string}	string											"failed to get nearby restaurants"
+//	@Router			/restaurants/nearby [get]
+func (h *RestaurantHandler) GetNearbyRestaurants(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get nearby restaurants.", r.Context().Value(chimiddleware.RequestIDKey))
+	query := r.URL.Query()
+	pagination, err := parsePagination(query)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid pagination", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid limit or offset")
+		return
+	}
+
+	filter := &models.NearbyRestaurantFilter{Pagination: pagination}
+	if query.Has("longitude") || query.Has("latitude") {
+		longitude, longitudeErr := strconv.ParseFloat(query.Get("longitude"), 64)
+		latitude, latitudeErr := strconv.ParseFloat(query.Get("latitude"), 64)
+		// ParseFloat accepts "NaN", which compares false to every bound
+		if longitudeErr != nil || latitudeErr != nil || math.IsNaN(longitude) || math.IsNaN(latitude) ||
+			longitude < -180 || longitude > 180 || latitude < -90 || latitude > 90 {
+			h.logger.Errorf("Request ID %s: Invalid location %q, %q", r.Context().Value(chimiddleware.RequestIDKey), query.Get("longitude"), query.Get("latitude"))
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid location, send both the longitude and the latitude")
+			return
+		}
+		filter.Longitude, filter.Latitude = &longitude, &latitude
+	}
+
+	if radius := query.Get("radius"); radius != "" {
+		filter.Radius, err = strconv.Atoi(radius)
+		if err != nil || filter.Radius < 1 || filter.Radius > maxNearbyRadius {
+			h.logger.Errorf("Request ID %s: Invalid radius %q", r.Context().Value(chimiddleware.RequestIDKey), radius)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "invalid radius, it has to be between 1 and %d meters", maxNearbyRadius)
+			return
+		}
+	}
+
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	restaurants, total, err := h.restaurantService.GetNearbyRestaurants(userID, filter)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get nearby restaurants", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get nearby restaurants")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(&models.Page[*models.NearbyRestaurantResponse]{Items: utils.MapNearbyRestaurantsToNearbyRestaurantResponses(restaurants), Total: total, Limit: pagination.Limit, Offset: pagination.Offset})
+	h.logger.Infof("Request ID %s: Finished processing request to get nearby restaurants.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
 // UpdateRestaurant godoc
 //
 //	@Summary		Update a restaurant
-//	@Description	Update a restaurant with the given request body
+//	@Description	Update the fields of the restaurant sent in the body, the other ones keep their current value. The longitude and latitude have to be sent together. Pausing the restaurant stops it from creating orders until it is resumed
 //	@Tags			restaurants
 //	@Accept			json
 //	@Produce		json
@@ -173,6 +258,7 @@ func (h *RestaurantHandler) GetRestaurantByID(w http.ResponseWriter, r *http.Req
 //	@Security		jwt
 //	@Success		200	{object}	models.Restaurant	"Updated Restaurant"
 //	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		404	{string}	string				"restaurant not found"
 //	@Failure		500	{string}	string				"Failed to update restaurant"
 //	@Router			/restaurants/me [patch]
 func (h *RestaurantHandler) UpdateRestaurant(w http.ResponseWriter, r *http.Request) {
@@ -191,25 +277,29 @@ func (h *RestaurantHandler) UpdateRestaurant(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, "invalid request body")
+		fmt.Fprint(w, utils.FormatValidationError(err))
 		return
 	}
 
-	restaurant := utils.MapUpdateRestaurantRequestToRestaurant(updateRestaurantRequest)
+	patch := utils.MapUpdateRestaurantRequestToRestaurantPatch(updateRestaurantRequest)
 
-	firebaseUID, ok := r.Context().Value("UserID").(string)
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
 
 	if !ok {
-		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "failed to get user ID from request context")
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
 		return
 	}
 
-	restaurant.ID = firebaseUID
-
-	updatedRestaurant, err := h.restaurantService.UpdateRestaurant(restaurant)
+	updatedRestaurant, err := h.restaurantService.UpdateRestaurant(restaurantID, patch)
 	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Restaurant not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "restaurant not found")
+			return
+		}
 		h.logger.WithError(err).Errorf("Request ID %s: Failed to update restaurant", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "failed to update restaurant")
@@ -233,17 +323,16 @@ func (h *RestaurantHandler) UpdateRestaurant(w http.ResponseWriter, r *http.Requ
 //	@Router			/restaurants/logo/uploadurl [get]
 func (h *RestaurantHandler) GetLogoUploadURL(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to get logo upload URL.", r.Context().Value(chimiddleware.RequestIDKey))
-	// Extract the user ID from the request context
-	UID := r.Context().Value("UID").(string)
-
-	if UID == "" {
-		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+	// Extract the restaurant ID from the request context
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "failed to get user ID from request context")
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
 		return
 	}
 
-	presignedURL, storedFileURL, err := h.restaurantService.GetLogoUploadURL(UID, h.config.RestaurantLogosBucket)
+	presignedURL, storedFileURL, err := h.restaurantService.GetLogoUploadURL(restaurantID, h.config.RestaurantLogosBucket)
 	if err != nil {
 		h.logger.WithError(err).Errorf("Request ID %s: Failed to get upload URL", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -266,7 +355,7 @@ func (h *RestaurantHandler) GetLogoUploadURL(w http.ResponseWriter, r *http.Requ
 // DeleteRestaurant godoc
 //
 //	@Summary		Delete a restaurant
-//	@Description	Delete a restaurant
+//	@Description	Delete the restaurant and its account, signing it out of all its sessions
 //	@Tags			restaurants
 //	@Security		jwt
 //	@Success		204	{string}	string	"Restaurant deleted"
@@ -274,17 +363,16 @@ func (h *RestaurantHandler) GetLogoUploadURL(w http.ResponseWriter, r *http.Requ
 //	@Router			/restaurants/me [delete]
 func (h *RestaurantHandler) DeleteRestaurant(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to delete restaurant.", r.Context().Value(chimiddleware.RequestIDKey))
-	// Extract the user ID from the request context
-	UID := r.Context().Value("UID").(string)
-
-	if UID == "" {
-		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+	// Extract the restaurant ID from the request context
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "failed to get user ID from request context")
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
 		return
 	}
 
-	err := h.restaurantService.DeleteRestaurant(UID)
+	err := h.restaurantService.DeleteRestaurant(restaurantID)
 	if err != nil {
 		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete restaurant", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -295,3 +383,1002 @@ func (h *RestaurantHandler) DeleteRestaurant(w http.ResponseWriter, r *http.Requ
 	w.WriteHeader(http.StatusNoContent)
 	h.logger.Infof("Request ID %s: Finished processing request to delete restaurant.", r.Context().Value(chimiddleware.RequestIDKey))
 }
+
+// RegisterDevice godoc
+//
+//	@Summary		Register a device for notifications
+//	@Description	Register the FCM token of a restaurant device (mobile app or web push subscription) to be notified when drivers accept, reject or let an order expire
+//	@Tags			restaurants
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.RegisterRestaurantDeviceRequest	true	"Register Device Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.RestaurantDeviceResponse	"Registered Device"
+//	@Failure		400	{string}	string							"Invalid request body"
+//	@Failure		500	{string}	string							"Failed to register device"
+//	@Router			/restaurants/me/devices [post]
+func (h *RestaurantHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to register restaurant device.", r.Context().Value(chimiddleware.RequestIDKey))
+	registerDeviceRequest := &models.RegisterRestaurantDeviceRequest{}
+	err := json.NewDecoder(r.Body).Decode(registerDeviceRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(registerDeviceRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	device := utils.MapRegisterRestaurantDeviceRequestToRestaurantDevice(registerDeviceRequest)
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	device.RestaurantID = restaurantID
+
+	registeredDevice, err := h.restaurantService.RegisterDevice(device)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to register device", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to register device")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(utils.MapRestaurantDeviceToRestaurantDeviceResponse(registeredDevice))
+	h.logger.Infof("Request ID %s: Finished processing request to register restaurant device.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetDevices godoc
+//
+//	@Summary		Get the registered devices
+//	@Description	Get the devices the restaurant registered for notifications
+//	@Tags			restaurants
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.RestaurantDeviceResponse	"Registered Devices"
+//	@Failure		500	{string}	string							"Failed to get devices"
+//	@Router			/restaurants/me/devices [get]
+func (h *RestaurantHandler) GetDevices(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get restaurant devices.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	devices, err := h.restaurantService.GetDevices(restaurantID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get devices", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get devices")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(utils.MapRestaurantDevicesToRestaurantDeviceResponses(devices))
+	h.logger.Infof("Request ID %s: Finished processing request to get restaurant devices.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteDevice godoc
+//
+//	@Summary		Unregister a device
+//	@Description	Stop sending notifications to a device of the restaurant, e.g. when logging out
+//	@Tags			restaurants
+//	@Param			deviceID	path	int	true	"Device ID"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Device deleted"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"device not found"
+//	@Failure		500	{string}	string	"Failed to delete device"
+//	@Router			/restaurants/me/devices/{deviceID} [delete]
+func (h *RestaurantHandler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete restaurant device.", r.Context().Value(chimiddleware.RequestIDKey))
+	deviceID, err := strconv.Atoi(chi.URLParam(r, "deviceID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err = h.restaurantService.DeleteDevice(deviceID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Device not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "device not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete device", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete device")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete restaurant device.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// SetDriverPreference godoc
+//
+//	@Summary		Prefer or block a driver
+//	@Description	Mark a driver as preferred, so they receive the orders of the restaurant before the other drivers in reach, or as blocked, so they never receive them
+//	@Tags			restaurants
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	string								true	"User ID of the driver"
+//	@Param			request	body	models.SetDriverPreferenceRequest	true	"Set Driver Preference Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.RestaurantDriverPreference	"Driver Preference"
+//	@Failure		400	{string}	string								"Invalid request body"
+//	@Failure		404	{string}	string								"driver not found"
+//	@Failure		500	{string}	string								"Failed to set driver preference"
+//	@Router			/restaurants/me/drivers/{userID} [put]
+func (h *RestaurantHandler) SetDriverPreference(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to set driver preference.", r.Context().Value(chimiddleware.RequestIDKey))
+	setDriverPreferenceRequest := &models.SetDriverPreferenceRequest{}
+	err := json.NewDecoder(r.Body).Decode(setDriverPreferenceRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(setDriverPreferenceRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	preference := &models.RestaurantDriverPreference{
+		RestaurantID: restaurantID,
+		UserID:       chi.URLParam(r, "userID"),
+		Preference:   setDriverPreferenceRequest.Preference,
+		Note:         setDriverPreferenceRequest.Note,
+	}
+
+	updatedPreference, err := h.restaurantService.SetDriverPreference(preference)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Driver not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "driver not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to set driver preference", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to set driver preference")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedPreference)
+	h.logger.Infof("Request ID %s: Finished processing request to set driver preference.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetDriverPreferences godoc
+//
+//	@Summary		Get the preferred and blocked drivers
+//	@Description	Get the drivers the restaurant prefers or blocked
+//	@Tags			restaurants
+//	@Produce		json
+//	@Param			preference	query	string	false	"Only return the drivers with this preference"	Enums(PREFERRED, BLOCKED)
+//	@Security		jwt
+//	@Success		200	{array}		models.RestaurantDriverPreference	"Driver Preferences"
+//	@Failure		400	{string}	string								"invalid preference"
+//	@Failure		500	{string}	string								"Failed to get driver preferences"
+//	@Router			/restaurants/me/drivers [get]
+func (h *RestaurantHandler) GetDriverPreferences(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get driver preferences.", r.Context().Value(chimiddleware.RequestIDKey))
+	preference := r.URL.Query().Get("preference")
+	if preference != "" && preference != models.DriverPreferencePreferred && preference != models.DriverPreferenceBlocked {
+		h.logger.Errorf("Request ID %s: Invalid preference %s", r.Context().Value(chimiddleware.RequestIDKey), preference)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid preference")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	preferences, err := h.restaurantService.GetDriverPreferences(restaurantID, preference)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get driver preferences", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get driver preferences")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(preferences)
+	h.logger.Infof("Request ID %s: Finished processing request to get driver preferences.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteDriverPreference godoc
+//
+//	@Summary		Remove a driver preference
+//	@Description	Stop preferring or unblock a driver, they are then dispatched like any other driver
+//	@Tags			restaurants
+//	@Param			userID	path	string	true	"User ID of the driver"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Driver preference deleted"
+//	@Failure		404	{string}	string	"driver preference not found"
+//	@Failure		500	{string}	string	"Failed to delete driver preference"
+//	@Router			/restaurants/me/drivers/{userID} [delete]
+func (h *RestaurantHandler) DeleteDriverPreference(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete driver preference.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err := h.restaurantService.DeleteDriverPreference(restaurantID, chi.URLParam(r, "userID"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Driver preference not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "driver preference not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete driver preference", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete driver preference")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete driver preference.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetMembers godoc
+//
+//	@Summary		Get the members of the restaurant
+//	@Description	Get the owner and the staff members of the restaurant
+//	@Tags			restaurants
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.RestaurantMember	"Members"
+//	@Failure		500	{string}	string					"Failed to get members"
+//	@Router			/restaurants/me/members [get]
+func (h *RestaurantHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get restaurant members.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	members, err := h.restaurantService.GetMembers(restaurantID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get members", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get members")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(members)
+	h.logger.Infof("Request ID %s: Finished processing request to get restaurant members.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Remove a staff member
+//	@Description	Stop a staff member from acting for the restaurant. The owner can't be removed
+//	@Tags			restaurants
+//	@Param			uid	path	string	true	"UID of the staff member"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Member removed"
+//	@Failure		404	{string}	string	"member not found"
+//	@Failure		500	{string}	string	"Failed to remove member"
+//	@Router			/restaurants/me/members/{uid} [delete]
+func (h *RestaurantHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to remove restaurant member.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err := h.restaurantService.RemoveMember(restaurantID, chi.URLParam(r, "uid"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Member not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "member not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to remove member", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to remove member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to remove restaurant member.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// CreateInvite godoc
+//
+//	@Summary		Invite a staff member
+//	@Description	Invite the account with the email to join the restaurant as a manager or cashier. The invitee sees the invite once signed in with the email, it expires after a week
+//	@Tags			restaurants
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.CreateRestaurantInviteRequest	true	"Create Restaurant Invite Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.RestaurantInvite	"Created invite"
+//	@Failure		400	{string}	string					"Invalid request body"
+//	@Failure		500	{string}	string					"Failed to create invite"
+//	@Router			/restaurants/me/invites [post]
+func (h *RestaurantHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to create restaurant invite.", r.Context().Value(chimiddleware.RequestIDKey))
+	createInviteRequest := &models.CreateRestaurantInviteRequest{}
+	err := json.NewDecoder(r.Body).Decode(createInviteRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(createInviteRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	invite := &models.RestaurantInvite{
+		RestaurantID: restaurantID,
+		Email:        createInviteRequest.Email,
+		Role:         createInviteRequest.Role,
+	}
+
+	createdInvite, err := h.restaurantService.InviteMember(invite)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to create invite", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdInvite)
+	h.logger.Infof("Request ID %s: Finished processing request to create restaurant invite.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetInvites godoc
+//
+//	@Summary		Get the pending invites of the restaurant
+//	@Description	Get the invites of the restaurant that weren't accepted and didn't expire
+//	@Tags			restaurants
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.RestaurantInvite	"Invites"
+//	@Failure		500	{string}	string					"Failed to get invites"
+//	@Router			/restaurants/me/invites [get]
+func (h *RestaurantHandler) GetInvites(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get restaurant invites.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	invites, err := h.restaurantService.GetInvites(restaurantID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get invites", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get invites")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invites)
+	h.logger.Infof("Request ID %s: Finished processing request to get restaurant invites.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteInvite godoc
+//
+//	@Summary		Cancel an invite
+//	@Description	Cancel a pending invite of the restaurant
+//	@Tags			restaurants
+//	@Param			inviteID	path	int	true	"Invite ID"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Invite deleted"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"invite not found"
+//	@Failure		500	{string}	string	"Failed to delete invite"
+//	@Router			/restaurants/me/invites/{inviteID} [delete]
+func (h *RestaurantHandler) DeleteInvite(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete restaurant invite.", r.Context().Value(chimiddleware.RequestIDKey))
+	inviteID, err := strconv.Atoi(chi.URLParam(r, "inviteID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err = h.restaurantService.DeleteInvite(inviteID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Invite not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "invite not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete invite", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete restaurant invite.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetReceivedInvites godoc
+//
+//	@Summary		Get the invites sent to the account
+//	@Description	Get the pending invites sent to the verified email of the signed in account
+//	@Tags			restaurants
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.RestaurantInvite	"Invites"
+//	@Failure		403	{string}	string					"email not verified"
+//	@Failure		500	{string}	string					"Failed to get invites"
+//	@Router			/restaurants/invites [get]
+func (h *RestaurantHandler) GetReceivedInvites(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get received restaurant invites.", r.Context().Value(chimiddleware.RequestIDKey))
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get principal from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	// Invites are sent by email, only the owner of the email can see them
+	if principal.Email == "" || !principal.EmailVerified {
+		h.logger.Errorf("Request ID %s: Email of user %s isn't verified", r.Context().Value(chimiddleware.RequestIDKey), principal.UID)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "email not verified")
+		return
+	}
+
+	invites, err := h.restaurantService.GetInvitesForEmail(principal.Email)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get invites", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get invites")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invites)
+	h.logger.Infof("Request ID %s: Finished processing request to get received restaurant invites.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// AcceptInvite godoc
+//
+//	@Summary		Accept an invite
+//	@Description	Join the restaurant that invited the verified email of the signed in account. Accounts without a role become restaurant staff and have to refresh their token, drivers and restaurant owners can't join a restaurant
+//	@Tags			restaurants
+//	@Produce		json
+//	@Param			inviteID	path	int	true	"Invite ID"
+//	@Security		jwt
+//	@Success		200	{object}	models.RestaurantMember	"Membership"
+//	@Failure		400	{string}	string					"invalid id"
+//	@Failure		403	{string}	string					"email not verified"
+//	@Failure		404	{string}	string					"invite not found"
+//	@Failure		409	{string}	string					"already a member of a restaurant or role already assigned"
+//	@Failure		500	{string}	string					"Failed to accept invite"
+//	@Router			/restaurants/invites/{inviteID}/accept [post]
+func (h *RestaurantHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to accept restaurant invite.", r.Context().Value(chimiddleware.RequestIDKey))
+	inviteID, err := strconv.Atoi(chi.URLParam(r, "inviteID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get principal from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	if principal.Email == "" || !principal.EmailVerified {
+		h.logger.Errorf("Request ID %s: Email of user %s isn't verified", r.Context().Value(chimiddleware.RequestIDKey), principal.UID)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "email not verified")
+		return
+	}
+
+	member, err := h.restaurantService.AcceptInvite(inviteID, principal.UID, principal.Email)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Invite not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "invite not found")
+			return
+		}
+		if errors.Is(err, utils.ErrAlreadyMember) {
+			h.logger.WithError(err).Errorf("Request ID %s: User already works for a restaurant", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "already a member of a restaurant")
+			return
+		}
+		if errors.Is(err, utils.ErrRoleAlreadyAssigned) {
+			h.logger.WithError(err).Errorf("Request ID %s: User already has a role", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "role already assigned")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to accept invite", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to accept invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+	h.logger.Infof("Request ID %s: Finished processing request to accept restaurant invite.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetOpeningHours godoc
+//
+//	@Summary		Get the opening hours of the restaurant
+//	@Description	Get the timezone and weekly opening hours of the restaurant. Restaurants without opening hours take orders at any time
+//	@Tags			restaurants
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{object}	models.RestaurantOpeningHours	"Opening hours"
+//	@Failure		404	{string}	string							"restaurant not found"
+//	@Failure		500	{string}	string							"failed to get opening hours"
+//	@Router			/restaurants/me/opening-hours [get]
+func (h *RestaurantHandler) GetOpeningHours(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get restaurant opening hours.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	openingHours, err := h.restaurantService.GetOpeningHours(restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Restaurant not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "restaurant not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get opening hours", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get opening hours")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(openingHours)
+	h.logger.Infof("Request ID %s: Finished processing request to get restaurant opening hours.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// UpdateOpeningHours godoc
+//
+//	@Summary		Replace the opening hours of the restaurant
+//	@Description	Replace the timezone and weekly opening hours of the restaurant. Weekdays go from 0 (Sunday) to 6, times are HH:MM in the restaurant's timezone and a period closing before its open time, e.g. 18:00 to 02:00, closes the next day. Send no periods to take orders at any time
+//	@Tags			restaurants
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.UpdateRestaurantOpeningHoursRequest	true	"Update Opening Hours Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.RestaurantOpeningHours	"Updated opening hours"
+//	@Failure		400	{string}	string							"Invalid request body"
+//	@Failure		404	{string}	string							"restaurant not found"
+//	@Failure		500	{string}	string							"failed to update opening hours"
+//	@Router			/restaurants/me/opening-hours [put]
+func (h *RestaurantHandler) UpdateOpeningHours(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to update restaurant opening hours.", r.Context().Value(chimiddleware.RequestIDKey))
+	updateOpeningHoursRequest := &models.UpdateRestaurantOpeningHoursRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateOpeningHoursRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(updateOpeningHoursRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	openingHours, err := h.restaurantService.UpdateOpeningHours(restaurantID, utils.MapUpdateRestaurantOpeningHoursRequestToRestaurantOpeningHours(updateOpeningHoursRequest))
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidSchedule) {
+			h.logger.WithError(err).Errorf("Request ID %s: Invalid opening hours", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "opening periods must close after they open")
+			return
+		}
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Restaurant not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "restaurant not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to update opening hours", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to update opening hours")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(openingHours)
+	h.logger.Infof("Request ID %s: Finished processing request to update restaurant opening hours.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// CreateClosure godoc
+//
+//	@Summary		Add a closure
+//	@Description	Add a period during which the restaurant can't create orders whatever its opening hours say, e.g. a holiday
+//	@Tags			restaurants
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.CreateRestaurantClosureRequest	true	"Create Closure Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.RestaurantClosure	"Created closure"
+//	@Failure		400	{string}	string						"Invalid request body"
+//	@Failure		500	{string}	string						"failed to create closure"
+//	@Router			/restaurants/me/closures [post]
+func (h *RestaurantHandler) CreateClosure(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to create restaurant closure.", r.Context().Value(chimiddleware.RequestIDKey))
+	createClosureRequest := &models.CreateRestaurantClosureRequest{}
+	err := json.NewDecoder(r.Body).Decode(createClosureRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(createClosureRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	closure := utils.MapCreateRestaurantClosureRequestToRestaurantClosure(createClosureRequest)
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	closure.RestaurantID = restaurantID
+
+	createdClosure, err := h.restaurantService.CreateClosure(closure)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to create closure", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create closure")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdClosure)
+	h.logger.Infof("Request ID %s: Finished processing request to create restaurant closure.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetClosures godoc
+//
+//	@Summary		Get the closures of the restaurant
+//	@Description	Get the current and upcoming closures of the restaurant
+//	@Tags			restaurants
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.RestaurantClosure	"Closures"
+//	@Failure		500	{string}	string						"failed to get closures"
+//	@Router			/restaurants/me/closures [get]
+func (h *RestaurantHandler) GetClosures(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get restaurant closures.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	closures, err := h.restaurantService.GetClosures(restaurantID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get closures", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get closures")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(closures)
+	h.logger.Infof("Request ID %s: Finished processing request to get restaurant closures.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteClosure godoc
+//
+//	@Summary		Delete a closure
+//	@Description	Delete a closure of the restaurant, e.g. when it reopens early
+//	@Tags			restaurants
+//	@Param			closureID	path	int	true	"Closure ID"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Closure deleted"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"closure not found"
+//	@Failure		500	{string}	string	"failed to delete closure"
+//	@Router			/restaurants/me/closures/{closureID} [delete]
+func (h *RestaurantHandler) DeleteClosure(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete restaurant closure.", r.Context().Value(chimiddleware.RequestIDKey))
+	closureID, err := strconv.Atoi(chi.URLParam(r, "closureID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err = h.restaurantService.DeleteClosure(closureID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Closure not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "closure not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete closure", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete closure")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete restaurant closure.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetDeliveryZone godoc
+//
+//	@Summary		Get the delivery zone of the restaurant
+//	@Description	Get the area the restaurant delivers in. Restaurants without a zone get the drivers whose radius covers them
+//	@Tags			restaurants
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{object}	models.DeliveryZone	"Delivery zone"
+//	@Failure		404	{string}	string				"delivery zone not found"
+//	@Failure		500	{string}	string				"failed to get delivery zone"
+//	@Router			/restaurants/me/delivery-zone [get]
+func (h *RestaurantHandler) GetDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get restaurant delivery zone.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	zone, err := h.restaurantService.GetDeliveryZone(restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Delivery zone not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "delivery zone not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get delivery zone", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get delivery zone")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(zone)
+	h.logger.Infof("Request ID %s: Finished processing request to get restaurant delivery zone.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// SetDeliveryZone godoc
+//
+//	@Summary		Set the delivery zone of the restaurant
+//	@Description	Replace the area the restaurant delivers in, only the drivers inside it receive its orders. The zone is either a polygon of [longitude, latitude] points, which mustn't cross itself, or a radius in meters around the restaurant
+//	@Tags			restaurants
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.SetDeliveryZoneRequest	true	"Set Delivery Zone Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.DeliveryZone	"Delivery zone"
+//	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		400	{string}	string				"invalid polygon"
+//	@Failure		500	{string}	string				"failed to set delivery zone"
+//	@Router			/restaurants/me/delivery-zone [put]
+func (h *RestaurantHandler) SetDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to set restaurant delivery zone.", r.Context().Value(chimiddleware.RequestIDKey))
+	setDeliveryZoneRequest := &models.SetDeliveryZoneRequest{}
+	err := json.NewDecoder(r.Body).Decode(setDeliveryZoneRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(setDeliveryZoneRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	zone := utils.MapSetDeliveryZoneRequestToDeliveryZone(setDeliveryZoneRequest)
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	zone.RestaurantID = restaurantID
+
+	updatedZone, err := h.restaurantService.SetDeliveryZone(zone)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidZone) {
+			h.logger.WithError(err).Errorf("Request ID %s: Invalid polygon", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid polygon, it needs at least 3 different points and its edges can't cross")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to set delivery zone", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to set delivery zone")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedZone)
+	h.logger.Infof("Request ID %s: Finished processing request to set restaurant delivery zone.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteDeliveryZone godoc
+//
+//	@Summary		Delete the delivery zone of the restaurant
+//	@Description	Remove the delivery zone of the restaurant, its orders then go to the drivers whose radius covers it
+//	@Tags			restaurants
+//	@Security		jwt
+//	@Success		204	{string}	string	"Delivery zone deleted"
+//	@Failure		404	{string}	string	"delivery zone not found"
+//	@Failure		500	{string}	string	"failed to delete delivery zone"
+//	@Router			/restaurants/me/delivery-zone [delete]
+func (h *RestaurantHandler) DeleteDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete restaurant delivery zone.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err := h.restaurantService.DeleteDeliveryZone(restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Delivery zone not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "delivery zone not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete delivery zone", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete delivery zone")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete restaurant delivery zone.", r.Context().Value(chimiddleware.RequestIDKey))
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"Tamra/internal/pkg/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestaurantHandler_GetNearbyRestaurantsInvalidLocation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	// The location is validated before the service is called, so it isn't needed
+	handler := NewRestaurantHandler(nil, nil, logger, utils.Config{})
+
+	invalidQueries := []string{
+		"longitude=NaN&latitude=10",
+		"longitude=10&latitude=nan",
+		"longitude=181&latitude=10",
+		"longitude=10&latitude=-91",
+		"longitude=Inf&latitude=10",
+		"longitude=10",
+		"longitude=ten&latitude=10",
+	}
+	for _, query := range invalidQueries {
+		req := httptest.NewRequest(http.MethodGet, "/restaurants/nearby?"+query, nil)
+		rec := httptest.NewRecorder()
+		handler.GetNearbyRestaurants(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
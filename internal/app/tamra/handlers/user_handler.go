@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"Tamra/internal/app/tamra/middleware"
 	"Tamra/internal/app/tamra/services"
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
@@ -8,7 +9,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/sirupsen/logrus"
 )
@@ -29,10 +32,11 @@ type UserHandler struct {
 	userService services.UserService
 	validator   Validator
 	logger      logrus.FieldLogger
+	config      utils.Config
 }
 
-func NewUserHandler(userService services.UserService, validator Validator, logger logrus.FieldLogger) *UserHandler {
-	return &UserHandler{userService: userService, validator: validator, logger: logger}
+func NewUserHandler(userService services.UserService, validator Validator, logger logrus.FieldLogger, config utils.Config) *UserHandler {
+	return &UserHandler{userService: userService, validator: validator, logger: logger, config: config}
 }
 
 // CreateUser godoc
@@ -72,7 +76,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	// It should be loosely coupled and only know about the domain models
 	user := utils.MapCreateUserRequestToUser(createUserRequest)
 
-	firebaseUID, ok := r.Context().Value("UID").(string)
+	firebaseUID, ok := middleware.UIDFromContext(r.Context())
 	if !ok {
 		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -111,7 +115,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 //	@Router			/users/me [get]
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to get user.", r.Context().Value(chimiddleware.RequestIDKey))
-	userID, ok := r.Context().Value("UID").(string)
+	userID, ok := middleware.UIDFromContext(r.Context())
 	if !ok {
 		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -145,7 +149,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 // UpdateUser godoc
 //
 //	@Summary		Update a user
-//	@Description	Update a user
+//	@Description	Update the fields of the user sent in the body, the other ones keep their current value. The longitude and latitude have to be sent together. Changing the vehicle type or the ID photo of an approved driver sends them back to approval
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
@@ -153,6 +157,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 //	@Security		jwt
 //	@Success		200	{object}	models.UserResponse	"Updated User"
 //	@Failure		400	{string}	string				"Invalid request body"
+//	@Failure		404	{string}	string				"user not found"
 //	@Failure		500	{string}	string				"Failed to update user"
 //	@Router			/users/me [patch]
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
@@ -170,13 +175,13 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, "invalid request body")
+		fmt.Fprint(w, utils.FormatValidationError(err))
 		return
 	}
 
-	user := utils.MapUpdateUserRequestToUser(updateUserRequest)
+	patch := utils.MapUpdateUserRequestToUserPatch(updateUserRequest)
 
-	fbUID, ok := r.Context().Value("UID").(string)
+	fbUID, ok := middleware.UIDFromContext(r.Context())
 
 	if !ok {
 		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
@@ -185,11 +190,14 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set the ID of the user to the ID from the URL
-	user.ID = fbUID
-
-	updatedUser, err := h.userService.UpdateUser(user)
+	updatedUser, err := h.userService.UpdateUser(fbUID, patch)
 	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
 		h.logger.WithError(err).Errorf("Request ID %s: Failed to update user", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "failed to update user")
@@ -232,7 +240,7 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 // DeleteUser godoc
 //
 //	@Summary		Delete a user
-//	@Description	Delete a user
+//	@Description	Delete the user and their account, signing them out of all their sessions
 //	@Tags			users
 //	@Security		jwt
 //	@Success		200	{string}	string	"User deleted"
@@ -240,7 +248,7 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 //	@Router			/users/me [delete]
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Request ID %s: Received request to delete user.", r.Context().Value(chimiddleware.RequestIDKey))
-	userID, ok := r.Context().Value("UID").(string)
+	userID, ok := middleware.UIDFromContext(r.Context())
 	if !ok {
 		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -260,3 +268,421 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "user deleted")
 	h.logger.Infof("Request ID %s: Finished processing request to delete user.", r.Context().Value(chimiddleware.RequestIDKey))
 }
+
+// UpdateLocation godoc
+//
+//	@Summary		Update the location of the user
+//	@Description	Lightweight location update for the driver app to call as the driver moves. Updates sent more often than the configured interval, imprecise or old positions are ignored, the response tells whether the location was stored
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.UpdateLocationRequest	true	"Update Location Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.LocationUpdateResponse	"Location update result"
+//	@Failure		400	{string}	string							"Invalid request body"
+//	@Failure		404	{string}	string							"user not found"
+//	@Failure		500	{string}	string							"Failed to update location"
+//	@Router			/users/me/location [put]
+func (h *UserHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to update user location.", r.Context().Value(chimiddleware.RequestIDKey))
+	updateLocationRequest := &models.UpdateLocationRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateLocationRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(updateLocationRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	h.updateLocation(w, r, []*models.LocationPoint{utils.MapUpdateLocationRequestToLocationPoint(updateLocationRequest)})
+	h.logger.Infof("Request ID %s: Finished processing request to update user location.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// UpdateLocationBatch godoc
+//
+//	@Summary		Update the location of the user from buffered points
+//	@Description	Send the positions the driver app buffered while offline. The most recent position that passes the accuracy and age filters is stored, subject to the same throttling as single updates
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.UpdateLocationBatchRequest	true	"Update Location Batch Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.LocationUpdateResponse	"Location update result"
+//	@Failure		400	{string}	string							"Invalid request body"
+//	@Failure		404	{string}	string							"user not found"
+//	@Failure		500	{string}	string							"Failed to update location"
+//	@Router			/users/me/location/batch [post]
+func (h *UserHandler) UpdateLocationBatch(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to update user location in batch.", r.Context().Value(chimiddleware.RequestIDKey))
+	updateLocationBatchRequest := &models.UpdateLocationBatchRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateLocationBatchRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(updateLocationBatchRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	h.updateLocation(w, r, utils.MapUpdateLocationRequestsToLocationPoints(updateLocationBatchRequest.Points))
+	h.logger.Infof("Request ID %s: Finished processing request to update user location in batch.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// updateLocation stores the points of the user and writes the result, it is shared by the single and batch location updates
+func (h *UserHandler) updateLocation(w http.ResponseWriter, r *http.Request, points []*models.LocationPoint) {
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	locationUpdate, err := h.userService.UpdateLocation(userID, points)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to update location", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to update location")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(locationUpdate)
+}
+
+// GetSchedule godoc
+//
+//	@Summary		Get the weekly schedule of the user
+//	@Description	Get the timezone and weekly shifts of the driver. Drivers without shifts can receive orders at any time
+//	@Tags			users
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{object}	models.DriverSchedule	"Schedule"
+//	@Failure		404	{string}	string					"user not found"
+//	@Failure		500	{string}	string					"failed to get schedule"
+//	@Router			/users/me/schedule [get]
+func (h *UserHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get user schedule.", r.Context().Value(chimiddleware.RequestIDKey))
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	schedule, err := h.userService.GetSchedule(userID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get schedule", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get schedule")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+	h.logger.Infof("Request ID %s: Finished processing request to get user schedule.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetIDPhotoUploadURL godoc
+//
+//	@Summary		Get a signed URL to upload the ID photo of the user
+//	@Description	Get a signed URL to upload the ID photo of the driver to the S3 bucket. Drivers are approved once an admin checked it
+//	@Tags			users
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{object}	models.UserIDPhotoUploadResponse	"Presigned URL"
+//	@Failure		500	{string}	string								"Failed to get upload URL"
+//	@Router			/users/me/id-photo/uploadurl [get]
+func (h *UserHandler) GetIDPhotoUploadURL(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get ID photo upload URL.", r.Context().Value(chimiddleware.RequestIDKey))
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	presignedURL, storedFileURL, err := h.userService.GetIDPhotoUploadURL(userID, h.config.DriverDocumentsBucket)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get upload URL", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get upload URL")
+		return
+	}
+
+	presignedURLResponse := &models.UserIDPhotoUploadResponse{
+		PresignedURL:  presignedURL,
+		StoredFileURL: storedFileURL,
+		Description:   "The stored_file_url is the URL you have to send as the id_photo_url of the user",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(presignedURLResponse)
+	h.logger.Infof("Request ID %s: Finished processing request to get ID photo upload URL.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetStats godoc
+//
+//	@Summary		Get the performance stats of the user
+//	@Description	Get how the driver handled the orders offered to them over the last 30 days, and the reliability score used by dispatch
+//	@Tags			users
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{object}	models.DriverStats	"Stats"
+//	@Failure		500	{string}	string				"failed to get stats"
+//	@Router			/users/me/stats [get]
+func (h *UserHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get user stats.", r.Context().Value(chimiddleware.RequestIDKey))
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	stats, err := h.userService.GetStats(userID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get stats", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get stats")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+	h.logger.Infof("Request ID %s: Finished processing request to get user stats.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// UpdateSchedule godoc
+//
+//	@Summary		Replace the weekly schedule of the user
+//	@Description	Replace the timezone and weekly shifts of the driver. Weekdays go from 0 (Sunday) to 6, times are HH:MM in the driver's timezone and a shift ending before its start time, e.g. 22:00 to 06:00, ends the next day. Send no shifts to be available at any time
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.UpdateDriverScheduleRequest	true	"Update Schedule Request"
+//	@Security		jwt
+//	@Success		200	{object}	models.DriverSchedule	"Updated Schedule"
+//	@Failure		400	{string}	string					"Invalid request body"
+//	@Failure		404	{string}	string					"user not found"
+//	@Failure		500	{string}	string					"failed to update schedule"
+//	@Router			/users/me/schedule [put]
+func (h *UserHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to update user schedule.", r.Context().Value(chimiddleware.RequestIDKey))
+	updateScheduleRequest := &models.UpdateDriverScheduleRequest{}
+	err := json.NewDecoder(r.Body).Decode(updateScheduleRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(updateScheduleRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	schedule, err := h.userService.UpdateSchedule(userID, utils.MapUpdateDriverScheduleRequestToDriverSchedule(updateScheduleRequest))
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidSchedule) {
+			h.logger.WithError(err).Errorf("Request ID %s: Invalid schedule", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "shifts must end after they start")
+			return
+		}
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: User not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "user not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to update schedule", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to update schedule")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+	h.logger.Infof("Request ID %s: Finished processing request to update user schedule.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// CreateTimeOff godoc
+//
+//	@Summary		Add time off
+//	@Description	Add a period during which the driver doesn't receive orders, whatever their weekly schedule says
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.CreateDriverTimeOffRequest	true	"Create Time Off Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.DriverTimeOff	"Created Time Off"
+//	@Failure		400	{string}	string					"Invalid request body"
+//	@Failure		500	{string}	string					"failed to create time off"
+//	@Router			/users/me/time-off [post]
+func (h *UserHandler) CreateTimeOff(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to create user time off.", r.Context().Value(chimiddleware.RequestIDKey))
+	createTimeOffRequest := &models.CreateDriverTimeOffRequest{}
+	err := json.NewDecoder(r.Body).Decode(createTimeOffRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(createTimeOffRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	timeOff := utils.MapCreateDriverTimeOffRequestToDriverTimeOff(createTimeOffRequest)
+
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	timeOff.UserID = userID
+
+	createdTimeOff, err := h.userService.CreateTimeOff(timeOff)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to create time off", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create time off")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdTimeOff)
+	h.logger.Infof("Request ID %s: Finished processing request to create user time off.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetTimeOffs godoc
+//
+//	@Summary		Get the time off of the user
+//	@Description	Get the current and upcoming time off periods of the driver
+//	@Tags			users
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.DriverTimeOff	"Time Offs"
+//	@Failure		500	{string}	string					"failed to get time offs"
+//	@Router			/users/me/time-off [get]
+func (h *UserHandler) GetTimeOffs(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get user time offs.", r.Context().Value(chimiddleware.RequestIDKey))
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	timeOffs, err := h.userService.GetTimeOffs(userID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get time offs", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get time offs")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(timeOffs)
+	h.logger.Infof("Request ID %s: Finished processing request to get user time offs.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteTimeOff godoc
+//
+//	@Summary		Delete time off
+//	@Description	Delete a time off period of the driver, e.g. when they come back early
+//	@Tags			users
+//	@Param			timeOffID	path	int	true	"Time Off ID"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Time off deleted"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"time off not found"
+//	@Failure		500	{string}	string	"failed to delete time off"
+//	@Router			/users/me/time-off/{timeOffID} [delete]
+func (h *UserHandler) DeleteTimeOff(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete user time off.", r.Context().Value(chimiddleware.RequestIDKey))
+	timeOffID, err := strconv.Atoi(chi.URLParam(r, "timeOffID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	userID, ok := middleware.UIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get user ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get user ID from request context")
+		return
+	}
+
+	err = h.userService.DeleteTimeOff(timeOffID, userID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Time off not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "time off not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete time off", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete time off")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete user time off.", r.Context().Value(chimiddleware.RequestIDKey))
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"Tamra/internal/app/tamra/middleware"
+	"Tamra/internal/app/tamra/services"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookHandler struct {
+	webhookService services.WebhookService
+	validator      Validator
+	logger         logrus.FieldLogger
+}
+
+func NewWebhookHandler(webhookService services.WebhookService, validator Validator, logger logrus.FieldLogger) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService, validator: validator, logger: logger}
+}
+
+// CreateWebhook godoc
+//
+//	@Summary		Register a webhook endpoint
+//	@Description	Register a URL that receives the selected order events. Deliveries are signed with the returned secret: the X-Tamra-Signature header is "sha256=" followed by the hex encoded HMAC-SHA256 of "<X-Tamra-Timestamp>.<body>"
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	models.CreateWebhookEndpointRequest	true	"Create Webhook Request"
+//	@Security		jwt
+//	@Success		201	{object}	models.CreatedWebhookEndpointResponse	"Created Webhook"
+//	@Failure		400	{string}	string									"Invalid request body"
+//	@Failure		500	{string}	string									"Failed to create webhook"
+//	@Router			/restaurants/me/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to create webhook.", r.Context().Value(chimiddleware.RequestIDKey))
+	createWebhookRequest := &models.CreateWebhookEndpointRequest{}
+	err := json.NewDecoder(r.Body).Decode(createWebhookRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to decode request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	err = h.validator.Struct(createWebhookRequest)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Invalid request body", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid request body")
+		return
+	}
+
+	endpoint := utils.MapCreateWebhookEndpointRequestToWebhookEndpoint(createWebhookRequest)
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	endpoint.RestaurantID = restaurantID
+
+	createdEndpoint, err := h.webhookService.CreateEndpoint(endpoint)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to create webhook", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to create webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(&models.CreatedWebhookEndpointResponse{
+		WebhookEndpointResponse: *utils.MapWebhookEndpointToWebhookEndpointResponse(createdEndpoint),
+		Secret:                  createdEndpoint.Secret,
+	})
+	h.logger.Infof("Request ID %s: Finished processing request to create webhook.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetWebhooks godoc
+//
+//	@Summary		Get the webhook endpoints
+//	@Description	Get the webhook endpoints registered by the restaurant
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		jwt
+//	@Success		200	{array}		models.WebhookEndpointResponse	"Webhooks"
+//	@Failure		500	{string}	string							"Failed to get webhooks"
+//	@Router			/restaurants/me/webhooks [get]
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get webhooks.", r.Context().Value(chimiddleware.RequestIDKey))
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	endpoints, err := h.webhookService.GetEndpoints(restaurantID)
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get webhooks", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get webhooks")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(utils.MapWebhookEndpointsToWebhookEndpointResponses(endpoints))
+	h.logger.Infof("Request ID %s: Finished processing request to get webhooks.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		Delete a webhook endpoint
+//	@Description	Stop sending events to a webhook endpoint. Its deliveries are deleted as well
+//	@Tags			webhooks
+//	@Param			webhookID	path	int	true	"Webhook ID"
+//	@Security		jwt
+//	@Success		204	{string}	string	"Webhook deleted"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"webhook not found"
+//	@Failure		500	{string}	string	"Failed to delete webhook"
+//	@Router			/restaurants/me/webhooks/{webhookID} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to delete webhook.", r.Context().Value(chimiddleware.RequestIDKey))
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err = h.webhookService.DeleteEndpoint(webhookID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Webhook not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "webhook not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to delete webhook", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Infof("Request ID %s: Finished processing request to delete webhook.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetWebhookDeliveries godoc
+//
+//	@Summary		Get the deliveries of a webhook endpoint
+//	@Description	Get the latest deliveries sent to a webhook endpoint with their state
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			webhookID	path	int	true	"Webhook ID"
+//	@Security		jwt
+//	@Success		200	{array}		models.WebhookDelivery	"Deliveries"
+//	@Failure		400	{string}	string					"invalid id"
+//	@Failure		404	{string}	string					"webhook not found"
+//	@Failure		500	{string}	string					"Failed to get deliveries"
+//	@Router			/restaurants/me/webhooks/{webhookID}/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get webhook deliveries.", r.Context().Value(chimiddleware.RequestIDKey))
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(webhookID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Webhook not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "webhook not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get deliveries", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get deliveries")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+	h.logger.Infof("Request ID %s: Finished processing request to get webhook deliveries.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// GetWebhookDelivery godoc
+//
+//	@Summary		Get a webhook delivery
+//	@Description	Get a delivery with the log of every attempt to send it
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			webhookID	path	int	true	"Webhook ID"
+//	@Param			deliveryID	path	int	true	"Delivery ID"
+//	@Security		jwt
+//	@Success		200	{object}	models.WebhookDelivery	"Delivery"
+//	@Failure		400	{string}	string					"invalid id"
+//	@Failure		404	{string}	string					"delivery not found"
+//	@Failure		500	{string}	string					"Failed to get delivery"
+//	@Router			/restaurants/me/webhooks/{webhookID}/deliveries/{deliveryID} [get]
+func (h *WebhookHandler) GetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to get webhook delivery.", r.Context().Value(chimiddleware.RequestIDKey))
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(deliveryID, webhookID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Delivery not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "delivery not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to get delivery", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get delivery")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(delivery)
+	h.logger.Infof("Request ID %s: Finished processing request to get webhook delivery.", r.Context().Value(chimiddleware.RequestIDKey))
+}
+
+// ReplayWebhookDelivery godoc
+//
+//	@Summary		Replay a webhook delivery
+//	@Description	Send a delivery again with the same payload, e.g. after fixing the endpoint. The attempt is logged with the previous ones
+//	@Tags			webhooks
+//	@Param			webhookID	path	int	true	"Webhook ID"
+//	@Param			deliveryID	path	int	true	"Delivery ID"
+//	@Security		jwt
+//	@Success		202	{string}	string	"Delivery replayed"
+//	@Failure		400	{string}	string	"invalid id"
+//	@Failure		404	{string}	string	"delivery not found"
+//	@Failure		500	{string}	string	"Failed to replay delivery"
+//	@Router			/restaurants/me/webhooks/{webhookID}/deliveries/{deliveryID}/replay [post]
+func (h *WebhookHandler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	h.logger.Infof("Request ID %s: Received request to replay webhook delivery.", r.Context().Value(chimiddleware.RequestIDKey))
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to parse id", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "invalid id")
+		return
+	}
+
+	restaurantID, ok := middleware.RestaurantIDFromContext(r.Context())
+	if !ok {
+		h.logger.Errorf("Request ID %s: Failed to get restaurant ID from request context", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to get restaurant ID from request context")
+		return
+	}
+
+	err = h.webhookService.ReplayDelivery(deliveryID, webhookID, restaurantID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			h.logger.WithError(err).Errorf("Request ID %s: Delivery not found", r.Context().Value(chimiddleware.RequestIDKey))
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "delivery not found")
+			return
+		}
+		h.logger.WithError(err).Errorf("Request ID %s: Failed to replay delivery", r.Context().Value(chimiddleware.RequestIDKey))
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "failed to replay delivery")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	h.logger.Infof("Request ID %s: Finished processing request to replay webhook delivery.", r.Context().Value(chimiddleware.RequestIDKey))
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"Tamra/internal/pkg/models"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminActionRecorder records the changes the admins make
+type AdminActionRecorder interface {
+	RecordAdminAction(log *models.AdminAuditLog) error
+}
+
+// maxAuditedBodySize is the size above which the request bodies aren't kept in the audit logs, the admin requests are small
+const maxAuditedBodySize = 64 << 10
+
+// AuditAdminActions records the requests that can change something, i.e. all of them but GET, with the admin who sent them and their status code.
+// It has to come after RequireRole. Failing to record an action doesn't fail the request, the change was already made
+func AuditAdminActions(recorder AdminActionRecorder, logger logrus.FieldLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			// The start of the body is kept for the log, the handler still reads all of it
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditedBodySize+1))
+			if err != nil {
+				logger.WithError(err).Errorf("Failed to read the request body of admin %s.", principal.UID)
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			log := &models.AdminAuditLog{
+				AdminUID:   principal.UID,
+				Action:     r.Method + " " + chi.RouteContext(r.Context()).RoutePattern(),
+				Path:       r.URL.Path,
+				RequestID:  chimiddleware.GetReqID(r.Context()),
+				StatusCode: ww.Status(),
+			}
+			// Handlers that don't write a header respond with a 200
+			if log.StatusCode == 0 {
+				log.StatusCode = http.StatusOK
+			}
+			if len(body) <= maxAuditedBodySize && json.Valid(body) {
+				log.RequestBody = body
+			}
+
+			err = recorder.RecordAdminAction(log)
+			if err != nil {
+				logger.WithError(err).Errorf("Failed to record the action %s of admin %s.", log.Action, principal.UID)
+			}
+		})
+	}
+}
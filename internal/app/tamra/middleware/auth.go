@@ -1,16 +1,18 @@
 package middleware
 
 import (
-	"context"
+	"Tamra/internal/pkg/models"
 	"net/http"
+	"slices"
 
-	"firebase.google.com/go/auth"
 	"github.com/sirupsen/logrus"
 )
 
-// We pass the firebaseAuth as a parameter to the middleware so we can use it to verify the token and return a handler function that
+// RequireRole verifies the token of the request and only lets through the accounts whose role custom claim is one of the given roles.
+// Without roles any signed in account is let through, e.g. so new accounts can pick their role.
+// We pass the tokenVerifier as a parameter to the middleware so we can use it to verify the token and return a handler function that
 // Take a http.Handler as a parameter and returns a http.Handler so we can continue the chain of handlers after the middleware.
-func UserAuthMiddleware(firebaseAuth *auth.Client, logger logrus.FieldLogger) func(http.Handler) http.Handler {
+func RequireRole(tokenVerifier TokenVerifier, logger logrus.FieldLogger, roles ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get the token from the request header
@@ -21,60 +23,34 @@ func UserAuthMiddleware(firebaseAuth *auth.Client, logger logrus.FieldLogger) fu
 				return
 			}
 
-			// Verify the token.
-			// TODO: what is the use of the context.Background()?
-			tokenWithClaims, err := firebaseAuth.VerifyIDToken(r.Context(), token)
+			verifiedToken, err := tokenVerifier.VerifyToken(r.Context(), token)
 			if err != nil {
+				logger.WithError(err).Warn("Failed to verify token.")
 				http.Error(w, "unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			// // Print all token details
-			// // fmt.Printf("Token: %v\n", tokenWithClaims)
-			logger.Infof("Passed auth token: %+v.", tokenWithClaims)
-			ctx := context.WithValue(r.Context(), "UID", tokenWithClaims.UID)
-
-			// If the token is valid, we can continue the chain of handlers and pass the context
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
-	}
-}
-
-func RestaurantAuthMiddleware(firebaseAuth *auth.Client, logger logrus.FieldLogger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get the token from the request header
-			token := r.Header.Get("Authorization")
-			if token == "" {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				logger.Warn("No auth token provided.")
-				return
-			}
-
-			// Verify the token.
-			// TODO: what is the use of the context.Background()?
-			// TODO: should this context from the request or should we create it from the context.Background()?
-			tokenWithClaims, err := firebaseAuth.VerifyIDToken(r.Context(), token)
-			if err != nil {
-				logger.Error("Failed to verify token: %v", err)
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			// The token is valid but the account doesn't have one of the roles of the route
+			role, _ := verifiedToken.Claims[models.RoleClaim].(string)
+			if len(roles) > 0 && !slices.Contains(roles, role) {
+				logger.Warnf("User %s with role %q tried to access a route for %v.", verifiedToken.UID, role, roles)
+				http.Error(w, "forbidden", http.StatusForbidden)
 				return
 			}
 
-			// Check if the claims contain an email
-			// If the claims contain an email, we can assume that the user is a restaurant
-			if tokenWithClaims.Claims["email"] == nil {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			logger.Info("Passed auth token: %+v.", tokenWithClaims)
-			// print the email
-			logger.Info("Email: %v", tokenWithClaims.Claims["email"])
-
-			ctx := context.WithValue(r.Context(), "UID", tokenWithClaims.UID)
-
-			// If the token is valid, we can continue the chain of handlers and pass the context
+			email, _ := verifiedToken.Claims["email"].(string)
+			emailVerified, _ := verifiedToken.Claims["email_verified"].(bool)
+			phone, _ := verifiedToken.Claims["phone_number"].(string)
+			ctx := WithPrincipal(r.Context(), &Principal{
+				UID:           verifiedToken.UID,
+				Role:          role,
+				Email:         email,
+				EmailVerified: emailVerified,
+				Phone:         phone,
+				Claims:        verifiedToken.Claims,
+			})
+
+			// If the token is valid, we can continue the chain of handlers and pass the principal in the context
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
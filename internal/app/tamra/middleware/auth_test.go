@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"Tamra/internal/pkg/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveWithRole sends a request with the token through RequireRole and returns the response and the principal the next handler received
+func serveWithRole(verifier TokenVerifier, token string, roles ...string) (*httptest.ResponseRecorder, *Principal) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	var principal *Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	rec := httptest.NewRecorder()
+	RequireRole(verifier, logger, roles...)(next).ServeHTTP(rec, req)
+	return rec, principal
+}
+
+func TestRequireRole(t *testing.T) {
+	issuer := NewLocalTokenIssuer("test-secret", time.Hour)
+	driverToken, _, err := issuer.IssueToken("driver-1", map[string]interface{}{models.RoleClaim: models.RoleDriver, "phone_number": "+212600000000"})
+	assert.NoError(t, err)
+	newAccountToken, _, err := issuer.IssueToken("new-1", map[string]interface{}{"email": "new@example.com"})
+	assert.NoError(t, err)
+
+	t.Run("lets the role through with its principal", func(t *testing.T) {
+		rec, principal := serveWithRole(issuer, driverToken, models.RoleDriver, models.RoleRestaurant)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "driver-1", principal.UID)
+		assert.Equal(t, models.RoleDriver, principal.Role)
+		assert.Equal(t, "+212600000000", principal.Phone)
+	})
+
+	t.Run("forbids the other roles", func(t *testing.T) {
+		rec, principal := serveWithRole(issuer, driverToken, models.RoleAdmin)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("lets any account through without roles", func(t *testing.T) {
+		rec, principal := serveWithRole(issuer, newAccountToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "", principal.Role)
+		assert.Equal(t, "new@example.com", principal.Email)
+
+		rec, _ = serveWithRole(issuer, newAccountToken, models.RoleDriver)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("rejects a missing token", func(t *testing.T) {
+		rec, _ := serveWithRole(issuer, "", models.RoleDriver)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("rejects a token signed with another secret", func(t *testing.T) {
+		otherToken, _, err := NewLocalTokenIssuer("other-secret", time.Hour).IssueToken("driver-1", map[string]interface{}{models.RoleClaim: models.RoleAdmin})
+		assert.NoError(t, err)
+		rec, _ := serveWithRole(issuer, otherToken, models.RoleAdmin)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("rejects an expired token", func(t *testing.T) {
+		expiredToken, _, err := NewLocalTokenIssuer("test-secret", -time.Minute).IssueToken("driver-1", map[string]interface{}{models.RoleClaim: models.RoleDriver})
+		assert.NoError(t, err)
+		rec, _ := serveWithRole(issuer, expiredToken, models.RoleDriver)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
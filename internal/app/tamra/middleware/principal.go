@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+)
+
+// Principal is the account that sent a request, as described by its verified token
+type Principal struct {
+	UID            string
+	Role           string                 // One of the models.Role* constants, empty until the account picks one
+	Email          string                 // Empty if the account didn't sign in with an email
+	EmailVerified  bool                   // Whether the account proved it owns the email
+	Phone          string                 // Empty if the account didn't sign in with a phone number
+	Claims         map[string]interface{} // All the claims of the token, including the custom ones
+	RestaurantID   string                 // Restaurant the account acts for, only set by ResolveRestaurant
+	RestaurantRole string                 // Role of the account in the restaurant, one of the models.RestaurantMemberRole* constants
+}
+
+// principalKey is unexported so only this package can set the principal of a request
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the context holding the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal set by RequireRole, false if the request didn't go through it
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// UIDFromContext returns the UID of the principal set by RequireRole, false if the request didn't go through it
+func UIDFromContext(ctx context.Context) (string, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return principal.UID, true
+}
+
+// RestaurantIDFromContext returns the restaurant the principal acts for, false if ResolveRestaurant didn't resolve one
+func RestaurantIDFromContext(ctx context.Context) (string, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.RestaurantID == "" {
+		return "", false
+	}
+	return principal.RestaurantID, true
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RestaurantMemberResolver finds the restaurant an account works for
+type RestaurantMemberResolver interface {
+	// GetMembership fails with utils.ErrNotFound if the account doesn't work for a restaurant
+	GetMembership(uid string) (*models.RestaurantMember, error)
+}
+
+// ResolveRestaurant sets the restaurant the principal acts for from its membership, so the staff of a restaurant act for it
+// with their own account. It has to come after RequireRole. Only the accounts with the given restaurant roles are let through,
+// any member if there are none. The accounts that aren't restaurants, e.g. drivers, are let through without a restaurant
+func ResolveRestaurant(resolver RestaurantMemberResolver, logger logrus.FieldLogger, memberRoles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if principal.Role != models.RoleRestaurant && principal.Role != models.RoleRestaurantStaff {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			member, err := resolver.GetMembership(principal.UID)
+			if err != nil {
+				if errors.Is(err, utils.ErrNotFound) {
+					logger.Warnf("User %s doesn't work for a restaurant.", principal.UID)
+					http.Error(w, "not a member of a restaurant", http.StatusForbidden)
+					return
+				}
+				logger.WithError(err).Errorf("Failed to get the restaurant of user %s.", principal.UID)
+				http.Error(w, "failed to get restaurant membership", http.StatusInternalServerError)
+				return
+			}
+
+			if len(memberRoles) > 0 && !slices.Contains(memberRoles, member.Role) {
+				logger.Warnf("User %s with restaurant role %s tried to access a route for %v.", principal.UID, member.Role, memberRoles)
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			// The principal is copied so the one of the parent context isn't changed
+			resolvedPrincipal := *principal
+			resolvedPrincipal.RestaurantID = member.RestaurantID
+			resolvedPrincipal.RestaurantRole = member.Role
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), &resolvedPrincipal)))
+		})
+	}
+}
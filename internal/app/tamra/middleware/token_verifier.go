@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"firebase.google.com/go/auth"
+)
+
+// VerifiedToken is what the auth middleware needs from a token once its signature and expiry are checked
+type VerifiedToken struct {
+	UID    string
+	Claims map[string]interface{} // All the claims of the token, the custom ones are at the top level like in Firebase tokens
+}
+
+// TokenVerifier verifies the tokens sent in the Authorization header. It is an interface so the routes can be used
+// with a local issuer during development and in the tests, without a Firebase project
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*VerifiedToken, error)
+}
+
+// FirebaseTokenVerifier verifies Firebase ID tokens.
+// Checking whether a token was revoked calls Firebase, so the tokens that passed the check are trusted for the revocation cache TTL.
+// A revoked token can therefore still be used for up to that long
+type FirebaseTokenVerifier struct {
+	authClient         *auth.Client
+	checkRevoked       bool
+	revocationCacheTTL time.Duration
+	mu                 sync.Mutex
+	cache              map[[sha256.Size]byte]*cachedToken // Keyed by the hash of the tokens so they aren't kept in memory
+}
+
+type cachedToken struct {
+	token     *VerifiedToken
+	expiresAt time.Time
+}
+
+func NewFirebaseTokenVerifier(authClient *auth.Client, checkRevoked bool, revocationCacheTTL time.Duration) TokenVerifier {
+	return &FirebaseTokenVerifier{authClient: authClient, checkRevoked: checkRevoked, revocationCacheTTL: revocationCacheTTL, cache: map[[sha256.Size]byte]*cachedToken{}}
+}
+
+func (v *FirebaseTokenVerifier) VerifyToken(ctx context.Context, token string) (*VerifiedToken, error) {
+	if !v.checkRevoked {
+		tokenWithClaims, err := v.authClient.VerifyIDToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		return &VerifiedToken{UID: tokenWithClaims.UID, Claims: tokenWithClaims.Claims}, nil
+	}
+
+	key := sha256.Sum256([]byte(token))
+	if verifiedToken, ok := v.getCached(key); ok {
+		return verifiedToken, nil
+	}
+
+	tokenWithClaims, err := v.authClient.VerifyIDTokenAndCheckRevoked(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedToken := &VerifiedToken{UID: tokenWithClaims.UID, Claims: tokenWithClaims.Claims}
+	// The token is never trusted past its own expiry
+	expiresAt := time.Now().Add(v.revocationCacheTTL)
+	if tokenExpiresAt := time.Unix(tokenWithClaims.Expires, 0); tokenExpiresAt.Before(expiresAt) {
+		expiresAt = tokenExpiresAt
+	}
+	v.setCached(key, &cachedToken{token: verifiedToken, expiresAt: expiresAt})
+	return verifiedToken, nil
+}
+
+func (v *FirebaseTokenVerifier) getCached(key [sha256.Size]byte) (*VerifiedToken, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	cached, ok := v.cache[key]
+	if !ok || time.Now().After(cached.expiresAt) {
+		return nil, false
+	}
+	return cached.token, true
+}
+
+// setCached also drops the expired tokens, so the cache only holds the tokens used within the TTL
+func (v *FirebaseTokenVerifier) setCached(key [sha256.Size]byte, cached *cachedToken) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now()
+	for cachedKey, entry := range v.cache {
+		if now.After(entry.expiresAt) {
+			delete(v.cache, cachedKey)
+		}
+	}
+	v.cache[key] = cached
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"database/sql"
+)
+
+type AuditRepository interface {
+	// CreateAdminAuditLog records a change made by an admin
+	CreateAdminAuditLog(log *models.AdminAuditLog) (*models.AdminAuditLog, error)
+	// SearchAdminAuditLogs returns a page of the audit logs matching the filter, the newest first, and how many match it
+	SearchAdminAuditLogs(filter *models.AdminAuditLogFilter) ([]*models.AdminAuditLog, int, error)
+}
+
+// adminAuditLogColumns are the columns selected for every audit log, in the order scanAdminAuditLog expects them
+const adminAuditLogColumns = "id, admin_uid, action, path, request_id, request_body, status_code, created_at"
+
+type AuditRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &AuditRepositoryImpl{db: db}
+}
+
+func (r *AuditRepositoryImpl) CreateAdminAuditLog(log *models.AdminAuditLog) (*models.AdminAuditLog, error) {
+	// The body is sent as a string, pq would encode a []byte as bytea. Requests without a body are stored as NULL
+	requestBody := sql.NullString{String: string(log.RequestBody), Valid: len(log.RequestBody) > 0}
+
+	const query = "INSERT INTO admin_audit_logs (admin_uid, action, path, request_id, request_body, status_code, created_at) VALUES ($1, $2, $3, $4, $5, $6, CLOCK_TIMESTAMP()) RETURNING " + adminAuditLogColumns
+	err := scanAdminAuditLog(r.db.QueryRow(query, log.AdminUID, log.Action, log.Path, log.RequestID, requestBody, log.StatusCode), log)
+	return log, err
+}
+
+func (r *AuditRepositoryImpl) SearchAdminAuditLogs(filter *models.AdminAuditLogFilter) ([]*models.AdminAuditLog, int, error) {
+	conditions := &filterBuilder{}
+	if filter.AdminUID != "" {
+		conditions.where("admin_uid = ?", filter.AdminUID)
+	}
+
+	var total int
+	countQuery, countArgs := conditions.count("admin_audit_logs")
+	err := r.db.QueryRow(countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query, args := conditions.selectPage("admin_audit_logs", adminAuditLogColumns, "created_at DESC, id DESC", filter.Pagination)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []*models.AdminAuditLog{}
+	for rows.Next() {
+		log := &models.AdminAuditLog{}
+		err := scanAdminAuditLog(rows, log)
+		if err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, total, rows.Err()
+}
+
+func scanAdminAuditLog(row rowScanner, log *models.AdminAuditLog) error {
+	var requestBody []byte
+	err := row.Scan(&log.ID, &log.AdminUID, &log.Action, &log.Path, &log.RequestID, &requestBody, &log.StatusCode, &log.CreatedAt)
+	if err != nil {
+		return err
+	}
+	log.RequestBody = requestBody
+	return nil
+}
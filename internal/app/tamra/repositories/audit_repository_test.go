@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository_AdminAuditLogs(t *testing.T) {
+	auditRepo := NewAuditRepository(Db)
+
+	log, err := auditRepo.CreateAdminAuditLog(&models.AdminAuditLog{
+		AdminUID:    "auditadmin",
+		Action:      "PUT /admin/users/{userID}/role",
+		Path:        "/admin/users/user1/role",
+		RequestID:   "request1",
+		RequestBody: json.RawMessage(`{"role": "driver"}`),
+		StatusCode:  200,
+	})
+	assert.NoError(t, err)
+	assert.NotZero(t, log.ID)
+	assert.JSONEq(t, `{"role": "driver"}`, string(log.RequestBody))
+
+	// Requests without a body are logged too
+	_, err = auditRepo.CreateAdminAuditLog(&models.AdminAuditLog{
+		AdminUID:   "auditadmin",
+		Action:     "POST /admin/orders/{orderID}/cancel",
+		Path:       "/admin/orders/1/cancel",
+		RequestID:  "request2",
+		StatusCode: 404,
+	})
+	assert.NoError(t, err)
+
+	logs, total, err := auditRepo.SearchAdminAuditLogs(&models.AdminAuditLogFilter{AdminUID: "auditadmin", Pagination: models.Pagination{Limit: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, "request2", logs[0].RequestID)
+	assert.Empty(t, logs[0].RequestBody)
+
+	logs, total, err = auditRepo.SearchAdminAuditLogs(&models.AdminAuditLogFilter{AdminUID: "someoneelse", Pagination: models.Pagination{Limit: 10}})
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, logs)
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"fmt"
+	"strings"
+)
+
+// filterBuilder builds the WHERE clause of a SELECT statement from the fields of a filter that are set,
+// numbering the placeholders of the arguments as it goes
+type filterBuilder struct {
+	conditions []string
+	args       []any
+}
+
+// where adds a condition the rows have to match. Every ? in the condition is replaced by the placeholder of the next value
+func (b *filterBuilder) where(condition string, values ...any) {
+	for _, value := range values {
+		b.args = append(b.args, value)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(b.args)), 1)
+	}
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *filterBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// selectPage returns the SELECT statement of a page of the matching rows and its arguments
+func (b *filterBuilder) selectPage(table string, columns string, orderBy string, pagination models.Pagination) (string, []any) {
+	args := append(b.args[:len(b.args):len(b.args)], pagination.Limit, pagination.Offset)
+	query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT $%d OFFSET $%d", columns, table, b.whereClause(), orderBy, len(args)-1, len(args))
+	return query, args
+}
+
+// count returns the statement counting the matching rows and its arguments
+func (b *filterBuilder) count(table string) (string, []any) {
+	return "SELECT COUNT(*) FROM " + table + b.whereClause(), b.args
+}
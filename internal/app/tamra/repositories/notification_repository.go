@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type NotificationRepository interface {
+	// CreateNotificationFallback stores a fallback notification until it is due
+	CreateNotificationFallback(fallback *models.NotificationFallback) (*models.NotificationFallback, error)
+	// ClaimDueNotificationFallbacks deletes the fallbacks due at the given time and returns the ones that still have to be sent,
+	// i.e. the ones whose order is still pending and never reached the driver's device
+	ClaimDueNotificationFallbacks(now time.Time, limit int) ([]*models.NotificationFallback, error)
+}
+
+// notificationFallbackColumns are the columns selected for every fallback, in the order scanNotificationFallback expects them
+const notificationFallbackColumns = "id, order_id, channel, event, recipient_id, COALESCE(phone, ''), fcm_tokens, title, body, send_at, created_at"
+
+type NotificationRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &NotificationRepositoryImpl{db: db}
+}
+
+func (r *NotificationRepositoryImpl) CreateNotificationFallback(fallback *models.NotificationFallback) (*models.NotificationFallback, error) {
+	const query = "INSERT INTO notification_fallbacks (order_id, channel, event, recipient_id, phone, fcm_tokens, title, body, send_at, created_at) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, CLOCK_TIMESTAMP()) RETURNING " + notificationFallbackColumns
+	err := scanNotificationFallback(r.db.QueryRow(query, fallback.OrderID, fallback.Channel, fallback.Event, fallback.RecipientID, fallback.Phone, pq.Array(fallback.FCMTokens), fallback.Title, fallback.Body, fallback.SendAt), fallback)
+	return fallback, err
+}
+
+func (r *NotificationRepositoryImpl) ClaimDueNotificationFallbacks(now time.Time, limit int) ([]*models.NotificationFallback, error) {
+	// The fallbacks are deleted when claimed so they are sent at most once, even with several workers.
+	// SKIP LOCKED lets the workers claim fallbacks at the same time without waiting for each other
+	const query = `
+	WITH due AS (
+		DELETE FROM notification_fallbacks
+		WHERE id IN (
+			SELECT id FROM notification_fallbacks
+			WHERE send_at <= $1
+			ORDER BY send_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationFallbackColumns + `
+	)
+	SELECT due.* FROM due
+	JOIN orders ON orders.id = due.order_id
+	WHERE orders.state = 'PENDING' AND orders.delivered_at IS NULL`
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fallbacks := []*models.NotificationFallback{}
+	for rows.Next() {
+		fallback := &models.NotificationFallback{}
+		err := scanNotificationFallback(rows, fallback)
+		if err != nil {
+			return nil, err
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+	return fallbacks, rows.Err()
+}
+
+func scanNotificationFallback(row rowScanner, fallback *models.NotificationFallback) error {
+	return row.Scan(&fallback.ID, &fallback.OrderID, &fallback.Channel, &fallback.Event, &fallback.RecipientID, &fallback.Phone, pq.Array(&fallback.FCMTokens), &fallback.Title, &fallback.Body, &fallback.SendAt, &fallback.CreatedAt)
+}
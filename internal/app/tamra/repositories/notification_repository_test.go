@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRepository_ClaimDueNotificationFallbacks(t *testing.T) {
+	orderRepo := NewOrderRepository(Db)
+	notificationRepo := NewNotificationRepository(Db)
+
+	pendingOrder, err := orderRepo.CreateOrder(&models.Order{UserID: "user1", RestaurantID: "restaurant1", Code: "712531", Description: "Test Order"})
+	assert.NoError(t, err)
+	acceptedOrder, err := orderRepo.CreateOrder(&models.Order{UserID: "user1", RestaurantID: "restaurant1", Code: "712532", Description: "Test Order"})
+	assert.NoError(t, err)
+	err = orderRepo.UpdateUserOrderState(acceptedOrder.ID, "user1", "ACCEPTED")
+	assert.NoError(t, err)
+
+	now := time.Now().UTC()
+	for _, orderID := range []int{pendingOrder.ID, acceptedOrder.ID} {
+		fallback, err := notificationRepo.CreateNotificationFallback(&models.NotificationFallback{
+			OrderID:     orderID,
+			Channel:     "sms",
+			Event:       "NEW_ORDER",
+			RecipientID: "user1",
+			Phone:       "+201000000000",
+			FCMTokens:   []string{"token1"},
+			Title:       "title",
+			Body:        "body",
+			SendAt:      now.Add(2 * time.Minute),
+		})
+		assert.NoError(t, err)
+		assert.NotZero(t, fallback.ID)
+	}
+
+	// Nothing is due yet
+	fallbacks, err := notificationRepo.ClaimDueNotificationFallbacks(now, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, fallbacks)
+
+	// Only the fallback of the order the driver didn't respond to is returned
+	fallbacks, err = notificationRepo.ClaimDueNotificationFallbacks(now.Add(2*time.Minute), 100)
+	assert.NoError(t, err)
+	assert.Len(t, fallbacks, 1)
+	assert.Equal(t, pendingOrder.ID, fallbacks[0].OrderID)
+	assert.Equal(t, "+201000000000", fallbacks[0].Phone)
+	assert.Equal(t, []string{"token1"}, fallbacks[0].FCMTokens)
+
+	// Both were claimed
+	fallbacks, err = notificationRepo.ClaimDueNotificationFallbacks(now.Add(2*time.Minute), 100)
+	assert.NoError(t, err)
+	assert.Empty(t, fallbacks)
+}
@@ -5,27 +5,64 @@ import (
 	"Tamra/internal/pkg/utils"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type OrderRepository interface {
 	// CreateOrder creates a new order
 	CreateOrder(order *models.Order) (*models.Order, error)
-	// GetOrder returns an order by its ID
+	// GetOrder returns an order of a restaurant, or of one of the branches of the organization the restaurant owns
 	GetOrder(id int, fbUID string) (*models.Order, error)
+	// GetOrderByID returns an order by its ID regardless of who it belongs to
+	GetOrderByID(id int) (*models.Order, error)
 	// GetUserOrders returns a list of orders
 	GetUserOrders(userID string) ([]*models.Order, error)
 	// GetRestaurantOrders returns a list of orders
 	GetRestaurantOrders(restaurantID string) ([]*models.Order, error)
+	// GetOrganizationOrders returns the orders of all the branches of an organization
+	GetOrganizationOrders(organizationID int) ([]*models.Order, error)
+	// SearchOrders returns a page of the orders matching the filter, the newest first, and how many match it
+	SearchOrders(filter *models.OrderFilter) ([]*models.Order, int, error)
+	// GetPendingOrdersCreatedBefore returns the orders still waiting for a driver's response that were created before the given time
+	GetPendingOrdersCreatedBefore(createdBefore time.Time) ([]*models.Order, error)
 	// UpdateOrder updates an order
 	UpdateOrder(order *models.Order) (*models.Order, error)
-	// UpdateUserOrderState updates the state of an order that belongs to a user
+	// UpdateUserOrderState updates the state of a pending order that belongs to a user.
+	// It fails with utils.ErrNotFound if the order belongs to another user or isn't pending anymore
 	UpdateUserOrderState(id int, fbUID string, state string) error
-	// UpdateRestaurantOrderState updates the state of an order that belongs to a restaurant
-	UpdateRestaurantOrderState(id int, fbUID string, state string) error
+	// UpdateRestaurantOrderState updates the state of an order that belongs to a restaurant, or to one of the branches of the organization the restaurant owns, and returns it.
+	// It fails with utils.ErrNotFound if the order isn't the restaurant's and with utils.ErrInvalidOrderState if the order can't move to the state from the one it is in
+	UpdateRestaurantOrderState(id int, fbUID string, state string) (*models.Order, error)
+	// ExpirePendingOrder marks an order that is still pending as expired and returns it.
+	// It fails with utils.ErrNotFound if the order isn't pending anymore, e.g. the driver accepted it or it already expired
+	ExpirePendingOrder(id int) (*models.Order, error)
 	// DeleteOrder deletes an order
 	DeleteOrder(id int) error
+	// GetManagedRestaurantIDs returns the IDs of the restaurants whose orders the restaurant manages, itself included
+	GetManagedRestaurantIDs(restaurantID string) ([]string, error)
 	// Check if the restaurant is the owner of the order
 	IsRestaurantOwnerOfOrder(id int, fbUID string) (bool, error)
+	// MarkOrderDelivered records that the notification of an order reached the driver's device
+	MarkOrderDelivered(id int, fbUID string) error
+	// MarkOrderSeen records that the driver opened the order. An order that was seen was also delivered
+	MarkOrderSeen(id int, fbUID string) error
+	// AddOrderLocationPoints adds the points to the location trail of the orders the user is carrying, i.e. their accepted orders
+	AddOrderLocationPoints(userID string, points []*models.LocationPoint) error
+	// GetOrderLocationPoints returns the location trail of an order recorded within the retention period, oldest first
+	GetOrderLocationPoints(orderID int, retention time.Duration) ([]*models.LocationPoint, error)
+	// DeleteOrderLocationPointsOlderThan deletes the location points recorded before the retention period and returns how many were deleted
+	DeleteOrderLocationPointsOlderThan(retention time.Duration) (int64, error)
+}
+
+// orderColumns are the columns selected for every order, in the order scanOrder expects them
+const orderColumns = "id, user_id, restaurant_id, code, state, COALESCE(required_vehicle, ''), description, delivered_at, seen_at, accepted_at, created_at, updated_at"
+
+// managedRestaurantIDs selects the IDs of the restaurants whose orders the restaurant with the ID in the parameter manages:
+// itself and, if it owns an organization, the branches of the organization
+func managedRestaurantIDs(param string) string {
+	return "SELECT " + param + "::VARCHAR UNION SELECT r.id FROM restaurants r JOIN organizations o ON r.organization_id = o.id WHERE o.owner_restaurant_id = " + param
 }
 
 type OrderRepositoryImpl struct {
@@ -38,82 +75,193 @@ func NewOrderRepository(db *sql.DB) OrderRepository {
 
 func (r *OrderRepositoryImpl) CreateOrder(order *models.Order) (*models.Order, error) {
 	// The state is set to "PENDING" by default. That's why it's not included in the query
-	const query = "INSERT INTO orders (user_id, restaurant_id, code, description, created_at, updated_at) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING id, user_id, restaurant_id, code, state, description, created_at, updated_at"
-	err := r.db.QueryRow(query, order.UserID, order.RestaurantID, order.Code, order.Description).Scan(&order.ID, &order.UserID, &order.RestaurantID, &order.Code, &order.State, &order.Description, &order.CreatedAt, &order.UpdatedAt)
+	const query = "INSERT INTO orders (user_id, restaurant_id, code, description, required_vehicle, created_at, updated_at) VALUES ($1, $2, $3, $4, NULLIF($5, ''), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING " + orderColumns
+	err := scanOrder(r.db.QueryRow(query, order.UserID, order.RestaurantID, order.Code, order.Description, order.RequiredVehicle), order)
 	return order, err
 }
 
 func (r *OrderRepositoryImpl) GetOrder(id int, fbUID string) (*models.Order, error) {
 	order := &models.Order{}
-	var userID sql.NullString // We use sql.NullString to handle the case where the user_id is null
-
-	err := r.db.QueryRow("SELECT id, user_id, restaurant_id, code, state, description, created_at, updated_at FROM orders WHERE id = $1 AND restaurant_id = $2", id, fbUID).Scan(&order.ID, &userID, &order.RestaurantID, &order.Code, &order.State, &order.Description, &order.CreatedAt, &order.UpdatedAt)
+	err := scanOrder(r.db.QueryRow("SELECT "+orderColumns+" FROM orders WHERE id = $1 AND restaurant_id IN ("+managedRestaurantIDs("$2")+")", id, fbUID), order)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// If the user_id is not null, we set it in the order, otherwise we leave it as an empty string
-	if userID.Valid {
-		order.UserID = userID.String
+	return order, err
+}
+
+func (r *OrderRepositoryImpl) GetOrderByID(id int) (*models.Order, error) {
+	order := &models.Order{}
+	err := scanOrder(r.db.QueryRow("SELECT "+orderColumns+" FROM orders WHERE id = $1", id), order)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return order, err
+	return order, nil
 }
 
 func (r *OrderRepositoryImpl) GetUserOrders(userID string) ([]*models.Order, error) {
-	rows, err := r.db.Query("SELECT id, user_id, restaurant_id, code, state, description, created_at, updated_at FROM orders WHERE user_id = $1 AND (state = 'PENDING' OR state = 'ACCEPTED')", userID)
+	rows, err := r.db.Query("SELECT "+orderColumns+" FROM orders WHERE user_id = $1 AND (state = 'PENDING' OR state = 'ACCEPTED')", userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	orders := []*models.Order{}
-	for rows.Next() {
-		order := &models.Order{}
-		err := rows.Scan(&order.ID, &order.UserID, &order.RestaurantID, &order.Code, &order.State, &order.Description, &order.CreatedAt, &order.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		orders = append(orders, order)
-	}
-	return orders, nil
+	return scanOrders(rows)
 }
 
 func (r *OrderRepositoryImpl) GetRestaurantOrders(restaurantID string) ([]*models.Order, error) {
-	rows, err := r.db.Query("SELECT id, user_id, restaurant_id, code, state, description, created_at, updated_at FROM orders WHERE restaurant_id = $1", restaurantID)
+	rows, err := r.db.Query("SELECT "+orderColumns+" FROM orders WHERE restaurant_id = $1", restaurantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	orders := []*models.Order{}
-	for rows.Next() {
-		order := &models.Order{}
-		err := rows.Scan(&order.ID, &order.UserID, &order.RestaurantID, &order.Code, &order.State, &order.Description, &order.CreatedAt, &order.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		orders = append(orders, order)
+	return scanOrders(rows)
+}
+
+func (r *OrderRepositoryImpl) GetOrganizationOrders(organizationID int) ([]*models.Order, error) {
+	rows, err := r.db.Query("SELECT "+orderColumns+" FROM orders WHERE restaurant_id IN (SELECT id FROM restaurants WHERE organization_id = $1) ORDER BY created_at DESC", organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
+func (r *OrderRepositoryImpl) SearchOrders(filter *models.OrderFilter) ([]*models.Order, int, error) {
+	conditions := &filterBuilder{}
+	if filter.State != "" {
+		conditions.where("state = ?", filter.State)
+	}
+	if filter.Code != "" {
+		conditions.where("code = ?", filter.Code)
+	}
+	if filter.RestaurantID != "" {
+		conditions.where("restaurant_id = ?", filter.RestaurantID)
 	}
-	return orders, nil
+	if filter.UserID != "" {
+		conditions.where("user_id = ?", filter.UserID)
+	}
+	if filter.CreatedAfter != nil {
+		conditions.where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		conditions.where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	var total int
+	countQuery, countArgs := conditions.count("orders")
+	err := r.db.QueryRow(countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query, args := conditions.selectPage("orders", orderColumns, "created_at DESC, id DESC", filter.Pagination)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (r *OrderRepositoryImpl) GetPendingOrdersCreatedBefore(createdBefore time.Time) ([]*models.Order, error) {
+	rows, err := r.db.Query("SELECT "+orderColumns+" FROM orders WHERE state = 'PENDING' AND created_at < $1", createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
 }
 
 func (r *OrderRepositoryImpl) UpdateOrder(order *models.Order) (*models.Order, error) {
-	const query = "UPDATE orders SET user_id=$1, restaurant_id=$2, code=$3, state=$4, description=$5, updated_at=CLOCK_TIMESTAMP() WHERE id=$6 RETURNING id, user_id, restaurant_id, code, state, description, created_at, updated_at"
-	err := r.db.QueryRow(query, order.UserID, order.RestaurantID, order.Code, order.State, order.Description, order.ID).Scan(&order.ID, &order.UserID, &order.RestaurantID, &order.Code, &order.State, &order.Description, &order.CreatedAt, &order.UpdatedAt)
+	const query = "UPDATE orders SET user_id=$1, restaurant_id=$2, code=$3, state=$4, description=$5, updated_at=CLOCK_TIMESTAMP() WHERE id=$6 RETURNING " + orderColumns
+	err := scanOrder(r.db.QueryRow(query, order.UserID, order.RestaurantID, order.Code, order.State, order.Description, order.ID), order)
 	return order, err
 }
 
 // Updates the state of an order that belongs to a user
 func (r *OrderRepositoryImpl) UpdateUserOrderState(id int, fbUID string, state string) error {
-	_, err := r.db.Exec("UPDATE orders SET state = $1 WHERE id = $2 AND user_id = $3", state, id, fbUID)
-	return err
+	// The first acceptance is kept so the time to accept can be measured.
+	// Drivers only respond to the orders they were offered and haven't responded to yet
+	result, err := r.db.Exec("UPDATE orders SET state = $1, accepted_at = CASE WHEN $1 = 'ACCEPTED' THEN COALESCE(accepted_at, CLOCK_TIMESTAMP()) ELSE accepted_at END WHERE id = $2 AND user_id = $3 AND state = 'PENDING'", state, id, fbUID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
 }
 
-// Updates the state of an order that belongs to a restaurant
-func (r *OrderRepositoryImpl) UpdateRestaurantOrderState(id int, fbUID string, state string) error {
-	_, err := r.db.Exec("UPDATE orders SET state = $1 WHERE id = $2 AND restaurant_id = $3", state, id, fbUID)
-	return err
+// restaurantOrderTransitions are the states an order must be in for a restaurant to move it to each state.
+// Fulfilled and cancelled orders are final, expired orders can still be reassigned or cancelled
+var restaurantOrderTransitions = map[string][]string{
+	"FULFILLED": {"ACCEPTED"},
+	"CANCELLED": {"PENDING", "ACCEPTED", "REJECTED", "EXPIRED"},
+	"EXPIRED":   {"PENDING", "ACCEPTED", "REJECTED", "EXPIRED"},
+}
+
+// Updates the state of an order that belongs to a restaurant, only if the order can move to the state from the one it is in
+func (r *OrderRepositoryImpl) UpdateRestaurantOrderState(id int, fbUID string, state string) (*models.Order, error) {
+	query := "UPDATE orders SET state = $1, updated_at = CLOCK_TIMESTAMP() WHERE id = $2 AND restaurant_id IN (" + managedRestaurantIDs("$3") + ") AND state = ANY($4) RETURNING " + orderColumns
+	order := &models.Order{}
+	err := scanOrder(r.db.QueryRow(query, state, id, fbUID, pq.Array(restaurantOrderTransitions[state])), order)
+	if err == sql.ErrNoRows {
+		// Tell the orders of other restaurants apart from the ones that can't move to the state
+		_, err = r.GetOrder(id, fbUID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, utils.ErrInvalidOrderState
+	}
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Expires the order only if it is still pending, so a driver's response or another expiry isn't overwritten
+func (r *OrderRepositoryImpl) ExpirePendingOrder(id int) (*models.Order, error) {
+	order := &models.Order{}
+	err := scanOrder(r.db.QueryRow("UPDATE orders SET state = 'EXPIRED', updated_at = CLOCK_TIMESTAMP() WHERE id = $1 AND state = 'PENDING' RETURNING "+orderColumns, id), order)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+func (r *OrderRepositoryImpl) GetManagedRestaurantIDs(restaurantID string) ([]string, error) {
+	rows, err := r.db.Query(managedRestaurantIDs("$1"), restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	restaurantIDs := []string{}
+	for rows.Next() {
+		var id string
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		restaurantIDs = append(restaurantIDs, id)
+	}
+	return restaurantIDs, rows.Err()
 }
 
 // Fulfill the order if the order is accepted
@@ -156,3 +304,146 @@ func (r *OrderRepositoryImpl) DeleteOrder(id int) error {
 	_, err := r.db.Exec("DELETE FROM orders WHERE id = $1", id)
 	return err
 }
+
+// Only the first acknowledgement is kept, the app may report the same order more than once
+func (r *OrderRepositoryImpl) MarkOrderDelivered(id int, fbUID string) error {
+	result, err := r.db.Exec("UPDATE orders SET delivered_at = COALESCE(delivered_at, CLOCK_TIMESTAMP()) WHERE id = $1 AND user_id = $2", id, fbUID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *OrderRepositoryImpl) MarkOrderSeen(id int, fbUID string) error {
+	result, err := r.db.Exec("UPDATE orders SET delivered_at = COALESCE(delivered_at, CLOCK_TIMESTAMP()), seen_at = COALESCE(seen_at, CLOCK_TIMESTAMP()) WHERE id = $1 AND user_id = $2", id, fbUID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *OrderRepositoryImpl) AddOrderLocationPoints(userID string, points []*models.LocationPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	longitudes := make([]float64, len(points))
+	latitudes := make([]float64, len(points))
+	accuracies := make([]sql.NullFloat64, len(points))
+	ages := make([]float64, len(points))
+	now := time.Now()
+	for i, point := range points {
+		longitudes[i] = point.Longitude
+		latitudes[i] = point.Latitude
+		if point.Accuracy != nil {
+			accuracies[i] = sql.NullFloat64{Float64: *point.Accuracy, Valid: true}
+		}
+		// Like the user location, recorded_at is computed from the database clock and the age of the point
+		ages[i] = max(now.Sub(point.RecordedAt).Seconds(), 0)
+	}
+
+	// Every point is added to every accepted order of the user
+	const query = `
+	INSERT INTO order_location_points (order_id, user_id, location, accuracy, recorded_at, created_at)
+	SELECT o.id, o.user_id, ST_SetSRID(ST_MakePoint(p.longitude, p.latitude), 4326), p.accuracy, CLOCK_TIMESTAMP() - p.age * INTERVAL '1 second', CLOCK_TIMESTAMP()
+	FROM orders o
+	CROSS JOIN UNNEST($2::float8[], $3::float8[], $4::float8[], $5::float8[]) AS p(longitude, latitude, accuracy, age)
+	WHERE o.user_id = $1 AND o.state = 'ACCEPTED'
+	`
+	_, err := r.db.Exec(query, userID, pq.Array(longitudes), pq.Array(latitudes), pq.Array(accuracies), pq.Array(ages))
+	return err
+}
+
+func (r *OrderRepositoryImpl) GetOrderLocationPoints(orderID int, retention time.Duration) ([]*models.LocationPoint, error) {
+	const query = `
+	SELECT ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, accuracy, recorded_at
+	FROM order_location_points
+	WHERE order_id = $1 AND recorded_at > CLOCK_TIMESTAMP() - $2::float8 * INTERVAL '1 second'
+	ORDER BY recorded_at
+	`
+	rows, err := r.db.Query(query, orderID, retention.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []*models.LocationPoint{}
+	for rows.Next() {
+		point := &models.LocationPoint{}
+		var accuracy sql.NullFloat64
+		err := rows.Scan(&point.Longitude, &point.Latitude, &accuracy, &point.RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+		if accuracy.Valid {
+			point.Accuracy = &accuracy.Float64
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
+
+func (r *OrderRepositoryImpl) DeleteOrderLocationPointsOlderThan(retention time.Duration) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM order_location_points WHERE recorded_at <= CLOCK_TIMESTAMP() - $1::float8 * INTERVAL '1 second'", retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanOrder scans the orderColumns into the order
+func scanOrder(row rowScanner, order *models.Order) error {
+	var userID sql.NullString // We use sql.NullString to handle the case where the user_id is null
+	var deliveredAt, seenAt, acceptedAt sql.NullTime
+
+	err := row.Scan(&order.ID, &userID, &order.RestaurantID, &order.Code, &order.State, &order.RequiredVehicle, &order.Description, &deliveredAt, &seenAt, &acceptedAt, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	// If the user_id is not null, we set it in the order, otherwise we leave it as an empty string
+	order.UserID = userID.String
+	if deliveredAt.Valid {
+		order.DeliveredAt = &deliveredAt.Time
+	}
+	if seenAt.Valid {
+		order.SeenAt = &seenAt.Time
+	}
+	if acceptedAt.Valid {
+		order.AcceptedAt = &acceptedAt.Time
+	}
+
+	return nil
+}
+
+func scanOrders(rows *sql.Rows) ([]*models.Order, error) {
+	orders := []*models.Order{}
+	for rows.Next() {
+		order := &models.Order{}
+		err := scanOrder(rows, order)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// checkRowsAffected returns utils.ErrNotFound if the statement didn't affect any row
+func checkRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return utils.ErrNotFound
+	}
+
+	return nil
+}
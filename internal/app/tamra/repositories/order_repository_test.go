@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -153,6 +155,12 @@ func TestOrderRepository_UpdateUserOrderState(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, retrievedOrder)
 	assert.Equal(t, "ACCEPTED", retrievedOrder.State)
+
+	// Drivers can't respond to the orders of other drivers, nor to an order twice
+	err = orderRepo.UpdateUserOrderState(createdOrder.ID, "user1", "REJECTED")
+	assert.Equal(t, utils.ErrNotFound, err)
+	err = orderRepo.UpdateUserOrderState(createdOrder.ID, createdOrder.UserID, "REJECTED")
+	assert.Equal(t, utils.ErrNotFound, err)
 }
 
 func TestOrderRepository_UpdateRestaurantOrderState(t *testing.T) {
@@ -170,15 +178,64 @@ func TestOrderRepository_UpdateRestaurantOrderState(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, createdOrder)
 
+	// Only accepted orders are fulfilled
+	_, err = orderRepo.UpdateRestaurantOrderState(createdOrder.ID, createdOrder.RestaurantID, "FULFILLED")
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+
+	err = orderRepo.UpdateUserOrderState(createdOrder.ID, createdOrder.UserID, "ACCEPTED")
+	assert.NoError(t, err)
+
 	// Update the order state
-	err = orderRepo.UpdateRestaurantOrderState(createdOrder.ID, createdOrder.RestaurantID, "ACCEPTED")
+	updatedOrder, err := orderRepo.UpdateRestaurantOrderState(createdOrder.ID, createdOrder.RestaurantID, "FULFILLED")
 	assert.NoError(t, err)
+	assert.Equal(t, "FULFILLED", updatedOrder.State)
 
 	// Get the updated order
 	retrievedOrder, err := orderRepo.GetOrder(createdOrder.ID, createdOrder.RestaurantID)
 	assert.NoError(t, err)
 	assert.NotNil(t, retrievedOrder)
-	assert.Equal(t, "ACCEPTED", retrievedOrder.State)
+	assert.Equal(t, "FULFILLED", retrievedOrder.State)
+
+	// Fulfilled orders can't be cancelled or reassigned
+	_, err = orderRepo.UpdateRestaurantOrderState(createdOrder.ID, createdOrder.RestaurantID, "CANCELLED")
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+	_, err = orderRepo.UpdateRestaurantOrderState(createdOrder.ID, createdOrder.RestaurantID, "EXPIRED")
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+
+	// Orders of other restaurants aren't found
+	_, err = orderRepo.UpdateRestaurantOrderState(createdOrder.ID, "nonexistentrestaurant", "CANCELLED")
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+}
+
+func TestOrderRepository_ExpirePendingOrder(t *testing.T) {
+	orderRepo := NewOrderRepository(Db)
+
+	order := &models.Order{
+		UserID:       "user1",       // User from the seed
+		RestaurantID: "restaurant1", // Restaurant from the seed
+		Code:         "612533",
+		Description:  "Test Order",
+	}
+
+	createdOrder, err := orderRepo.CreateOrder(order)
+	assert.NoError(t, err)
+
+	expiredOrder, err := orderRepo.ExpirePendingOrder(createdOrder.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPIRED", expiredOrder.State)
+
+	// An order that already expired isn't expired again
+	_, err = orderRepo.ExpirePendingOrder(createdOrder.ID)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+
+	// An accepted order isn't overwritten
+	acceptedOrder, err := orderRepo.CreateOrder(&models.Order{UserID: "user1", RestaurantID: "restaurant1", Code: "612534", Description: "Test Order"})
+	assert.NoError(t, err)
+	err = orderRepo.UpdateUserOrderState(acceptedOrder.ID, "user1", "ACCEPTED")
+	assert.NoError(t, err)
+
+	_, err = orderRepo.ExpirePendingOrder(acceptedOrder.ID)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
 }
 
 func TestOrderRepository_CancelOrder(t *testing.T) {
@@ -206,3 +263,86 @@ func TestOrderRepository_CancelOrder(t *testing.T) {
 	assert.NotNil(t, retrievedOrder)
 	assert.Equal(t, "CANCELLED", retrievedOrder.State)
 }
+
+func TestOrderRepository_MarkOrderDeliveredAndSeen(t *testing.T) {
+	orderRepo := NewOrderRepository(Db)
+
+	order := &models.Order{
+		UserID:       "user1",       // User from the seed
+		RestaurantID: "restaurant1", // Restaurant from the seed
+		Code:         "7281932",
+		Description:  "Test Order",
+	}
+
+	createdOrder, err := orderRepo.CreateOrder(order)
+	assert.NoError(t, err)
+	assert.Nil(t, createdOrder.DeliveredAt)
+	assert.Nil(t, createdOrder.SeenAt)
+
+	// Another driver can't acknowledge the order
+	err = orderRepo.MarkOrderDelivered(createdOrder.ID, "user2")
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	err = orderRepo.MarkOrderDelivered(createdOrder.ID, createdOrder.UserID)
+	assert.NoError(t, err)
+
+	deliveredOrder, err := orderRepo.GetOrderByID(createdOrder.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, deliveredOrder.DeliveredAt)
+	assert.Nil(t, deliveredOrder.SeenAt)
+
+	err = orderRepo.MarkOrderSeen(createdOrder.ID, createdOrder.UserID)
+	assert.NoError(t, err)
+
+	seenOrder, err := orderRepo.GetOrderByID(createdOrder.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, seenOrder.SeenAt)
+	// The delivery timestamp is not overwritten
+	assert.Equal(t, deliveredOrder.DeliveredAt, seenOrder.DeliveredAt)
+}
+
+func TestOrderRepository_OrderLocationPoints(t *testing.T) {
+	orderRepo := NewOrderRepository(Db)
+
+	order := &models.Order{
+		UserID:       "user1",       // User from the seed
+		RestaurantID: "restaurant1", // Restaurant from the seed
+		Code:         "7281933",
+		Description:  "Test Order",
+	}
+
+	createdOrder, err := orderRepo.CreateOrder(order)
+	assert.NoError(t, err)
+
+	accuracy := 12.5
+	point := &models.LocationPoint{Longitude: 13.0, Latitude: 77.6, Accuracy: &accuracy, RecordedAt: time.Now()}
+
+	// Points are only recorded while the driver carries the order
+	err = orderRepo.AddOrderLocationPoints(createdOrder.UserID, []*models.LocationPoint{point})
+	assert.NoError(t, err)
+
+	points, err := orderRepo.GetOrderLocationPoints(createdOrder.ID, time.Hour)
+	assert.NoError(t, err)
+	assert.Empty(t, points)
+
+	err = orderRepo.UpdateUserOrderState(createdOrder.ID, createdOrder.UserID, "ACCEPTED")
+	assert.NoError(t, err)
+
+	err = orderRepo.AddOrderLocationPoints(createdOrder.UserID, []*models.LocationPoint{point, {Longitude: 13.1, Latitude: 77.7, RecordedAt: time.Now()}})
+	assert.NoError(t, err)
+
+	points, err = orderRepo.GetOrderLocationPoints(createdOrder.ID, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, points, 2)
+	assert.Equal(t, 13.0, points[0].Longitude)
+	assert.Equal(t, accuracy, *points[0].Accuracy)
+	assert.Nil(t, points[1].Accuracy)
+
+	// Points older than the retention period are purged
+	_, err = orderRepo.DeleteOrderLocationPointsOlderThan(0)
+	assert.NoError(t, err)
+
+	points, err = orderRepo.GetOrderLocationPoints(createdOrder.ID, time.Hour)
+	assert.NoError(t, err)
+	assert.Empty(t, points)
+}
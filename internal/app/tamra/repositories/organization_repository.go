@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"database/sql"
+)
+
+type OrganizationRepository interface {
+	// CreateOrganization creates an organization owned by a restaurant, the restaurant becomes its first branch.
+	// It fails with utils.ErrAlreadyInOrganization if the restaurant is already a branch of an organization
+	CreateOrganization(organization *models.Organization) (*models.Organization, error)
+	// GetOrganizationByOwner returns the organization owned by a restaurant
+	GetOrganizationByOwner(ownerRestaurantID string) (*models.Organization, error)
+	// CreateBranch creates a restaurant in an organization, its ID is generated since branches don't have an account
+	CreateBranch(organizationID int, branch *models.Restaurant) (*models.Restaurant, error)
+	// GetBranches returns the restaurants of an organization, the oldest first
+	GetBranches(organizationID int) ([]*models.Restaurant, error)
+	// GetBranch returns a restaurant of an organization
+	GetBranch(organizationID int, branchID string) (*models.Restaurant, error)
+	// DeleteBranch deletes a restaurant of an organization. The owner can't be deleted, it is deleted with its account
+	DeleteBranch(organizationID int, branchID string) error
+}
+
+// organizationColumns are the columns selected for every organization, in the order scanOrganization expects them
+const organizationColumns = "id, name, owner_restaurant_id, created_at, updated_at"
+
+type OrganizationRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) OrganizationRepository {
+	return &OrganizationRepositoryImpl{db: db}
+}
+
+func (r *OrganizationRepositoryImpl) CreateOrganization(organization *models.Organization) (*models.Organization, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The restaurant is locked so it can't join two organizations at the same time
+	var organizationID sql.NullInt64
+	err = tx.QueryRow("SELECT organization_id FROM restaurants WHERE id = $1 FOR UPDATE", organization.OwnerRestaurantID).Scan(&organizationID)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if organizationID.Valid {
+		return nil, utils.ErrAlreadyInOrganization
+	}
+
+	const query = "INSERT INTO organizations (name, owner_restaurant_id, created_at, updated_at) VALUES ($1, $2, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING " + organizationColumns
+	err = scanOrganization(tx.QueryRow(query, organization.Name, organization.OwnerRestaurantID), organization)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("UPDATE restaurants SET organization_id = $1, updated_at = CLOCK_TIMESTAMP() WHERE id = $2", organization.ID, organization.OwnerRestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return organization, tx.Commit()
+}
+
+func (r *OrganizationRepositoryImpl) GetOrganizationByOwner(ownerRestaurantID string) (*models.Organization, error) {
+	organization := &models.Organization{}
+	err := scanOrganization(r.db.QueryRow("SELECT "+organizationColumns+" FROM organizations WHERE owner_restaurant_id = $1", ownerRestaurantID), organization)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return organization, err
+}
+
+func (r *OrganizationRepositoryImpl) CreateBranch(organizationID int, branch *models.Restaurant) (*models.Restaurant, error) {
+	const query = `
+	INSERT INTO restaurants (id, organization_id, name, location, location_description, phone_number, logo_url, default_vehicle, created_at, updated_at)
+	VALUES (gen_random_uuid()::TEXT, $1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, $7, NULLIF($8, ''), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP())
+	RETURNING ` + restaurantColumns
+	err := scanRestaurant(r.db.QueryRow(query, organizationID, branch.Name, branch.Longitude, branch.Latitude, branch.LocationDescription, branch.PhoneNumber, branch.LogoURL, branch.DefaultVehicle), branch)
+	return branch, err
+}
+
+func (r *OrganizationRepositoryImpl) GetBranches(organizationID int) ([]*models.Restaurant, error) {
+	rows, err := r.db.Query("SELECT "+restaurantColumns+" FROM restaurants WHERE organization_id = $1 ORDER BY created_at", organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := []*models.Restaurant{}
+	for rows.Next() {
+		branch := &models.Restaurant{}
+		err := scanRestaurant(rows, branch)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+	}
+	return branches, rows.Err()
+}
+
+func (r *OrganizationRepositoryImpl) GetBranch(organizationID int, branchID string) (*models.Restaurant, error) {
+	branch := &models.Restaurant{}
+	err := scanRestaurant(r.db.QueryRow("SELECT "+restaurantColumns+" FROM restaurants WHERE id = $1 AND organization_id = $2", branchID, organizationID), branch)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return branch, err
+}
+
+func (r *OrganizationRepositoryImpl) DeleteBranch(organizationID int, branchID string) error {
+	const query = "DELETE FROM restaurants WHERE id = $1 AND organization_id = $2 AND id <> (SELECT owner_restaurant_id FROM organizations WHERE id = $2)"
+	result, err := r.db.Exec(query, branchID, organizationID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func scanOrganization(row rowScanner, organization *models.Organization) error {
+	return row.Scan(&organization.ID, &organization.Name, &organization.OwnerRestaurantID, &organization.CreatedAt, &organization.UpdatedAt)
+}
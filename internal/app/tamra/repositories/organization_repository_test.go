@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrganizationRepository_Branches(t *testing.T) {
+	restaurantRepo := NewRestaurantRepository(Db)
+	organizationRepo := NewOrganizationRepository(Db)
+
+	owner := &models.Restaurant{
+		ID:                  "chainowner",
+		Longitude:           12.9715987,
+		Latitude:            77.5945667,
+		Name:                "Test Chain",
+		PhoneNumber:         "4245129911",
+		LocationDescription: "Test Location",
+	}
+	_, err := restaurantRepo.CreateRestaurant(owner)
+	assert.NoError(t, err)
+
+	organization, err := organizationRepo.CreateOrganization(&models.Organization{Name: "Test Chain", OwnerRestaurantID: owner.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, owner.ID, organization.OwnerRestaurantID)
+
+	// A restaurant belongs to a single organization
+	_, err = organizationRepo.CreateOrganization(&models.Organization{Name: "Another Chain", OwnerRestaurantID: owner.ID})
+	assert.Equal(t, utils.ErrAlreadyInOrganization, err)
+
+	branch, err := organizationRepo.CreateBranch(organization.ID, &models.Restaurant{
+		Longitude:           13.0715987,
+		Latitude:            77.6945667,
+		Name:                "Test Chain Downtown",
+		LocationDescription: "Downtown",
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, branch.ID)
+	assert.Equal(t, 13.0715987, branch.Longitude)
+
+	branches, err := organizationRepo.GetBranches(organization.ID)
+	assert.NoError(t, err)
+	assert.Len(t, branches, 2)
+	assert.Equal(t, owner.ID, branches[0].ID)
+
+	// The owner manages the orders of every branch, the branches only their own
+	managedRestaurantIDs, err := NewOrderRepository(Db).GetManagedRestaurantIDs(owner.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{owner.ID, branch.ID}, managedRestaurantIDs)
+
+	managedRestaurantIDs, err = NewOrderRepository(Db).GetManagedRestaurantIDs(branch.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{branch.ID}, managedRestaurantIDs)
+
+	fetchedBranch, err := organizationRepo.GetBranch(organization.ID, branch.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, branch.Name, fetchedBranch.Name)
+
+	// Restaurants outside of the organization aren't its branches
+	_, err = organizationRepo.GetBranch(organization.ID, "restaurant1")
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// The owner can't be deleted as a branch
+	err = organizationRepo.DeleteBranch(organization.ID, owner.ID)
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	err = organizationRepo.DeleteBranch(organization.ID, branch.ID)
+	assert.NoError(t, err)
+	_, err = organizationRepo.GetBranch(organization.ID, branch.ID)
+	assert.Equal(t, utils.ErrNotFound, err)
+}
@@ -4,21 +4,97 @@ import (
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
 	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type RestaurantRepository interface {
-	// CreateRestaurant creates a new restaurant
+	// CreateRestaurant creates a new restaurant owned by the account whose UID is the ID of the restaurant
 	CreateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error)
 	// GetRestaurant returns a restaurant by its ID
 	GetRestaurant(fbUID string) (*models.Restaurant, error)
 	// GetRestaurantByID returns a restaurant by its ID
 	GetRestaurantByID(restaurantID string) (*models.Restaurant, error)
-	// UpdateRestaurant updates a restaurant
-	UpdateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error)
+	// UpdateRestaurant updates the fields of a restaurant that are set in the patch
+	UpdateRestaurant(restaurantID string, patch *models.RestaurantPatch) (*models.Restaurant, error)
+	// SearchRestaurants returns a page of the restaurants matching the filter, the newest first, and how many match it
+	SearchRestaurants(filter *models.RestaurantFilter) ([]*models.Restaurant, int, error)
+	// SearchNearbyRestaurants returns a page of the restaurants within the radius of a location, the closest first, and how many there are
+	SearchNearbyRestaurants(longitude float64, latitude float64, radius int, pagination models.Pagination) ([]*models.NearbyRestaurant, int, error)
 	// Delete a restaurant
 	DeleteRestaurant(id string) error
+	// CreateRestaurantDevice registers a device of a restaurant. Registering an existing token moves it to the given restaurant
+	CreateRestaurantDevice(device *models.RestaurantDevice) (*models.RestaurantDevice, error)
+	// GetRestaurantDevices returns the devices registered by a restaurant
+	GetRestaurantDevices(restaurantID string) ([]*models.RestaurantDevice, error)
+	// DeleteRestaurantDevice deletes a device that belongs to a restaurant
+	DeleteRestaurantDevice(id int, restaurantID string) error
+	// SetRestaurantDriverPreference creates or replaces the preference of a restaurant for a driver
+	SetRestaurantDriverPreference(preference *models.RestaurantDriverPreference) (*models.RestaurantDriverPreference, error)
+	// GetRestaurantDriverPreferences returns the preferences of a restaurant, all of them if preference is empty
+	GetRestaurantDriverPreferences(restaurantID string, preference string) ([]*models.RestaurantDriverPreference, error)
+	// DeleteRestaurantDriverPreference deletes the preference of a restaurant for a driver
+	DeleteRestaurantDriverPreference(restaurantID string, userID string) error
+	// GetRestaurantMember returns the membership of an account, the restaurant it works for
+	GetRestaurantMember(uid string) (*models.RestaurantMember, error)
+	// GetRestaurantMembers returns the members of a restaurant, the oldest first
+	GetRestaurantMembers(restaurantID string) ([]*models.RestaurantMember, error)
+	// GetAllRestaurantMembers returns the members of every restaurant
+	GetAllRestaurantMembers() ([]*models.RestaurantMember, error)
+	// DeleteRestaurantMember removes a member from a restaurant. The owner can't be removed
+	DeleteRestaurantMember(restaurantID string, uid string) error
+	// CreateRestaurantInvite creates an invite that expires after the ttl
+	CreateRestaurantInvite(invite *models.RestaurantInvite, ttl time.Duration) (*models.RestaurantInvite, error)
+	// GetRestaurantInvites returns the pending invites of a restaurant
+	GetRestaurantInvites(restaurantID string) ([]*models.RestaurantInvite, error)
+	// GetRestaurantInvitesByEmail returns the pending invites sent to an email
+	GetRestaurantInvitesByEmail(email string) ([]*models.RestaurantInvite, error)
+	// DeleteRestaurantInvite deletes a pending invite of a restaurant
+	DeleteRestaurantInvite(id int, restaurantID string) error
+	// AcceptRestaurantInvite makes the account a member of the restaurant of a pending invite sent to its email.
+	// It fails with utils.ErrAlreadyMember if the account already works for a restaurant
+	AcceptRestaurantInvite(id int, email string, uid string) (*models.RestaurantMember, error)
+	// GetRestaurantOpeningHours returns the timezone and weekly opening hours of a restaurant
+	GetRestaurantOpeningHours(restaurantID string) (*models.RestaurantOpeningHours, error)
+	// ReplaceRestaurantOpeningHours replaces the timezone and all the weekly opening hours of a restaurant
+	ReplaceRestaurantOpeningHours(restaurantID string, openingHours *models.RestaurantOpeningHours) (*models.RestaurantOpeningHours, error)
+	CreateRestaurantClosure(closure *models.RestaurantClosure) (*models.RestaurantClosure, error)
+	// GetRestaurantClosures returns the current and upcoming closures of a restaurant
+	GetRestaurantClosures(restaurantID string) ([]*models.RestaurantClosure, error)
+	DeleteRestaurantClosure(id int, restaurantID string) error
+	// GetRestaurantAvailability returns whether a restaurant takes orders right now
+	GetRestaurantAvailability(restaurantID string) (*models.RestaurantAvailability, error)
+	// GetRestaurantDeliveryZone returns the delivery zone of a restaurant
+	GetRestaurantDeliveryZone(restaurantID string) (*models.DeliveryZone, error)
+	// SetRestaurantDeliveryZone creates or replaces the delivery zone of a restaurant.
+	// It fails with utils.ErrInvalidZone if the polygon of the zone isn't a valid geometry
+	SetRestaurantDeliveryZone(zone *models.DeliveryZone) (*models.DeliveryZone, error)
+	DeleteRestaurantDeliveryZone(restaurantID string) error
 }
 
+// restaurantDriverPreferenceColumns are the columns selected for every driver preference, in the order scanRestaurantDriverPreference expects them
+const restaurantDriverPreferenceColumns = "restaurant_id, user_id, preference, note, created_at, updated_at"
+
+// restaurantMemberColumns are the columns selected for every member, in the order scanRestaurantMember expects them
+const restaurantMemberColumns = "restaurant_id, uid, role, created_at, updated_at"
+
+// restaurantInviteColumns are the columns selected for every invite, in the order scanRestaurantInvite expects them
+const restaurantInviteColumns = "id, restaurant_id, email, role, accepted_by, accepted_at, expires_at, created_at, updated_at"
+
+// pendingRestaurantInvite filters the invites that can still be accepted
+const pendingRestaurantInvite = "accepted_at IS NULL AND expires_at > CLOCK_TIMESTAMP()"
+
+// restaurantClosureColumns are the columns selected for every closure, in the order scanRestaurantClosure expects them
+const restaurantClosureColumns = "id, restaurant_id, starts_at, ends_at, reason, created_at"
+
+// deliveryZoneColumns are the columns selected for every delivery zone, in the order scanDeliveryZone expects them
+const deliveryZoneColumns = "restaurant_id, zone_type, COALESCE(ST_AsGeoJSON(area), ''), COALESCE(radius, 0), created_at, updated_at"
+
+// restaurantColumns are the columns selected for every restaurant, in the order scanRestaurant expects them
+const restaurantColumns = "id, name, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, location_description, phone_number, logo_url, COALESCE(default_vehicle, ''), is_paused, created_at, updated_at"
+
 type RestaurantRepositoryImpl struct {
 	db *sql.DB
 }
@@ -28,14 +104,24 @@ func NewRestaurantRepository(db *sql.DB) RestaurantRepository {
 }
 
 func (r *RestaurantRepositoryImpl) CreateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error) {
-	const query = "INSERT INTO restaurants (id, name, location, location_description, phone_number, logo_url, created_at, updated_at) VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, $7, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING id, name, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, location_description, phone_number, logo_url,  created_at, updated_at"
-	err := r.db.QueryRow(query, restaurant.ID, restaurant.Name, restaurant.Longitude, restaurant.Latitude, restaurant.LocationDescription, restaurant.PhoneNumber, restaurant.LogoURL).Scan(&restaurant.ID, &restaurant.Name, &restaurant.Longitude, &restaurant.Latitude, &restaurant.LocationDescription, &restaurant.PhoneNumber, &restaurant.LogoURL, &restaurant.CreatedAt, &restaurant.UpdatedAt)
+	// The owner membership is created in the same statement so a restaurant always has an owner
+	const query = `
+	WITH restaurant AS (
+		INSERT INTO restaurants (id, name, location, location_description, phone_number, logo_url, default_vehicle, created_at, updated_at)
+		VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, $7, NULLIF($8, ''), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP())
+		RETURNING *
+	), owner AS (
+		INSERT INTO restaurant_members (restaurant_id, uid, role, created_at, updated_at)
+		SELECT id, id, 'OWNER', CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP() FROM restaurant
+	)
+	SELECT ` + restaurantColumns + ` FROM restaurant`
+	err := scanRestaurant(r.db.QueryRow(query, restaurant.ID, restaurant.Name, restaurant.Longitude, restaurant.Latitude, restaurant.LocationDescription, restaurant.PhoneNumber, restaurant.LogoURL, restaurant.DefaultVehicle), restaurant)
 	return restaurant, err
 }
 
 func (r *RestaurantRepositoryImpl) GetRestaurant(fbUID string) (*models.Restaurant, error) {
 	restaurant := &models.Restaurant{}
-	err := r.db.QueryRow("SELECT id, name, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, location_description, phone_number, logo_url, created_at, updated_at FROM restaurants WHERE id = $1", fbUID).Scan(&restaurant.ID, &restaurant.Name, &restaurant.Longitude, &restaurant.Latitude, &restaurant.LocationDescription, &restaurant.PhoneNumber, &restaurant.LogoURL, &restaurant.CreatedAt, &restaurant.UpdatedAt)
+	err := scanRestaurant(r.db.QueryRow("SELECT "+restaurantColumns+" FROM restaurants WHERE id = $1", fbUID), restaurant)
 	// Return a custom error if the restaurant is not found so that the service or handler can handle it.
 	// In this case we want to return a 404 status code
 	if err == sql.ErrNoRows {
@@ -47,7 +133,7 @@ func (r *RestaurantRepositoryImpl) GetRestaurant(fbUID string) (*models.Restaura
 
 func (r *RestaurantRepositoryImpl) GetRestaurantByID(restaurantID string) (*models.Restaurant, error) {
 	restaurant := &models.Restaurant{}
-	err := r.db.QueryRow("SELECT id, name, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, location_description, phone_number, logo_url, created_at, updated_at FROM restaurants WHERE id = $1", restaurantID).Scan(&restaurant.ID, &restaurant.Name, &restaurant.Longitude, &restaurant.Latitude, &restaurant.LocationDescription, &restaurant.PhoneNumber, &restaurant.LogoURL, &restaurant.CreatedAt, &restaurant.UpdatedAt)
+	err := scanRestaurant(r.db.QueryRow("SELECT "+restaurantColumns+" FROM restaurants WHERE id = $1", restaurantID), restaurant)
 	// Return a custom error if the restaurant is not found so that the service or handler can handle it.
 	// In this case we want to return a 404 status code
 	if err == sql.ErrNoRows {
@@ -57,14 +143,44 @@ func (r *RestaurantRepositoryImpl) GetRestaurantByID(restaurantID string) (*mode
 	return restaurant, err
 }
 
-func (r *RestaurantRepositoryImpl) UpdateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error) {
-	const query = "UPDATE restaurants SET name = $1, location = ST_SetSRID(ST_MakePoint($2, $3), 4326), location_description = $4, phone_number = $5, logo_url = $6, updated_at = CLOCK_TIMESTAMP() WHERE id = $7 RETURNING id, name, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, location_description, phone_number, logo_url, created_at, updated_at"
-	err := r.db.QueryRow(query, restaurant.Name, restaurant.Longitude, restaurant.Latitude, restaurant.LocationDescription, restaurant.PhoneNumber, restaurant.LogoURL, restaurant.ID).Scan(&restaurant.ID, &restaurant.Name, &restaurant.Longitude, &restaurant.Latitude, &restaurant.LocationDescription, &restaurant.PhoneNumber, &restaurant.LogoURL, &restaurant.CreatedAt, &restaurant.UpdatedAt)
+func (r *RestaurantRepositoryImpl) UpdateRestaurant(restaurantID string, patch *models.RestaurantPatch) (*models.Restaurant, error) {
+	// Only the columns of the fields that are set are written
+	update := &updateBuilder{}
+	if patch.Name != nil {
+		update.set("name", *patch.Name)
+	}
+	if patch.Longitude != nil && patch.Latitude != nil {
+		update.setExpression("location", "ST_SetSRID(ST_MakePoint(?, ?), 4326)", *patch.Longitude, *patch.Latitude)
+	}
+	if patch.LocationDescription != nil {
+		update.set("location_description", *patch.LocationDescription)
+	}
+	if patch.PhoneNumber != nil {
+		update.set("phone_number", *patch.PhoneNumber)
+	}
+	if patch.LogoURL != nil {
+		update.set("logo_url", *patch.LogoURL)
+	}
+	if patch.DefaultVehicle != nil {
+		// An empty default vehicle removes it
+		update.setExpression("default_vehicle", "NULLIF(?, '')", *patch.DefaultVehicle)
+	}
+	if patch.IsPaused != nil {
+		update.set("is_paused", *patch.IsPaused)
+	}
+	update.setExpression("updated_at", "CLOCK_TIMESTAMP()")
+
+	query, args := update.build("restaurants", restaurantID, restaurantColumns)
+	restaurant := &models.Restaurant{}
+	err := scanRestaurant(r.db.QueryRow(query, args...), restaurant)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
 	return restaurant, err
 }
 
 func (r *RestaurantRepositoryImpl) GetRestaurants() ([]*models.Restaurant, error) {
-	rows, err := r.db.Query("SELECT id, name, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, location_description, phone_number, logo_url, created_at, updated_at FROM restaurants")
+	rows, err := r.db.Query("SELECT " + restaurantColumns + " FROM restaurants")
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +189,7 @@ func (r *RestaurantRepositoryImpl) GetRestaurants() ([]*models.Restaurant, error
 	restaurants := []*models.Restaurant{}
 	for rows.Next() {
 		restaurant := &models.Restaurant{}
-		err := rows.Scan(&restaurant.ID, &restaurant.Name, &restaurant.Longitude, &restaurant.Latitude, &restaurant.LocationDescription, &restaurant.PhoneNumber, &restaurant.LogoURL, &restaurant.CreatedAt, &restaurant.UpdatedAt)
+		err := scanRestaurant(rows, restaurant)
 		if err != nil {
 			return nil, err
 		}
@@ -87,7 +203,522 @@ func (r *RestaurantRepositoryImpl) GetRestaurants() ([]*models.Restaurant, error
 	return restaurants, nil
 }
 
+func (r *RestaurantRepositoryImpl) SearchRestaurants(filter *models.RestaurantFilter) ([]*models.Restaurant, int, error) {
+	conditions := &filterBuilder{}
+	if filter.Search != "" {
+		conditions.where("(id ILIKE ? OR name ILIKE ?)", "%"+filter.Search+"%", "%"+filter.Search+"%")
+	}
+	if filter.OrganizationID != nil {
+		conditions.where("organization_id = ?", *filter.OrganizationID)
+	}
+
+	var total int
+	countQuery, countArgs := conditions.count("restaurants")
+	err := r.db.QueryRow(countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query, args := conditions.selectPage("restaurants", restaurantColumns, "created_at DESC, id", filter.Pagination)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	restaurants := []*models.Restaurant{}
+	for rows.Next() {
+		restaurant := &models.Restaurant{}
+		err := scanRestaurant(rows, restaurant)
+		if err != nil {
+			return nil, 0, err
+		}
+		restaurants = append(restaurants, restaurant)
+	}
+	return restaurants, total, rows.Err()
+}
+
+func (r *RestaurantRepositoryImpl) SearchNearbyRestaurants(longitude float64, latitude float64, radius int, pagination models.Pagination) ([]*models.NearbyRestaurant, int, error) {
+	// ST_DWithin uses the GIST index on the location, ST_Distance is only computed for the restaurants in range
+	const within = "ST_DWithin(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3)"
+
+	var total int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM restaurants WHERE "+within, longitude, latitude, radius).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const query = `
+	SELECT ` + restaurantColumns + `, ST_Distance(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) AS distance
+	FROM restaurants
+	WHERE ` + within + `
+	ORDER BY distance, id
+	LIMIT $4 OFFSET $5
+	`
+	rows, err := r.db.Query(query, longitude, latitude, radius, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	restaurants := []*models.NearbyRestaurant{}
+	for rows.Next() {
+		restaurant := &models.NearbyRestaurant{Restaurant: &models.Restaurant{}}
+		err := scanRestaurant(rows, restaurant.Restaurant, &restaurant.Distance)
+		if err != nil {
+			return nil, 0, err
+		}
+		restaurants = append(restaurants, restaurant)
+	}
+	return restaurants, total, rows.Err()
+}
+
 func (r *RestaurantRepositoryImpl) DeleteRestaurant(id string) error {
 	_, err := r.db.Exec("DELETE FROM restaurants WHERE id = $1", id)
 	return err
 }
+
+func (r *RestaurantRepositoryImpl) CreateRestaurantDevice(device *models.RestaurantDevice) (*models.RestaurantDevice, error) {
+	// A token identifies a single app installation, so if it was registered before (e.g. the device logged into another restaurant) we take it over
+	const query = `
+	INSERT INTO restaurant_devices (restaurant_id, fcm_token, platform, created_at, updated_at)
+	VALUES ($1, $2, $3, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP())
+	ON CONFLICT (fcm_token) DO UPDATE SET restaurant_id = EXCLUDED.restaurant_id, platform = EXCLUDED.platform, updated_at = CLOCK_TIMESTAMP()
+	RETURNING id, restaurant_id, fcm_token, platform, created_at, updated_at
+	`
+	err := r.db.QueryRow(query, device.RestaurantID, device.FCMToken, device.Platform).Scan(&device.ID, &device.RestaurantID, &device.FCMToken, &device.Platform, &device.CreatedAt, &device.UpdatedAt)
+	return device, err
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantDevices(restaurantID string) ([]*models.RestaurantDevice, error) {
+	rows, err := r.db.Query("SELECT id, restaurant_id, fcm_token, platform, created_at, updated_at FROM restaurant_devices WHERE restaurant_id = $1", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []*models.RestaurantDevice{}
+	for rows.Next() {
+		device := &models.RestaurantDevice{}
+		err := rows.Scan(&device.ID, &device.RestaurantID, &device.FCMToken, &device.Platform, &device.CreatedAt, &device.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+func (r *RestaurantRepositoryImpl) DeleteRestaurantDevice(id int, restaurantID string) error {
+	result, err := r.db.Exec("DELETE FROM restaurant_devices WHERE id = $1 AND restaurant_id = $2", id, restaurantID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *RestaurantRepositoryImpl) SetRestaurantDriverPreference(preference *models.RestaurantDriverPreference) (*models.RestaurantDriverPreference, error) {
+	// Selecting the driver makes the query return no row instead of violating the foreign key when the driver doesn't exist
+	const query = `
+	INSERT INTO restaurant_driver_preferences (restaurant_id, user_id, preference, note, created_at, updated_at)
+	SELECT $1, id, $3, $4, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP() FROM users WHERE id = $2
+	ON CONFLICT (restaurant_id, user_id) DO UPDATE SET preference = EXCLUDED.preference, note = EXCLUDED.note, updated_at = CLOCK_TIMESTAMP()
+	RETURNING ` + restaurantDriverPreferenceColumns
+	err := scanRestaurantDriverPreference(r.db.QueryRow(query, preference.RestaurantID, preference.UserID, preference.Preference, preference.Note), preference)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return preference, err
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantDriverPreferences(restaurantID string, preference string) ([]*models.RestaurantDriverPreference, error) {
+	rows, err := r.db.Query("SELECT "+restaurantDriverPreferenceColumns+" FROM restaurant_driver_preferences WHERE restaurant_id = $1 AND ($2 = '' OR preference = $2) ORDER BY created_at", restaurantID, preference)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	preferences := []*models.RestaurantDriverPreference{}
+	for rows.Next() {
+		driverPreference := &models.RestaurantDriverPreference{}
+		err := scanRestaurantDriverPreference(rows, driverPreference)
+		if err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, driverPreference)
+	}
+	return preferences, rows.Err()
+}
+
+func (r *RestaurantRepositoryImpl) DeleteRestaurantDriverPreference(restaurantID string, userID string) error {
+	result, err := r.db.Exec("DELETE FROM restaurant_driver_preferences WHERE restaurant_id = $1 AND user_id = $2", restaurantID, userID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantMember(uid string) (*models.RestaurantMember, error) {
+	member := &models.RestaurantMember{}
+	err := scanRestaurantMember(r.db.QueryRow("SELECT "+restaurantMemberColumns+" FROM restaurant_members WHERE uid = $1", uid), member)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return member, err
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantMembers(restaurantID string) ([]*models.RestaurantMember, error) {
+	rows, err := r.db.Query("SELECT "+restaurantMemberColumns+" FROM restaurant_members WHERE restaurant_id = $1 ORDER BY created_at", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRestaurantMembers(rows)
+}
+
+func (r *RestaurantRepositoryImpl) GetAllRestaurantMembers() ([]*models.RestaurantMember, error) {
+	rows, err := r.db.Query("SELECT " + restaurantMemberColumns + " FROM restaurant_members ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRestaurantMembers(rows)
+}
+
+func scanRestaurantMembers(rows *sql.Rows) ([]*models.RestaurantMember, error) {
+	members := []*models.RestaurantMember{}
+	for rows.Next() {
+		member := &models.RestaurantMember{}
+		err := scanRestaurantMember(rows, member)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (r *RestaurantRepositoryImpl) DeleteRestaurantMember(restaurantID string, uid string) error {
+	result, err := r.db.Exec("DELETE FROM restaurant_members WHERE restaurant_id = $1 AND uid = $2 AND role <> 'OWNER'", restaurantID, uid)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *RestaurantRepositoryImpl) CreateRestaurantInvite(invite *models.RestaurantInvite, ttl time.Duration) (*models.RestaurantInvite, error) {
+	// Emails are stored in lower case so they match the ones of the tokens whatever the case they were typed in
+	const query = `
+	INSERT INTO restaurant_invites (restaurant_id, email, role, expires_at, created_at, updated_at)
+	VALUES ($1, LOWER($2), $3, CLOCK_TIMESTAMP() + make_interval(secs => $4), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP())
+	RETURNING ` + restaurantInviteColumns
+	err := scanRestaurantInvite(r.db.QueryRow(query, invite.RestaurantID, invite.Email, invite.Role, ttl.Seconds()), invite)
+	return invite, err
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantInvites(restaurantID string) ([]*models.RestaurantInvite, error) {
+	return r.queryRestaurantInvites("SELECT "+restaurantInviteColumns+" FROM restaurant_invites WHERE restaurant_id = $1 AND "+pendingRestaurantInvite+" ORDER BY created_at", restaurantID)
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantInvitesByEmail(email string) ([]*models.RestaurantInvite, error) {
+	return r.queryRestaurantInvites("SELECT "+restaurantInviteColumns+" FROM restaurant_invites WHERE email = LOWER($1) AND "+pendingRestaurantInvite+" ORDER BY created_at", email)
+}
+
+func (r *RestaurantRepositoryImpl) queryRestaurantInvites(query string, args ...any) ([]*models.RestaurantInvite, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*models.RestaurantInvite{}
+	for rows.Next() {
+		invite := &models.RestaurantInvite{}
+		err := scanRestaurantInvite(rows, invite)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+func (r *RestaurantRepositoryImpl) DeleteRestaurantInvite(id int, restaurantID string) error {
+	result, err := r.db.Exec("DELETE FROM restaurant_invites WHERE id = $1 AND restaurant_id = $2 AND "+pendingRestaurantInvite, id, restaurantID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *RestaurantRepositoryImpl) AcceptRestaurantInvite(id int, email string, uid string) (*models.RestaurantMember, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var isMember bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM restaurant_members WHERE uid = $1)", uid).Scan(&isMember)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return nil, utils.ErrAlreadyMember
+	}
+
+	var restaurantID, role string
+	err = tx.QueryRow("UPDATE restaurant_invites SET accepted_by = $3, accepted_at = CLOCK_TIMESTAMP(), updated_at = CLOCK_TIMESTAMP() WHERE id = $1 AND email = LOWER($2) AND "+pendingRestaurantInvite+" RETURNING restaurant_id, role", id, email, uid).Scan(&restaurantID, &role)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	member := &models.RestaurantMember{}
+	err = scanRestaurantMember(tx.QueryRow("INSERT INTO restaurant_members (restaurant_id, uid, role, created_at, updated_at) VALUES ($1, $2, $3, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING "+restaurantMemberColumns, restaurantID, uid, role), member)
+	if err != nil {
+		return nil, err
+	}
+
+	return member, tx.Commit()
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantOpeningHours(restaurantID string) (*models.RestaurantOpeningHours, error) {
+	openingHours := &models.RestaurantOpeningHours{}
+	err := r.db.QueryRow("SELECT timezone FROM restaurants WHERE id = $1", restaurantID).Scan(&openingHours.Timezone)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query("SELECT id, weekday, TO_CHAR(open_time, 'HH24:MI'), TO_CHAR(close_time, 'HH24:MI') FROM restaurant_opening_hours WHERE restaurant_id = $1 ORDER BY weekday, open_time", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	openingHours.Periods = []*models.OpeningPeriod{}
+	for rows.Next() {
+		period := &models.OpeningPeriod{}
+		err := rows.Scan(&period.ID, &period.Weekday, &period.OpenTime, &period.CloseTime)
+		if err != nil {
+			return nil, err
+		}
+		openingHours.Periods = append(openingHours.Periods, period)
+	}
+
+	return openingHours, rows.Err()
+}
+
+func (r *RestaurantRepositoryImpl) ReplaceRestaurantOpeningHours(restaurantID string, openingHours *models.RestaurantOpeningHours) (*models.RestaurantOpeningHours, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE restaurants SET timezone = $1, updated_at = CLOCK_TIMESTAMP() WHERE id = $2", openingHours.Timezone, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRowsAffected(result)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("DELETE FROM restaurant_opening_hours WHERE restaurant_id = $1", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, period := range openingHours.Periods {
+		err = tx.QueryRow("INSERT INTO restaurant_opening_hours (restaurant_id, weekday, open_time, close_time, created_at, updated_at) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING id", restaurantID, period.Weekday, period.OpenTime, period.CloseTime).Scan(&period.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetRestaurantOpeningHours(restaurantID)
+}
+
+func (r *RestaurantRepositoryImpl) CreateRestaurantClosure(closure *models.RestaurantClosure) (*models.RestaurantClosure, error) {
+	const query = "INSERT INTO restaurant_closures (restaurant_id, starts_at, ends_at, reason, created_at) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP()) RETURNING " + restaurantClosureColumns
+	err := scanRestaurantClosure(r.db.QueryRow(query, closure.RestaurantID, closure.StartsAt, closure.EndsAt, closure.Reason), closure)
+	return closure, err
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantClosures(restaurantID string) ([]*models.RestaurantClosure, error) {
+	rows, err := r.db.Query("SELECT "+restaurantClosureColumns+" FROM restaurant_closures WHERE restaurant_id = $1 AND ends_at > CURRENT_TIMESTAMP ORDER BY starts_at", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closures := []*models.RestaurantClosure{}
+	for rows.Next() {
+		closure := &models.RestaurantClosure{}
+		err := scanRestaurantClosure(rows, closure)
+		if err != nil {
+			return nil, err
+		}
+		closures = append(closures, closure)
+	}
+	return closures, rows.Err()
+}
+
+func (r *RestaurantRepositoryImpl) DeleteRestaurantClosure(id int, restaurantID string) error {
+	result, err := r.db.Exec("DELETE FROM restaurant_closures WHERE id = $1 AND restaurant_id = $2", id, restaurantID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantAvailability(restaurantID string) (*models.RestaurantAvailability, error) {
+	// The opening hours are compared to the current time in the timezone of the restaurant, like the schedules of the drivers
+	const query = `
+	SELECT
+		r.is_paused,
+		EXISTS (SELECT 1 FROM restaurant_closures c WHERE c.restaurant_id = r.id AND CURRENT_TIMESTAMP >= c.starts_at AND CURRENT_TIMESTAMP < c.ends_at),
+		NOT EXISTS (SELECT 1 FROM restaurant_opening_hours h WHERE h.restaurant_id = r.id)
+		OR EXISTS (
+			SELECT 1 FROM restaurant_opening_hours h
+			WHERE h.restaurant_id = r.id
+			AND (
+				(
+					h.weekday = EXTRACT(DOW FROM CURRENT_TIMESTAMP AT TIME ZONE r.timezone)
+					AND (CURRENT_TIMESTAMP AT TIME ZONE r.timezone)::time >= h.open_time
+					AND ((CURRENT_TIMESTAMP AT TIME ZONE r.timezone)::time < h.close_time OR h.close_time < h.open_time)
+				)
+				-- Overnight periods close on the day after their weekday
+				OR (
+					h.close_time < h.open_time
+					AND h.weekday = EXTRACT(DOW FROM (CURRENT_TIMESTAMP AT TIME ZONE r.timezone) - INTERVAL '1 day')
+					AND (CURRENT_TIMESTAMP AT TIME ZONE r.timezone)::time < h.close_time
+				)
+			)
+		)
+	FROM restaurants r
+	WHERE r.id = $1
+	`
+	var isPaused, inClosure, inOpeningHours bool
+	err := r.db.QueryRow(query, restaurantID).Scan(&isPaused, &inClosure, &inOpeningHours)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &models.RestaurantAvailability{IsOpen: true}
+	switch {
+	case isPaused:
+		availability = &models.RestaurantAvailability{ClosedReason: models.ClosedReasonPaused}
+	case inClosure:
+		availability = &models.RestaurantAvailability{ClosedReason: models.ClosedReasonClosure}
+	case !inOpeningHours:
+		availability = &models.RestaurantAvailability{ClosedReason: models.ClosedReasonOutsideOpeningHours}
+	}
+	return availability, nil
+}
+
+func (r *RestaurantRepositoryImpl) GetRestaurantDeliveryZone(restaurantID string) (*models.DeliveryZone, error) {
+	zone := &models.DeliveryZone{}
+	err := scanDeliveryZone(r.db.QueryRow("SELECT "+deliveryZoneColumns+" FROM restaurant_delivery_zones WHERE restaurant_id = $1", restaurantID), zone)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return zone, err
+}
+
+func (r *RestaurantRepositoryImpl) SetRestaurantDeliveryZone(zone *models.DeliveryZone) (*models.DeliveryZone, error) {
+	// The polygon is only written if PostGIS considers it valid, e.g. its boundary doesn't cross itself
+	const query = `
+	INSERT INTO restaurant_delivery_zones (restaurant_id, zone_type, area, radius, created_at, updated_at)
+	SELECT $1, $2, ST_GeomFromText(NULLIF($3, ''), 4326)::geography, NULLIF($4, 0), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()
+	WHERE CASE WHEN $3 = '' THEN TRUE ELSE ST_IsValid(ST_GeomFromText($3, 4326)) END
+	ON CONFLICT (restaurant_id) DO UPDATE SET zone_type = EXCLUDED.zone_type, area = EXCLUDED.area, radius = EXCLUDED.radius, updated_at = CLOCK_TIMESTAMP()
+	RETURNING ` + deliveryZoneColumns
+	err := scanDeliveryZone(r.db.QueryRow(query, zone.RestaurantID, zone.Type, polygonWKT(zone.Polygon), zone.Radius), zone)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrInvalidZone
+	}
+	return zone, err
+}
+
+func (r *RestaurantRepositoryImpl) DeleteRestaurantDeliveryZone(restaurantID string) error {
+	result, err := r.db.Exec("DELETE FROM restaurant_delivery_zones WHERE restaurant_id = $1", restaurantID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+// polygonWKT formats the points of a polygon as Well-Known Text, an empty string if there are none
+func polygonWKT(points [][]float64) string {
+	if len(points) == 0 {
+		return ""
+	}
+	coordinates := make([]string, len(points))
+	for i, point := range points {
+		coordinates[i] = strconv.FormatFloat(point[0], 'f', -1, 64) + " " + strconv.FormatFloat(point[1], 'f', -1, 64)
+	}
+	return "POLYGON((" + strings.Join(coordinates, ", ") + "))"
+}
+
+func scanDeliveryZone(row rowScanner, zone *models.DeliveryZone) error {
+	var area string
+	err := row.Scan(&zone.RestaurantID, &zone.Type, &area, &zone.Radius, &zone.CreatedAt, &zone.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	zone.Polygon = nil
+	if area == "" {
+		return nil
+	}
+	// The zones have a single ring, there are no holes in them
+	var geoJSON struct {
+		Coordinates [][][]float64 `json:"coordinates"`
+	}
+	err = json.Unmarshal([]byte(area), &geoJSON)
+	if err != nil {
+		return err
+	}
+	if len(geoJSON.Coordinates) > 0 {
+		zone.Polygon = geoJSON.Coordinates[0]
+	}
+	return nil
+}
+
+func scanRestaurantClosure(row rowScanner, closure *models.RestaurantClosure) error {
+	return row.Scan(&closure.ID, &closure.RestaurantID, &closure.StartsAt, &closure.EndsAt, &closure.Reason, &closure.CreatedAt)
+}
+
+func scanRestaurantMember(row rowScanner, member *models.RestaurantMember) error {
+	return row.Scan(&member.RestaurantID, &member.UID, &member.Role, &member.CreatedAt, &member.UpdatedAt)
+}
+
+func scanRestaurantInvite(row rowScanner, invite *models.RestaurantInvite) error {
+	return row.Scan(&invite.ID, &invite.RestaurantID, &invite.Email, &invite.Role, &invite.AcceptedBy, &invite.AcceptedAt, &invite.ExpiresAt, &invite.CreatedAt, &invite.UpdatedAt)
+}
+
+func scanRestaurantDriverPreference(row rowScanner, preference *models.RestaurantDriverPreference) error {
+	return row.Scan(&preference.RestaurantID, &preference.UserID, &preference.Preference, &preference.Note, &preference.CreatedAt, &preference.UpdatedAt)
+}
+
+// scanRestaurant scans the restaurantColumns into the restaurant, and the columns selected after them into extra
+func scanRestaurant(row rowScanner, restaurant *models.Restaurant, extra ...any) error {
+	return row.Scan(append([]any{&restaurant.ID, &restaurant.Name, &restaurant.Longitude, &restaurant.Latitude, &restaurant.LocationDescription, &restaurant.PhoneNumber, &restaurant.LogoURL, &restaurant.DefaultVehicle, &restaurant.IsPaused, &restaurant.CreatedAt, &restaurant.UpdatedAt}, extra...)...)
+}
@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -121,7 +123,14 @@ func TestRestaurantRepository_UpdateRestaurant(t *testing.T) {
 	restaurant.Longitude = 14.955987
 	restaurant.Latitude = 76.5945667
 
-	updatedRestaurant, err := restaurantRepo.UpdateRestaurant(restaurant)
+	updatedRestaurant, err := restaurantRepo.UpdateRestaurant(restaurant.ID, &models.RestaurantPatch{
+		Name:                &restaurant.Name,
+		LocationDescription: &restaurant.LocationDescription,
+		PhoneNumber:         &restaurant.PhoneNumber,
+		LogoURL:             &restaurant.LogoURL,
+		Longitude:           &restaurant.Longitude,
+		Latitude:            &restaurant.Latitude,
+	})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedRestaurant)
@@ -132,4 +141,262 @@ func TestRestaurantRepository_UpdateRestaurant(t *testing.T) {
 	assert.Equal(t, restaurant.Name, updatedRestaurant.Name)
 	assert.Equal(t, restaurant.PhoneNumber, updatedRestaurant.PhoneNumber)
 	assert.Equal(t, restaurant.LocationDescription, updatedRestaurant.LocationDescription)
+
+	// The fields left out of the patch keep their value
+	defaultVehicle := models.VehicleTypeCar
+	updatedRestaurant, err = restaurantRepo.UpdateRestaurant(restaurant.ID, &models.RestaurantPatch{DefaultVehicle: &defaultVehicle})
+	assert.NoError(t, err)
+	assert.Equal(t, models.VehicleTypeCar, updatedRestaurant.DefaultVehicle)
+	assert.Equal(t, restaurant.Name, updatedRestaurant.Name)
+	assert.Equal(t, restaurant.Longitude, updatedRestaurant.Longitude)
+	assert.Equal(t, restaurant.Latitude, updatedRestaurant.Latitude)
+
+	// An empty default vehicle removes it
+	noDefaultVehicle := ""
+	updatedRestaurant, err = restaurantRepo.UpdateRestaurant(restaurant.ID, &models.RestaurantPatch{DefaultVehicle: &noDefaultVehicle})
+	assert.NoError(t, err)
+	assert.Equal(t, "", updatedRestaurant.DefaultVehicle)
+
+	_, err = restaurantRepo.UpdateRestaurant("nonexistentrestaurant", &models.RestaurantPatch{Name: &restaurant.Name})
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
+func TestRestaurantRepository_RestaurantDevices(t *testing.T) {
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	device := &models.RestaurantDevice{
+		RestaurantID: "restaurant1", // Restaurant from the seed
+		FCMToken:     "restaurant1-device-token",
+		Platform:     "WEB",
+	}
+
+	createdDevice, err := restaurantRepo.CreateRestaurantDevice(device)
+	assert.NoError(t, err)
+	assert.NotNil(t, createdDevice)
+	assert.Equal(t, "restaurant1", createdDevice.RestaurantID)
+	assert.Equal(t, "WEB", createdDevice.Platform)
+
+	// Registering the same token for another restaurant moves the device instead of failing
+	movedDevice, err := restaurantRepo.CreateRestaurantDevice(&models.RestaurantDevice{
+		RestaurantID: "restaurant2",
+		FCMToken:     "restaurant1-device-token",
+		Platform:     "ANDROID",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, createdDevice.ID, movedDevice.ID)
+	assert.Equal(t, "restaurant2", movedDevice.RestaurantID)
+
+	devices, err := restaurantRepo.GetRestaurantDevices("restaurant2")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, devices)
+
+	// Only the owning restaurant can delete the device
+	err = restaurantRepo.DeleteRestaurantDevice(movedDevice.ID, "restaurant1")
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	err = restaurantRepo.DeleteRestaurantDevice(movedDevice.ID, "restaurant2")
+	assert.NoError(t, err)
+}
+
+func TestRestaurantRepository_Members(t *testing.T) {
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	restaurant := &models.Restaurant{
+		ID:                  "restaurantwithstaff",
+		Longitude:           12.9715987,
+		Latitude:            77.5945667,
+		Name:                "Test Restaurant With Staff",
+		PhoneNumber:         "4245127788",
+		LocationDescription: "Test Location",
+	}
+	_, err := restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// The account that creates the restaurant is its owner
+	owner, err := restaurantRepo.GetRestaurantMember(restaurant.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, restaurant.ID, owner.RestaurantID)
+	assert.Equal(t, models.RestaurantMemberRoleOwner, owner.Role)
+
+	invite, err := restaurantRepo.CreateRestaurantInvite(&models.RestaurantInvite{
+		RestaurantID: restaurant.ID,
+		Email:        "Cashier@Example.com",
+		Role:         models.RestaurantMemberRoleCashier,
+	}, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "cashier@example.com", invite.Email)
+	assert.Nil(t, invite.AcceptedBy)
+
+	invites, err := restaurantRepo.GetRestaurantInvitesByEmail("cashier@example.com")
+	assert.NoError(t, err)
+	assert.Len(t, invites, 1)
+
+	// Only the email the invite was sent to can accept it
+	_, err = restaurantRepo.AcceptRestaurantInvite(invite.ID, "someone@example.com", "cashier1")
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// The owner already works for a restaurant
+	_, err = restaurantRepo.AcceptRestaurantInvite(invite.ID, "cashier@example.com", restaurant.ID)
+	assert.Equal(t, utils.ErrAlreadyMember, err)
+
+	member, err := restaurantRepo.AcceptRestaurantInvite(invite.ID, "cashier@example.com", "cashier1")
+	assert.NoError(t, err)
+	assert.Equal(t, restaurant.ID, member.RestaurantID)
+	assert.Equal(t, models.RestaurantMemberRoleCashier, member.Role)
+
+	// Accepted invites aren't pending anymore
+	invites, err = restaurantRepo.GetRestaurantInvites(restaurant.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, invites)
+	_, err = restaurantRepo.AcceptRestaurantInvite(invite.ID, "cashier@example.com", "cashier2")
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	members, err := restaurantRepo.GetRestaurantMembers(restaurant.ID)
+	assert.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	// Expired invites can't be accepted
+	expiredInvite, err := restaurantRepo.CreateRestaurantInvite(&models.RestaurantInvite{
+		RestaurantID: restaurant.ID,
+		Email:        "manager@example.com",
+		Role:         models.RestaurantMemberRoleManager,
+	}, -time.Hour)
+	assert.NoError(t, err)
+	_, err = restaurantRepo.AcceptRestaurantInvite(expiredInvite.ID, "manager@example.com", "manager1")
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// The owner can't be removed
+	err = restaurantRepo.DeleteRestaurantMember(restaurant.ID, restaurant.ID)
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	err = restaurantRepo.DeleteRestaurantMember(restaurant.ID, "cashier1")
+	assert.NoError(t, err)
+	_, err = restaurantRepo.GetRestaurantMember("cashier1")
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
+func TestRestaurantRepository_GetRestaurantAvailability(t *testing.T) {
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	restaurant := &models.Restaurant{
+		ID:                  "hoursrestaurant",
+		Longitude:           21.4213234,
+		Latitude:            39.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Hours",
+		PhoneNumber:         "427536423411",
+		LocationDescription: "Test Location",
+	}
+	_, err := restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// Restaurants without opening hours are open at any time
+	availability, err := restaurantRepo.GetRestaurantAvailability(restaurant.ID)
+	assert.NoError(t, err)
+	assert.True(t, availability.IsOpen)
+
+	// A restaurant whose only opening period is on another day is closed
+	otherDay := (int(time.Now().UTC().Weekday()) + 3) % 7
+	openingHours, err := restaurantRepo.ReplaceRestaurantOpeningHours(restaurant.ID, &models.RestaurantOpeningHours{
+		Timezone: "UTC",
+		Periods:  []*models.OpeningPeriod{{Weekday: otherDay, OpenTime: "00:00", CloseTime: "23:59"}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, openingHours.Periods, 1)
+	assert.Equal(t, "23:59", openingHours.Periods[0].CloseTime)
+
+	availability, err = restaurantRepo.GetRestaurantAvailability(restaurant.ID)
+	assert.NoError(t, err)
+	assert.False(t, availability.IsOpen)
+	assert.Equal(t, models.ClosedReasonOutsideOpeningHours, availability.ClosedReason)
+
+	// Opening every day opens the restaurant again
+	periods := []*models.OpeningPeriod{}
+	for weekday := 0; weekday < 7; weekday++ {
+		periods = append(periods, &models.OpeningPeriod{Weekday: weekday, OpenTime: "00:00", CloseTime: "23:59"})
+	}
+	_, err = restaurantRepo.ReplaceRestaurantOpeningHours(restaurant.ID, &models.RestaurantOpeningHours{Timezone: "UTC", Periods: periods})
+	assert.NoError(t, err)
+
+	availability, err = restaurantRepo.GetRestaurantAvailability(restaurant.ID)
+	assert.NoError(t, err)
+	assert.True(t, availability.IsOpen)
+
+	// An overnight period that opened yesterday is still open today
+	yesterday := (int(time.Now().UTC().Weekday()) + 6) % 7
+	openingHours, err = restaurantRepo.ReplaceRestaurantOpeningHours(restaurant.ID, &models.RestaurantOpeningHours{
+		Timezone: "UTC",
+		Periods:  []*models.OpeningPeriod{{Weekday: yesterday, OpenTime: "23:59", CloseTime: "23:58"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "23:58", openingHours.Periods[0].CloseTime)
+
+	availability, err = restaurantRepo.GetRestaurantAvailability(restaurant.ID)
+	assert.NoError(t, err)
+	assert.True(t, availability.IsOpen)
+
+	_, err = restaurantRepo.ReplaceRestaurantOpeningHours(restaurant.ID, &models.RestaurantOpeningHours{Timezone: "UTC", Periods: periods})
+	assert.NoError(t, err)
+
+	// Closures override the opening hours
+	closure, err := restaurantRepo.CreateRestaurantClosure(&models.RestaurantClosure{RestaurantID: restaurant.ID, StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour), Reason: "Holiday"})
+	assert.NoError(t, err)
+
+	availability, err = restaurantRepo.GetRestaurantAvailability(restaurant.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClosedReasonClosure, availability.ClosedReason)
+
+	closures, err := restaurantRepo.GetRestaurantClosures(restaurant.ID)
+	assert.NoError(t, err)
+	assert.Len(t, closures, 1)
+
+	err = restaurantRepo.DeleteRestaurantClosure(closure.ID, restaurant.ID)
+	assert.NoError(t, err)
+
+	// Pausing closes the restaurant until it is resumed
+	isPaused := true
+	updatedRestaurant, err := restaurantRepo.UpdateRestaurant(restaurant.ID, &models.RestaurantPatch{IsPaused: &isPaused})
+	assert.NoError(t, err)
+	assert.True(t, updatedRestaurant.IsPaused)
+
+	availability, err = restaurantRepo.GetRestaurantAvailability(restaurant.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClosedReasonPaused, availability.ClosedReason)
+
+	_, err = restaurantRepo.GetRestaurantAvailability("nonexistentrestaurant")
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
+func TestRestaurantRepository_SearchNearbyRestaurants(t *testing.T) {
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	// The restaurants are about 0, 1.1 and 3.3 kilometers north of the point searched around
+	for i, latitude := range []float64{10, 10.01, 10.03} {
+		_, err := restaurantRepo.CreateRestaurant(&models.Restaurant{
+			ID:                  fmt.Sprintf("nearbyrestaurant%d", i),
+			Longitude:           60,
+			Latitude:            latitude,
+			LogoURL:             "https://www.google.com",
+			Name:                fmt.Sprintf("Test Restaurant Nearby %d", i),
+			PhoneNumber:         fmt.Sprintf("42753642350%d", i),
+			LocationDescription: "Test Location",
+		})
+		assert.NoError(t, err)
+	}
+
+	restaurants, total, err := restaurantRepo.SearchNearbyRestaurants(60, 10, 2000, models.Pagination{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, restaurants, 2)
+	assert.Equal(t, "nearbyrestaurant0", restaurants[0].ID)
+	assert.Equal(t, "nearbyrestaurant1", restaurants[1].ID)
+	assert.InDelta(t, 0, restaurants[0].Distance, 1)
+	assert.InDelta(t, 1106, restaurants[1].Distance, 10)
+
+	// The pages go on from the closest restaurant
+	restaurants, total, err = restaurantRepo.SearchNearbyRestaurants(60, 10, 5000, models.Pagination{Limit: 1, Offset: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, restaurants, 1)
+	assert.Equal(t, "nearbyrestaurant2", restaurants[0].ID)
 }
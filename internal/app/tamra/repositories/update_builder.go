@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+)
+
+// updateBuilder builds the SET clause of an UPDATE statement from the fields of a patch that are set,
+// numbering the placeholders of the arguments as it goes
+type updateBuilder struct {
+	assignments []string
+	args        []any
+}
+
+// set assigns a value to a column
+func (b *updateBuilder) set(column string, value any) {
+	b.setExpression(column, "?", value)
+}
+
+// setExpression assigns an SQL expression to a column. Every ? in the expression is replaced by the placeholder of the next value
+func (b *updateBuilder) setExpression(column string, expression string, values ...any) {
+	for _, value := range values {
+		b.args = append(b.args, value)
+		expression = strings.Replace(expression, "?", fmt.Sprintf("$%d", len(b.args)), 1)
+	}
+	b.assignments = append(b.assignments, column+" = "+expression)
+}
+
+// build returns the UPDATE statement and its arguments. The row is matched by its id, which is passed as the last argument
+func (b *updateBuilder) build(table string, id any, returning string) (string, []any) {
+	args := append(b.args, id)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d RETURNING %s", table, strings.Join(b.assignments, ", "), len(args), returning)
+	return query, args
+}
@@ -4,6 +4,9 @@ import (
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 //? Define generic error messages as the errors shouldn't be tied to the repository implementation
@@ -20,16 +23,61 @@ type UserRepository interface {
 	CreateUser(user *models.User) (*models.User, error)
 	// GetUser returns a user by its ID
 	GetUser(userId string) (*models.User, error)
-	// UpdateUser updates a user
-	UpdateUser(user *models.User) (*models.User, error)
+	// UpdateUser updates the fields of a user that are set in the patch
+	UpdateUser(userID string, patch *models.UserPatch) (*models.User, error)
+	// UpdateUserLocation updates the location of a user recorded age ago, unless the stored location was recorded less than minInterval before it.
+	// It returns the new location_updated_at, or nil if the update was throttled
+	UpdateUserLocation(userID string, longitude float64, latitude float64, age time.Duration, minInterval time.Duration) (*time.Time, error)
 	// Retrieve the user that last received an order
-	GetUserToReceiveOrder(restaurantID string) (*models.User, error)
+	GetUserToReceiveOrder(restaurantID string, options DispatchOptions) (*models.User, error)
+	// AssignOrderToNextUser picks the driver like GetUserToReceiveOrder and creates the order for them in a single transaction,
+	// so concurrent dispatches can't give a driver more orders than they can handle. It fails with utils.ErrNotFound if no driver can receive the order
+	AssignOrderToNextUser(order *models.Order, options DispatchOptions) (*models.Order, *models.User, error)
 	// GetUsers returns a list of users
 	GetUsers() ([]*models.User, error)
+	// GetUsersByStatus returns the users with the given status, the ones waiting the longest first
+	GetUsersByStatus(status string) ([]*models.User, error)
+	// SearchUsers returns a page of the users matching the filter, the newest first, and how many match it
+	SearchUsers(filter *models.UserFilter) ([]*models.User, int, error)
+	// UpdateUserStatus changes the status of a user
+	UpdateUserStatus(userID string, status string) (*models.User, error)
 	// DeleteUser deletes a user
 	DeleteUser(id string) error
+	// GetUserSchedule returns the timezone and weekly shifts of a user
+	GetUserSchedule(userID string) (*models.DriverSchedule, error)
+	// ReplaceUserSchedule replaces the timezone and all the weekly shifts of a user
+	ReplaceUserSchedule(userID string, schedule *models.DriverSchedule) (*models.DriverSchedule, error)
+	// CreateUserTimeOff creates a time off period for a user
+	CreateUserTimeOff(timeOff *models.DriverTimeOff) (*models.DriverTimeOff, error)
+	// GetUserTimeOffs returns the current and upcoming time off periods of a user
+	GetUserTimeOffs(userID string) ([]*models.DriverTimeOff, error)
+	// DeleteUserTimeOff deletes a time off period that belongs to a user
+	DeleteUserTimeOff(id int, userID string) error
+	// CreateUserPenalty creates a penalty whose cooldown ends after the given duration
+	CreateUserPenalty(penalty *models.DriverPenalty, cooldown time.Duration) (*models.DriverPenalty, error)
+	// CountUserPenaltiesSince returns how many penalties a user got within the given window
+	CountUserPenaltiesSince(userID string, window time.Duration) (int, error)
+	// GetActiveUserPenalty returns the penalty of a user whose cooldown ends last, if it isn't over
+	GetActiveUserPenalty(userID string) (*models.DriverPenalty, error)
+	// GetUserStats returns the performance of a user over the last 30 days
+	GetUserStats(userID string) (*models.DriverStats, error)
+}
+
+// DispatchOptions tweak how GetUserToReceiveOrder picks a driver
+type DispatchOptions struct {
+	// RankByScore picks the most reliable drivers first. Scores are rounded to one decimal
+	// so drivers with a similar score still take turns by last_order_received
+	RankByScore bool
+	// RequiredVehicle only picks drivers with this vehicle or a larger one, see models.VehicleTypes. Empty means any vehicle
+	RequiredVehicle string
 }
 
+// driverPenaltyColumns are the columns selected for every penalty, in the order scanDriverPenalty expects them
+const driverPenaltyColumns = "id, user_id, order_id, reason, offense_number, cooldown_until, created_at"
+
+// userColumns are the columns selected for every user, in the order scanUser expects them
+const userColumns = "id, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, is_active, phone, radius, fcm_token, last_order_received, location_updated_at, timezone, max_concurrent_orders, status, COALESCE(vehicle_type, ''), COALESCE(id_photo_url, ''), created_at, updated_at"
+
 type UserRepositoryImpl struct {
 	db *sql.DB
 }
@@ -39,14 +87,15 @@ func NewUserRepository(db *sql.DB) UserRepository {
 }
 
 func (r *UserRepositoryImpl) CreateUser(user *models.User) (*models.User, error) {
-	const query = "INSERT INTO users (id, location, is_active, phone, radius, fcm_token ,last_order_received, created_at, updated_at) VALUES ($1, ST_SetSRID(ST_MakePoint($2, $3), 4326), $4, $5, $6, $7, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING id, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, is_active, phone, radius, fcm_token, last_order_received, created_at, updated_at"
-	err := r.db.QueryRow(query, user.ID, user.Longitude, user.Latitude, user.IsActive, user.Phone, user.Radius, user.FCMToken).Scan(&user.ID, &user.Longitude, &user.Latitude, &user.IsActive, &user.Phone, &user.Radius, &user.FCMToken, &user.LastOrderReceived, &user.CreatedAt, &user.UpdatedAt)
+	// A max_concurrent_orders of 0 means it wasn't set, so the default is used. The status is left to its default so new drivers wait for an approval
+	const query = "INSERT INTO users (id, location, is_active, phone, radius, fcm_token, max_concurrent_orders, vehicle_type, id_photo_url, last_order_received, location_updated_at, created_at, updated_at) VALUES ($1, ST_SetSRID(ST_MakePoint($2, $3), 4326), $4, $5, $6, $7, COALESCE(NULLIF($8, 0), 1), NULLIF($9, ''), NULLIF($10, ''), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING " + userColumns
+	err := scanUser(r.db.QueryRow(query, user.ID, user.Longitude, user.Latitude, user.IsActive, user.Phone, user.Radius, user.FCMToken, user.MaxConcurrentOrders, user.VehicleType, user.IDPhotoURL), user)
 	return user, err
 }
 
 func (r *UserRepositoryImpl) GetUser(userId string) (*models.User, error) {
 	user := &models.User{}
-	err := r.db.QueryRow("SELECT id, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, is_active, phone, radius, fcm_token, last_order_received, created_at, updated_at FROM users WHERE id = $1", userId).Scan(&user.ID, &user.Longitude, &user.Latitude, &user.IsActive, &user.Phone, &user.Radius, &user.FCMToken, &user.LastOrderReceived, &user.CreatedAt, &user.UpdatedAt)
+	err := scanUser(r.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = $1", userId), user)
 	// Return a custom error if the user is not found so that the service or handler can handle it.
 	// In this case we want to return a 404 status code
 	if err == sql.ErrNoRows {
@@ -57,7 +106,7 @@ func (r *UserRepositoryImpl) GetUser(userId string) (*models.User, error) {
 }
 
 func (r *UserRepositoryImpl) GetUsers() ([]*models.User, error) {
-	rows, err := r.db.Query("SELECT id, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, is_active, phone, radius, fcm_token, last_order_received, created_at, updated_at FROM users")
+	rows, err := r.db.Query("SELECT " + userColumns + " FROM users")
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +115,7 @@ func (r *UserRepositoryImpl) GetUsers() ([]*models.User, error) {
 	users := []*models.User{}
 	for rows.Next() {
 		user := &models.User{}
-		err := rows.Scan(&user.ID, &user.Longitude, &user.Latitude, &user.IsActive, &user.Phone, &user.Radius, &user.FCMToken, &user.LastOrderReceived, &user.CreatedAt, &user.UpdatedAt)
+		err := scanUser(rows, user)
 		if err != nil {
 			return nil, err
 		}
@@ -80,39 +129,451 @@ func (r *UserRepositoryImpl) GetUsers() ([]*models.User, error) {
 	return users, nil
 }
 
-func (r *UserRepositoryImpl) UpdateUser(user *models.User) (*models.User, error) {
-	const query = "UPDATE users SET location = ST_SetSRID(ST_MakePoint($1, $2), 4326), is_active = $3, phone = $4, radius = $5, fcm_token = $6, last_order_received = $7, updated_at = CLOCK_TIMESTAMP() WHERE id = $8 RETURNING id, ST_X(location::geometry) as longitude, ST_Y(location::geometry) as latitude, is_active, phone, radius, fcm_token, last_order_received, created_at, updated_at"
-	err := r.db.QueryRow(query, user.Longitude, user.Latitude, user.IsActive, user.Phone, user.Radius, user.FCMToken, user.LastOrderReceived, user.ID).Scan(&user.ID, &user.Longitude, &user.Latitude, &user.IsActive, &user.Phone, &user.Radius, &user.FCMToken, &user.LastOrderReceived, &user.CreatedAt, &user.UpdatedAt)
+func (r *UserRepositoryImpl) GetUsersByStatus(status string) ([]*models.User, error) {
+	rows, err := r.db.Query("SELECT "+userColumns+" FROM users WHERE status = $1 ORDER BY created_at", status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user := &models.User{}
+		err := scanUser(rows, user)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepositoryImpl) SearchUsers(filter *models.UserFilter) ([]*models.User, int, error) {
+	conditions := &filterBuilder{}
+	if filter.Search != "" {
+		conditions.where("(id ILIKE ? OR phone ILIKE ?)", "%"+filter.Search+"%", "%"+filter.Search+"%")
+	}
+	if filter.Status != "" {
+		conditions.where("status = ?", filter.Status)
+	}
+	if filter.IsActive != nil {
+		conditions.where("is_active = ?", *filter.IsActive)
+	}
+	if filter.VehicleType != "" {
+		conditions.where("vehicle_type = ?", filter.VehicleType)
+	}
+
+	var total int
+	countQuery, countArgs := conditions.count("users")
+	err := r.db.QueryRow(countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query, args := conditions.selectPage("users", userColumns, "created_at DESC, id", filter.Pagination)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user := &models.User{}
+		err := scanUser(rows, user)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+func (r *UserRepositoryImpl) UpdateUserStatus(userID string, status string) (*models.User, error) {
+	user := &models.User{}
+	err := scanUser(r.db.QueryRow("UPDATE users SET status = $1, updated_at = CLOCK_TIMESTAMP() WHERE id = $2 RETURNING "+userColumns, status, userID), user)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return user, err
+}
+
+func (r *UserRepositoryImpl) UpdateUser(userID string, patch *models.UserPatch) (*models.User, error) {
+	// Only the columns of the fields that are set are written, so e.g. bumping last_order_received doesn't touch the location.
+	// The status is otherwise only changed through UpdateUserStatus
+	update := &updateBuilder{}
+	if patch.VehicleType != nil || patch.IDPhotoURL != nil {
+		// An approval covers the vehicle and the ID photo, so changing either sends an approved driver back to approval.
+		// The expressions of the SET clause see the values before the update
+		update.setExpression("status", "CASE WHEN status = ? AND (COALESCE(?, vehicle_type) IS DISTINCT FROM vehicle_type OR COALESCE(?, id_photo_url) IS DISTINCT FROM id_photo_url) THEN ? ELSE status END",
+			models.UserStatusApproved, patch.VehicleType, patch.IDPhotoURL, models.UserStatusPendingApproval)
+	}
+	if patch.Longitude != nil && patch.Latitude != nil {
+		update.setExpression("location", "ST_SetSRID(ST_MakePoint(?, ?), 4326)", *patch.Longitude, *patch.Latitude)
+		update.setExpression("location_updated_at", "CLOCK_TIMESTAMP()")
+	}
+	if patch.IsActive != nil {
+		update.set("is_active", *patch.IsActive)
+	}
+	if patch.Phone != nil {
+		update.set("phone", *patch.Phone)
+	}
+	if patch.Radius != nil {
+		update.set("radius", *patch.Radius)
+	}
+	if patch.FCMToken != nil {
+		update.set("fcm_token", *patch.FCMToken)
+	}
+	if patch.MaxConcurrentOrders != nil {
+		update.set("max_concurrent_orders", *patch.MaxConcurrentOrders)
+	}
+	if patch.VehicleType != nil {
+		update.set("vehicle_type", *patch.VehicleType)
+	}
+	if patch.IDPhotoURL != nil {
+		update.set("id_photo_url", *patch.IDPhotoURL)
+	}
+	if patch.LastOrderReceived != nil {
+		update.set("last_order_received", *patch.LastOrderReceived)
+	}
+	update.setExpression("updated_at", "CLOCK_TIMESTAMP()")
+
+	query, args := update.build("users", userID, userColumns)
+	user := &models.User{}
+	err := scanUser(r.db.QueryRow(query, args...), user)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
 	return user, err
 }
 
+func (r *UserRepositoryImpl) UpdateUserLocation(userID string, longitude float64, latitude float64, age time.Duration, minInterval time.Duration) (*time.Time, error) {
+	// The times are computed from the database clock like the other timestamps, the client only tells us how old the point is.
+	// The condition also drops points older than the stored location, e.g. buffered points sent after a fresher one
+	const query = `
+	UPDATE users SET location = ST_SetSRID(ST_MakePoint($1, $2), 4326), location_updated_at = CLOCK_TIMESTAMP() - $3::float8 * INTERVAL '1 second'
+	WHERE id = $4
+	AND (location_updated_at IS NULL OR location_updated_at <= CLOCK_TIMESTAMP() - ($3::float8 + $5::float8) * INTERVAL '1 second')
+	RETURNING location_updated_at
+	`
+	var locationUpdatedAt time.Time
+	err := r.db.QueryRow(query, longitude, latitude, age.Seconds(), userID, minInterval.Seconds()).Scan(&locationUpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &locationUpdatedAt, nil
+}
+
 // GetUserToReceiveOrder retrieves the user that last received an order.
 // Newly created users will be last in line to receive an order as
 // last_order_recieved is set to the current time when the user is created
 // First we get the restaurant location using the restaurantID
-// Then we get the users whose radius covers the restaurant location
+// Then we get the users whose radius covers the restaurant location and who are inside its delivery zone
 // Then we get the user that last received an order
 // Then we return the user
-func (r *UserRepositoryImpl) GetUserToReceiveOrder(restaurantID string) (*models.User, error) {
-	// TODO: measure the performance of this query and see if it can be optimized
-	const query = `
-	SELECT u.id, ST_X(u.location::geometry) as longitude, ST_Y(u.location::geometry) as latitude, u.is_active, u.phone, u.radius, u.fcm_token, u.last_order_received, u.created_at, u.updated_at
-	FROM users u
-	JOIN restaurants r ON ST_DWithin(u.location, r.location, u.radius)
-	WHERE r.id = $1
-	AND u.is_active = true
-	ORDER BY u.last_order_received
-	LIMIT 1
-	`
+func (r *UserRepositoryImpl) GetUserToReceiveOrder(restaurantID string, options DispatchOptions) (*models.User, error) {
 	user := &models.User{}
-	err := r.db.QueryRow(query, restaurantID).Scan(&user.ID, &user.Longitude, &user.Latitude, &user.IsActive, &user.Phone, &user.Radius, &user.FCMToken, &user.LastOrderReceived, &user.CreatedAt, &user.UpdatedAt)
+	err := scanUser(r.db.QueryRow(userToReceiveOrderQuery(""), restaurantID, options.RankByScore, options.RequiredVehicle, pq.Array(models.VehicleTypes)), user)
 	if err == sql.ErrNoRows {
 		return nil, utils.ErrNotFound
 	}
 	return user, err
 }
 
+func (r *UserRepositoryImpl) AssignOrderToNextUser(order *models.Order, options DispatchOptions) (*models.Order, *models.User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// The driver stays locked until the order is committed, so a concurrent dispatch skips the driver instead of waiting
+	// and two dispatches can't both give the last free slot of a driver away
+	user := &models.User{}
+	for attempt := 0; ; attempt++ {
+		err = scanUser(tx.QueryRow(userToReceiveOrderQuery("FOR UPDATE OF u SKIP LOCKED"), order.RestaurantID, options.RankByScore, options.RequiredVehicle, pq.Array(models.VehicleTypes)), user)
+		if err == sql.ErrNoRows {
+			return nil, nil, utils.ErrNotFound
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The selection may have counted the orders before a dispatch that just committed, so the orders are counted again now
+		// that the driver is locked. A driver who is full isn't selected again since the next selection counts them as well
+		var hasCapacity bool
+		err = tx.QueryRow("SELECT (SELECT COUNT(*) FROM orders WHERE user_id = $1 AND state IN ('PENDING', 'ACCEPTED')) < max_concurrent_orders FROM users WHERE id = $1", user.ID).Scan(&hasCapacity)
+		if err != nil {
+			return nil, nil, err
+		}
+		if hasCapacity {
+			break
+		}
+		if attempt == 2 {
+			return nil, nil, utils.ErrNotFound
+		}
+	}
+
+	order.UserID = user.ID
+	// The state is set to "PENDING" by default. That's why it's not included in the query
+	const insertQuery = "INSERT INTO orders (user_id, restaurant_id, code, description, required_vehicle, created_at, updated_at) VALUES ($1, $2, $3, $4, NULLIF($5, ''), CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING " + orderColumns
+	err = scanOrder(tx.QueryRow(insertQuery, order.UserID, order.RestaurantID, order.Code, order.Description, order.RequiredVehicle), order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The driver goes to the back of the line
+	err = tx.QueryRow("UPDATE users SET last_order_received = $1, updated_at = CLOCK_TIMESTAMP() WHERE id = $2 RETURNING last_order_received", order.CreatedAt, user.ID).Scan(&user.LastOrderReceived)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, user, nil
+}
+
+// userToReceiveOrderQuery selects the driver who receives the next order of a restaurant.
+// The locking clause applies to the selected driver, e.g. to lock it while the order is created
+func userToReceiveOrderQuery(lockingClause string) string {
+	// TODO: measure the performance of this query and see if it can be optimized
+	return `
+	SELECT ` + userColumns + ` FROM (
+		SELECT u.*
+		FROM users u
+		JOIN restaurants r ON ST_DWithin(u.location, r.location, u.radius)
+		LEFT JOIN driver_stats ds ON ds.user_id = u.id
+		LEFT JOIN restaurant_driver_preferences dp ON dp.restaurant_id = r.id AND dp.user_id = u.id
+		LEFT JOIN restaurant_delivery_zones z ON z.restaurant_id = r.id
+		WHERE r.id = $1
+		-- Besides covering the restaurant with their radius, the drivers have to be inside the delivery zone of the restaurant if it has one
+		AND (
+			z.restaurant_id IS NULL
+			OR (z.zone_type = 'POLYGON' AND ST_Covers(z.area, u.location))
+			OR (z.zone_type = 'RADIUS' AND ST_DWithin(u.location, r.location, z.radius))
+		)
+		AND u.is_active = true
+		AND u.status = 'APPROVED'
+		AND dp.preference IS DISTINCT FROM 'BLOCKED'
+		-- Drivers whose vehicle is unknown can't be trusted with an order that requires one
+		AND ($3 = '' OR array_position($4::text[], u.vehicle_type) >= array_position($4::text[], $3))
+		-- Drivers without a schedule are available at any time, the others only during one of their shifts
+		AND (
+			NOT EXISTS (SELECT 1 FROM driver_schedules s WHERE s.user_id = u.id)
+			OR EXISTS (
+				SELECT 1 FROM driver_schedules s
+				WHERE s.user_id = u.id
+				AND (
+					(
+						s.weekday = EXTRACT(DOW FROM CURRENT_TIMESTAMP AT TIME ZONE u.timezone)
+						AND (CURRENT_TIMESTAMP AT TIME ZONE u.timezone)::time >= s.start_time
+						AND ((CURRENT_TIMESTAMP AT TIME ZONE u.timezone)::time < s.end_time OR s.end_time < s.start_time)
+					)
+					-- Overnight shifts end on the day after their weekday
+					OR (
+						s.end_time < s.start_time
+						AND s.weekday = EXTRACT(DOW FROM (CURRENT_TIMESTAMP AT TIME ZONE u.timezone) - INTERVAL '1 day')
+						AND (CURRENT_TIMESTAMP AT TIME ZONE u.timezone)::time < s.end_time
+					)
+				)
+			)
+		)
+		-- Skip the drivers already carrying or deciding on as many orders as they can handle
+		AND (SELECT COUNT(*) FROM orders o WHERE o.user_id = u.id AND o.state IN ('PENDING', 'ACCEPTED')) < u.max_concurrent_orders
+		-- Drivers are available again once the cooldown of their penalties is over
+		AND NOT EXISTS (SELECT 1 FROM driver_penalties p WHERE p.user_id = u.id AND p.cooldown_until > CURRENT_TIMESTAMP)
+		AND NOT EXISTS (SELECT 1 FROM driver_time_off t WHERE t.user_id = u.id AND CURRENT_TIMESTAMP >= t.starts_at AND CURRENT_TIMESTAMP < t.ends_at)
+		-- The preferred drivers of the restaurant come first. Drivers without any order in the stats window have the score of a new driver
+		ORDER BY dp.preference IS NOT DISTINCT FROM 'PREFERRED' DESC, CASE WHEN $2 THEN ROUND(COALESCE(ds.score, 1)::numeric, 1) ELSE 0 END DESC, u.last_order_received
+		LIMIT 1
+		` + lockingClause + `
+	) AS users
+	`
+}
+
 func (r *UserRepositoryImpl) DeleteUser(id string) error {
 	_, err := r.db.Exec("DELETE FROM users WHERE id = $1", id)
 	return err
 }
+
+func (r *UserRepositoryImpl) GetUserSchedule(userID string) (*models.DriverSchedule, error) {
+	schedule := &models.DriverSchedule{}
+	err := r.db.QueryRow("SELECT timezone FROM users WHERE id = $1", userID).Scan(&schedule.Timezone)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query("SELECT id, weekday, TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI') FROM driver_schedules WHERE user_id = $1 ORDER BY weekday, start_time", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedule.Shifts = []*models.DriverShift{}
+	for rows.Next() {
+		shift := &models.DriverShift{}
+		err := rows.Scan(&shift.ID, &shift.Weekday, &shift.StartTime, &shift.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Shifts = append(schedule.Shifts, shift)
+	}
+
+	return schedule, rows.Err()
+}
+
+func (r *UserRepositoryImpl) ReplaceUserSchedule(userID string, schedule *models.DriverSchedule) (*models.DriverSchedule, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE users SET timezone = $1, updated_at = CLOCK_TIMESTAMP() WHERE id = $2", schedule.Timezone, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRowsAffected(result)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("DELETE FROM driver_schedules WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, shift := range schedule.Shifts {
+		err = tx.QueryRow("INSERT INTO driver_schedules (user_id, weekday, start_time, end_time, created_at, updated_at) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING id", userID, shift.Weekday, shift.StartTime, shift.EndTime).Scan(&shift.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetUserSchedule(userID)
+}
+
+func (r *UserRepositoryImpl) CreateUserTimeOff(timeOff *models.DriverTimeOff) (*models.DriverTimeOff, error) {
+	const query = "INSERT INTO driver_time_off (user_id, starts_at, ends_at, reason, created_at) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP()) RETURNING id, user_id, starts_at, ends_at, reason, created_at"
+	err := r.db.QueryRow(query, timeOff.UserID, timeOff.StartsAt, timeOff.EndsAt, timeOff.Reason).Scan(&timeOff.ID, &timeOff.UserID, &timeOff.StartsAt, &timeOff.EndsAt, &timeOff.Reason, &timeOff.CreatedAt)
+	return timeOff, err
+}
+
+func (r *UserRepositoryImpl) GetUserTimeOffs(userID string) ([]*models.DriverTimeOff, error) {
+	rows, err := r.db.Query("SELECT id, user_id, starts_at, ends_at, reason, created_at FROM driver_time_off WHERE user_id = $1 AND ends_at > CURRENT_TIMESTAMP ORDER BY starts_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timeOffs := []*models.DriverTimeOff{}
+	for rows.Next() {
+		timeOff := &models.DriverTimeOff{}
+		err := rows.Scan(&timeOff.ID, &timeOff.UserID, &timeOff.StartsAt, &timeOff.EndsAt, &timeOff.Reason, &timeOff.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		timeOffs = append(timeOffs, timeOff)
+	}
+
+	return timeOffs, rows.Err()
+}
+
+func (r *UserRepositoryImpl) DeleteUserTimeOff(id int, userID string) error {
+	result, err := r.db.Exec("DELETE FROM driver_time_off WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func (r *UserRepositoryImpl) CreateUserPenalty(penalty *models.DriverPenalty, cooldown time.Duration) (*models.DriverPenalty, error) {
+	const query = "INSERT INTO driver_penalties (user_id, order_id, reason, offense_number, cooldown_until, created_at) VALUES ($1, $2, $3, $4, CLOCK_TIMESTAMP() + $5::float8 * INTERVAL '1 second', CLOCK_TIMESTAMP()) RETURNING " + driverPenaltyColumns
+	err := scanDriverPenalty(r.db.QueryRow(query, penalty.UserID, penalty.OrderID, penalty.Reason, penalty.OffenseNumber, cooldown.Seconds()), penalty)
+	return penalty, err
+}
+
+func (r *UserRepositoryImpl) CountUserPenaltiesSince(userID string, window time.Duration) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM driver_penalties WHERE user_id = $1 AND created_at > CLOCK_TIMESTAMP() - $2::float8 * INTERVAL '1 second'", userID, window.Seconds()).Scan(&count)
+	return count, err
+}
+
+func (r *UserRepositoryImpl) GetActiveUserPenalty(userID string) (*models.DriverPenalty, error) {
+	penalty := &models.DriverPenalty{}
+	err := scanDriverPenalty(r.db.QueryRow("SELECT "+driverPenaltyColumns+" FROM driver_penalties WHERE user_id = $1 AND cooldown_until > CURRENT_TIMESTAMP ORDER BY cooldown_until DESC LIMIT 1", userID), penalty)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return penalty, err
+}
+
+func (r *UserRepositoryImpl) GetUserStats(userID string) (*models.DriverStats, error) {
+	const query = "SELECT offers, acceptances, rejections, expiries, fulfilments, median_seconds_to_accept, acceptance_rate, fulfilment_rate, score FROM driver_stats WHERE user_id = $1"
+	stats := &models.DriverStats{UserID: userID}
+	var medianSecondsToAccept, acceptanceRate, fulfilmentRate sql.NullFloat64
+
+	err := r.db.QueryRow(query, userID).Scan(&stats.Offers, &stats.Acceptances, &stats.Rejections, &stats.Expiries, &stats.Fulfilments, &medianSecondsToAccept, &acceptanceRate, &fulfilmentRate, &stats.Score)
+	// The view has no row for users without orders in the window, which is the same as a new driver
+	if err == sql.ErrNoRows {
+		stats.Score = 1
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if medianSecondsToAccept.Valid {
+		stats.MedianSecondsToAccept = &medianSecondsToAccept.Float64
+	}
+	if acceptanceRate.Valid {
+		stats.AcceptanceRate = &acceptanceRate.Float64
+	}
+	if fulfilmentRate.Valid {
+		stats.FulfilmentRate = &fulfilmentRate.Float64
+	}
+	return stats, nil
+}
+
+func scanDriverPenalty(row rowScanner, penalty *models.DriverPenalty) error {
+	var orderID sql.NullInt64
+
+	err := row.Scan(&penalty.ID, &penalty.UserID, &orderID, &penalty.Reason, &penalty.OffenseNumber, &penalty.CooldownUntil, &penalty.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if orderID.Valid {
+		id := int(orderID.Int64)
+		penalty.OrderID = &id
+	}
+	return nil
+}
+
+// scanUser scans the userColumns into the user
+func scanUser(row rowScanner, user *models.User) error {
+	var locationUpdatedAt sql.NullTime
+
+	err := row.Scan(&user.ID, &user.Longitude, &user.Latitude, &user.IsActive, &user.Phone, &user.Radius, &user.FCMToken, &user.LastOrderReceived, &locationUpdatedAt, &user.Timezone, &user.MaxConcurrentOrders, &user.Status, &user.VehicleType, &user.IDPhotoURL, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if locationUpdatedAt.Valid {
+		user.LocationUpdatedAt = &locationUpdatedAt.Time
+	}
+	return nil
+}
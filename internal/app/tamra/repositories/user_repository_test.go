@@ -3,7 +3,10 @@ package repositories
 import (
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +37,7 @@ func TestUserRepository_CreateUser(t *testing.T) {
 	assert.Equal(t, user.Phone, createdUser.Phone)
 	assert.Equal(t, user.Radius, createdUser.Radius)
 	assert.Equal(t, user.FCMToken, createdUser.FCMToken)
+	assert.Equal(t, models.UserStatusPendingApproval, createdUser.Status)
 }
 
 func TestUserRepository_GetUser(t *testing.T) {
@@ -110,8 +114,9 @@ func TestUserRepository_UpdateUser(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, createdUser)
 
+	// Only the availability is sent, the other fields keep their value
 	createdUser.IsActive = false
-	updatedUser, err := userRepo.UpdateUser(createdUser)
+	updatedUser, err := userRepo.UpdateUser(createdUser.ID, &models.UserPatch{IsActive: &createdUser.IsActive})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedUser)
@@ -122,6 +127,60 @@ func TestUserRepository_UpdateUser(t *testing.T) {
 	assert.Equal(t, createdUser.Phone, updatedUser.Phone)
 	assert.Equal(t, createdUser.Radius, updatedUser.Radius)
 	assert.Equal(t, createdUser.FCMToken, updatedUser.FCMToken)
+	assert.Equal(t, createdUser.LocationUpdatedAt, updatedUser.LocationUpdatedAt)
+
+	// Bumping last_order_received doesn't refresh the location
+	lastOrderReceived := time.Now()
+	updatedUser, err = userRepo.UpdateUser(createdUser.ID, &models.UserPatch{LastOrderReceived: &lastOrderReceived})
+	assert.NoError(t, err)
+	assert.Equal(t, createdUser.LocationUpdatedAt, updatedUser.LocationUpdatedAt)
+
+	longitude, latitude := 13.9715987, 76.5945667
+	updatedUser, err = userRepo.UpdateUser(createdUser.ID, &models.UserPatch{Longitude: &longitude, Latitude: &latitude})
+	assert.NoError(t, err)
+	assert.Equal(t, longitude, updatedUser.Longitude)
+	assert.Equal(t, latitude, updatedUser.Latitude)
+	assert.True(t, updatedUser.LocationUpdatedAt.After(*createdUser.LocationUpdatedAt))
+
+	_, err = userRepo.UpdateUser("nonexistentuser", &models.UserPatch{IsActive: &createdUser.IsActive})
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
+func TestUserRepository_UpdateUserLocation(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+
+	user := &models.User{
+		ID:        "locationupdater",
+		Longitude: 12.9715987,
+		Latitude:  77.5945667,
+		IsActive:  true,
+		Phone:     "4242376499",
+		Radius:    1000,
+		FCMToken:  "locationupdatertoken",
+	}
+
+	_, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+
+	// The location was just set when the user was created, so an update right away is throttled
+	locationUpdatedAt, err := userRepo.UpdateUserLocation(user.ID, 13.0, 77.6, 0, time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, locationUpdatedAt)
+
+	locationUpdatedAt, err = userRepo.UpdateUserLocation(user.ID, 13.0, 77.6, 0, 0)
+	assert.NoError(t, err)
+	assert.NotNil(t, locationUpdatedAt)
+
+	// A point recorded before the stored location is dropped
+	locationUpdatedAt, err = userRepo.UpdateUserLocation(user.ID, 14.0, 78.0, time.Minute, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, locationUpdatedAt)
+
+	updatedUser, err := userRepo.GetUser(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 13.0, updatedUser.Longitude)
+	assert.Equal(t, 77.6, updatedUser.Latitude)
+	assert.NotNil(t, updatedUser.LocationUpdatedAt)
 }
 
 func TestUserRepository_GetUserToReceiveOrder(t *testing.T) {
@@ -163,6 +222,10 @@ func TestUserRepository_GetUserToReceiveOrder(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, createdUser)
 
+	// Only approved drivers receive orders
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
 	createdRestaurantInReach, err := restaurantRepo.CreateRestaurant(restaurantInReach)
 
 	assert.NoError(t, err)
@@ -174,7 +237,7 @@ func TestUserRepository_GetUserToReceiveOrder(t *testing.T) {
 	assert.NotNil(t, createdRestaurantNotInReach)
 
 	// Get the user that should receive the order
-	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurantInReach.ID)
+	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurantInReach.ID, DispatchOptions{})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, userToReceiveOrder)
@@ -187,12 +250,559 @@ func TestUserRepository_GetUserToReceiveOrder(t *testing.T) {
 	assert.Equal(t, user.FCMToken, userToReceiveOrder.FCMToken)
 
 	// Case where no user is in reach and we should get an error of type ErrNotFound
-	_, err = userRepo.GetUserToReceiveOrder(restaurantNotInReach.ID)
+	_, err = userRepo.GetUserToReceiveOrder(restaurantNotInReach.ID, DispatchOptions{})
 
 	assert.Error(t, err)
 	assert.Equal(t, err, utils.ErrNotFound)
 }
 
+func TestUserRepository_GetUserToReceiveOrderSchedule(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	user := &models.User{
+		ID:        "scheduleddriver",
+		Longitude: 21.4213234,
+		Latitude:  39.5945667,
+		IsActive:  true,
+		Phone:     "4246123499",
+		Radius:    100,
+		FCMToken:  "scheduleddrivertoken",
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "scheduledrestaurant",
+		Longitude:           21.4213234,
+		Latitude:            39.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Schedule",
+		PhoneNumber:         "427536423499",
+		LocationDescription: "Test Location",
+	}
+
+	_, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+
+	// Only approved drivers receive orders
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
+	_, err = restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// A driver whose only shift is on another day isn't available
+	otherDay := (int(time.Now().UTC().Weekday()) + 3) % 7
+	schedule, err := userRepo.ReplaceUserSchedule(user.ID, &models.DriverSchedule{
+		Timezone: "UTC",
+		Shifts:   []*models.DriverShift{{Weekday: otherDay, StartTime: "00:00", EndTime: "23:59"}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, schedule.Shifts, 1)
+	assert.Equal(t, "00:00", schedule.Shifts[0].StartTime)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// Working every day makes the driver available again
+	shifts := []*models.DriverShift{}
+	for weekday := 0; weekday < 7; weekday++ {
+		shifts = append(shifts, &models.DriverShift{Weekday: weekday, StartTime: "00:00", EndTime: "23:59"})
+	}
+	_, err = userRepo.ReplaceUserSchedule(user.ID, &models.DriverSchedule{Timezone: "UTC", Shifts: shifts})
+	assert.NoError(t, err)
+
+	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, userToReceiveOrder.ID)
+
+	// An overnight shift that started yesterday is still running today
+	yesterday := (int(time.Now().UTC().Weekday()) + 6) % 7
+	schedule, err = userRepo.ReplaceUserSchedule(user.ID, &models.DriverSchedule{
+		Timezone: "UTC",
+		Shifts:   []*models.DriverShift{{Weekday: yesterday, StartTime: "23:59", EndTime: "23:58"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "23:58", schedule.Shifts[0].EndTime)
+
+	userToReceiveOrder, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, userToReceiveOrder.ID)
+
+	_, err = userRepo.ReplaceUserSchedule(user.ID, &models.DriverSchedule{Timezone: "UTC", Shifts: shifts})
+	assert.NoError(t, err)
+
+	// Time off overrides the schedule
+	timeOff, err := userRepo.CreateUserTimeOff(&models.DriverTimeOff{UserID: user.ID, StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour), Reason: "Sick"})
+	assert.NoError(t, err)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	timeOffs, err := userRepo.GetUserTimeOffs(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, timeOffs, 1)
+
+	err = userRepo.DeleteUserTimeOff(timeOff.ID, user.ID)
+	assert.NoError(t, err)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+}
+
+func TestUserRepository_GetUserToReceiveOrderCapacity(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+	orderRepo := NewOrderRepository(Db)
+
+	user := &models.User{
+		ID:        "capacitydriver",
+		Longitude: 23.4213234,
+		Latitude:  41.5945667,
+		IsActive:  true,
+		Phone:     "4246123498",
+		Radius:    100,
+		FCMToken:  "capacitydrivertoken",
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "capacityrestaurant",
+		Longitude:           23.4213234,
+		Latitude:            41.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Capacity",
+		PhoneNumber:         "427536423498",
+		LocationDescription: "Test Location",
+	}
+
+	createdUser, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, createdUser.MaxConcurrentOrders)
+
+	_, err = restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// Only approved drivers receive orders
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
+	_, err = orderRepo.CreateOrder(&models.Order{UserID: user.ID, RestaurantID: restaurant.ID, Code: "7281934"})
+	assert.NoError(t, err)
+
+	// The driver already has a pending order
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// Leaving the setting out of an update keeps it
+	isActive := true
+	updatedUser, err := userRepo.UpdateUser(createdUser.ID, &models.UserPatch{IsActive: &isActive})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updatedUser.MaxConcurrentOrders)
+
+	maxConcurrentOrders := 2
+	updatedUser, err = userRepo.UpdateUser(createdUser.ID, &models.UserPatch{MaxConcurrentOrders: &maxConcurrentOrders})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, updatedUser.MaxConcurrentOrders)
+
+	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, userToReceiveOrder.ID)
+
+	// Concurrent dispatches don't give the driver more orders than they can handle
+	var wg sync.WaitGroup
+	assigned := make(chan *models.Order, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			order, receivingUser, err := userRepo.AssignOrderToNextUser(&models.Order{RestaurantID: restaurant.ID, Code: fmt.Sprintf("728194%d", i)}, DispatchOptions{})
+			if err == nil {
+				assert.Equal(t, user.ID, receivingUser.ID)
+				assert.Equal(t, order.CreatedAt, receivingUser.LastOrderReceived)
+				assigned <- order
+			} else {
+				assert.Equal(t, utils.ErrNotFound, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(assigned)
+	assert.Len(t, assigned, 1)
+}
+
+func TestUserRepository_GetUserToReceiveOrderVehicle(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+	orderRepo := NewOrderRepository(Db)
+
+	user := &models.User{
+		ID:          "motorcycledriver",
+		Longitude:   31.4213234,
+		Latitude:    49.5945667,
+		IsActive:    true,
+		Phone:       "4246123494",
+		Radius:      100,
+		FCMToken:    "motorcycledrivertoken",
+		VehicleType: models.VehicleTypeMotorcycle,
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "vehiclerestaurant",
+		Longitude:           31.4213234,
+		Latitude:            49.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Vehicle",
+		PhoneNumber:         "427536423494",
+		LocationDescription: "Test Location",
+		DefaultVehicle:      models.VehicleTypeCar,
+	}
+
+	_, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+
+	// Only approved drivers receive orders
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
+	createdRestaurant, err := restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+	assert.Equal(t, models.VehicleTypeCar, createdRestaurant.DefaultVehicle)
+
+	// A motorcycle can carry the orders that require a bicycle or a motorcycle
+	for _, vehicleType := range []string{"", models.VehicleTypeBicycle, models.VehicleTypeMotorcycle} {
+		userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{RequiredVehicle: vehicleType})
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userToReceiveOrder.ID)
+	}
+
+	for _, vehicleType := range []string{models.VehicleTypeCar, models.VehicleTypeVan} {
+		_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{RequiredVehicle: vehicleType})
+		assert.Equal(t, utils.ErrNotFound, err)
+	}
+
+	order, err := orderRepo.CreateOrder(&models.Order{UserID: user.ID, RestaurantID: restaurant.ID, Code: "7281937", RequiredVehicle: models.VehicleTypeBicycle})
+	assert.NoError(t, err)
+	assert.Equal(t, models.VehicleTypeBicycle, order.RequiredVehicle)
+}
+
+func TestUserRepository_GetUserToReceiveOrderPreferences(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	firstUser := &models.User{
+		ID:        "preferencedriver1",
+		Longitude: 33.4213234,
+		Latitude:  51.5945667,
+		IsActive:  true,
+		Phone:     "4246123493",
+		Radius:    100,
+		FCMToken:  "preferencedriver1token",
+	}
+
+	secondUser := &models.User{
+		ID:        "preferencedriver2",
+		Longitude: 33.4213234,
+		Latitude:  51.5945667,
+		IsActive:  true,
+		Phone:     "4246123492",
+		Radius:    100,
+		FCMToken:  "preferencedriver2token",
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "preferencerestaurant",
+		Longitude:           33.4213234,
+		Latitude:            51.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Preference",
+		PhoneNumber:         "427536423493",
+		LocationDescription: "Test Location",
+	}
+
+	for _, user := range []*models.User{firstUser, secondUser} {
+		_, err := userRepo.CreateUser(user)
+		assert.NoError(t, err)
+
+		// Only approved drivers receive orders
+		_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+		assert.NoError(t, err)
+	}
+
+	_, err := restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// The first driver waited the longest
+	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, firstUser.ID, userToReceiveOrder.ID)
+
+	// Preferred drivers come first
+	preference, err := restaurantRepo.SetRestaurantDriverPreference(&models.RestaurantDriverPreference{RestaurantID: restaurant.ID, UserID: secondUser.ID, Preference: models.DriverPreferencePreferred})
+	assert.NoError(t, err)
+	assert.Equal(t, models.DriverPreferencePreferred, preference.Preference)
+
+	userToReceiveOrder, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, secondUser.ID, userToReceiveOrder.ID)
+
+	// Setting a preference again replaces it, blocked drivers never receive the orders
+	_, err = restaurantRepo.SetRestaurantDriverPreference(&models.RestaurantDriverPreference{RestaurantID: restaurant.ID, UserID: secondUser.ID, Preference: models.DriverPreferenceBlocked, Note: "Late"})
+	assert.NoError(t, err)
+	_, err = restaurantRepo.SetRestaurantDriverPreference(&models.RestaurantDriverPreference{RestaurantID: restaurant.ID, UserID: firstUser.ID, Preference: models.DriverPreferenceBlocked})
+	assert.NoError(t, err)
+
+	blockedDrivers, err := restaurantRepo.GetRestaurantDriverPreferences(restaurant.ID, models.DriverPreferenceBlocked)
+	assert.NoError(t, err)
+	assert.Len(t, blockedDrivers, 2)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	err = restaurantRepo.DeleteRestaurantDriverPreference(restaurant.ID, firstUser.ID)
+	assert.NoError(t, err)
+
+	userToReceiveOrder, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, firstUser.ID, userToReceiveOrder.ID)
+
+	err = restaurantRepo.DeleteRestaurantDriverPreference(restaurant.ID, firstUser.ID)
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	_, err = restaurantRepo.SetRestaurantDriverPreference(&models.RestaurantDriverPreference{RestaurantID: restaurant.ID, UserID: "nonexistentdriver", Preference: models.DriverPreferenceBlocked})
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
+func TestUserRepository_UserPenalties(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	user := &models.User{
+		ID:        "penalizeddriver",
+		Longitude: 25.4213234,
+		Latitude:  43.5945667,
+		IsActive:  true,
+		Phone:     "4246123497",
+		Radius:    100,
+		FCMToken:  "penalizeddrivertoken",
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "penaltyrestaurant",
+		Longitude:           25.4213234,
+		Latitude:            43.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Penalty",
+		PhoneNumber:         "427536423497",
+		LocationDescription: "Test Location",
+	}
+
+	_, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+
+	// Only approved drivers receive orders
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
+	_, err = restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	_, err = userRepo.GetActiveUserPenalty(user.ID)
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// A penalty whose cooldown is over still counts towards the escalation but doesn't keep the driver from receiving orders
+	_, err = userRepo.CreateUserPenalty(&models.DriverPenalty{UserID: user.ID, Reason: models.PenaltyReasonOrderExpired, OffenseNumber: 1}, 0)
+	assert.NoError(t, err)
+
+	count, err := userRepo.CountUserPenaltiesSince(user.ID, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+
+	penalty, err := userRepo.CreateUserPenalty(&models.DriverPenalty{UserID: user.ID, Reason: models.PenaltyReasonOrderReassigned, OffenseNumber: 2}, time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, penalty.OrderID)
+
+	activePenalty, err := userRepo.GetActiveUserPenalty(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, penalty.ID, activePenalty.ID)
+	assert.Equal(t, models.PenaltyReasonOrderReassigned, activePenalty.Reason)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
+func TestUserRepository_GetUserStats(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+	orderRepo := NewOrderRepository(Db)
+
+	user := &models.User{
+		ID:        "statsdriver",
+		Longitude: 27.4213234,
+		Latitude:  45.5945667,
+		IsActive:  true,
+		Phone:     "4246123496",
+		Radius:    100,
+		FCMToken:  "statsdrivertoken",
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "statsrestaurant",
+		Longitude:           27.4213234,
+		Latitude:            45.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Stats",
+		PhoneNumber:         "427536423496",
+		LocationDescription: "Test Location",
+	}
+
+	_, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+
+	// Only approved drivers receive orders
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
+	_, err = restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// A driver without orders has the score of a new driver
+	stats, err := userRepo.GetUserStats(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Offers)
+	assert.Nil(t, stats.AcceptanceRate)
+	assert.Equal(t, 1.0, stats.Score)
+
+	fulfilledOrder, err := orderRepo.CreateOrder(&models.Order{UserID: user.ID, RestaurantID: restaurant.ID, Code: "7281935"})
+	assert.NoError(t, err)
+	err = orderRepo.UpdateUserOrderState(fulfilledOrder.ID, user.ID, "ACCEPTED")
+	assert.NoError(t, err)
+	_, err = orderRepo.UpdateRestaurantOrderState(fulfilledOrder.ID, restaurant.ID, "FULFILLED")
+	assert.NoError(t, err)
+
+	fulfilledOrder, err = orderRepo.GetOrderByID(fulfilledOrder.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, fulfilledOrder.AcceptedAt)
+
+	rejectedOrder, err := orderRepo.CreateOrder(&models.Order{UserID: user.ID, RestaurantID: restaurant.ID, Code: "7281936"})
+	assert.NoError(t, err)
+	err = orderRepo.UpdateUserOrderState(rejectedOrder.ID, user.ID, "REJECTED")
+	assert.NoError(t, err)
+
+	stats, err = userRepo.GetUserStats(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Offers)
+	assert.Equal(t, 1, stats.Acceptances)
+	assert.Equal(t, 1, stats.Rejections)
+	assert.Equal(t, 0, stats.Expiries)
+	assert.Equal(t, 1, stats.Fulfilments)
+	assert.NotNil(t, stats.MedianSecondsToAccept)
+	assert.Equal(t, 0.5, *stats.AcceptanceRate)
+	assert.Equal(t, 1.0, *stats.FulfilmentRate)
+	assert.InDelta(t, 0.8, stats.Score, 0.0001)
+
+	// Ranking by score still finds the driver
+	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{RankByScore: true})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, userToReceiveOrder.ID)
+}
+
+func TestUserRepository_UpdateUserStatus(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	user := &models.User{
+		ID:          "onboardingdriver",
+		Longitude:   29.4213234,
+		Latitude:    47.5945667,
+		IsActive:    true,
+		Phone:       "4246123495",
+		Radius:      100,
+		FCMToken:    "onboardingdrivertoken",
+		VehicleType: models.VehicleTypeMotorcycle,
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "onboardingrestaurant",
+		Longitude:           29.4213234,
+		Latitude:            47.5945667,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Onboarding",
+		PhoneNumber:         "427536423495",
+		LocationDescription: "Test Location",
+	}
+
+	createdUser, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatusPendingApproval, createdUser.Status)
+	assert.Equal(t, models.VehicleTypeMotorcycle, createdUser.VehicleType)
+	assert.Equal(t, "", createdUser.IDPhotoURL)
+
+	_, err = restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// Drivers waiting for an approval don't receive orders
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	pendingUsers, err := userRepo.GetUsersByStatus(models.UserStatusPendingApproval)
+	assert.NoError(t, err)
+	pendingUserIDs := []string{}
+	for _, pendingUser := range pendingUsers {
+		pendingUserIDs = append(pendingUserIDs, pendingUser.ID)
+	}
+	assert.Contains(t, pendingUserIDs, user.ID)
+
+	// Documents left out of an update are kept
+	idPhotoURL := "https://www.google.com/id.jpg"
+	updatedUser, err := userRepo.UpdateUser(createdUser.ID, &models.UserPatch{IDPhotoURL: &idPhotoURL})
+	assert.NoError(t, err)
+	assert.Equal(t, models.VehicleTypeMotorcycle, updatedUser.VehicleType)
+	assert.Equal(t, "https://www.google.com/id.jpg", updatedUser.IDPhotoURL)
+
+	approvedUser, err := userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatusApproved, approvedUser.Status)
+
+	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, userToReceiveOrder.ID)
+
+	// Sending the same documents again keeps the approval
+	updatedUser, err = userRepo.UpdateUser(createdUser.ID, &models.UserPatch{IDPhotoURL: &idPhotoURL})
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatusApproved, updatedUser.Status)
+
+	// Changing the vehicle needs a new approval
+	vehicleType := models.VehicleTypeVan
+	updatedUser, err = userRepo.UpdateUser(createdUser.ID, &models.UserPatch{VehicleType: &vehicleType})
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatusPendingApproval, updatedUser.Status)
+	assert.Equal(t, models.VehicleTypeVan, updatedUser.VehicleType)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
+	// So does changing the ID photo
+	newIDPhotoURL := "https://www.google.com/new-id.jpg"
+	updatedUser, err = userRepo.UpdateUser(createdUser.ID, &models.UserPatch{IDPhotoURL: &newIDPhotoURL})
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatusPendingApproval, updatedUser.Status)
+
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusSuspended)
+	assert.NoError(t, err)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	_, err = userRepo.UpdateUserStatus("nonexistentdriver", models.UserStatusApproved)
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
 func TestUserRepository_DeleteUser(t *testing.T) {
 	userRepo := NewUserRepository(Db)
 	restaurantRepo := NewRestaurantRepository(Db)
@@ -254,3 +864,85 @@ func TestUserRepository_DeleteUser(t *testing.T) {
 	assert.NotNil(t, retrievedOrder)
 	assert.Equal(t, "", retrievedOrder.UserID)
 }
+
+func TestUserRepository_GetUserToReceiveOrderDeliveryZone(t *testing.T) {
+	userRepo := NewUserRepository(Db)
+	restaurantRepo := NewRestaurantRepository(Db)
+
+	// The driver is about 320 meters east of the restaurant, well within their radius
+	user := &models.User{
+		ID:        "zonedriver",
+		Longitude: 35.005,
+		Latitude:  55,
+		IsActive:  true,
+		Phone:     "4246123481",
+		Radius:    1000,
+		FCMToken:  "zonedrivertoken",
+	}
+
+	restaurant := &models.Restaurant{
+		ID:                  "zonerestaurant",
+		Longitude:           35,
+		Latitude:            55,
+		LogoURL:             "https://www.google.com",
+		Name:                "Test Restaurant Zone",
+		PhoneNumber:         "427536423481",
+		LocationDescription: "Test Location",
+	}
+
+	_, err := userRepo.CreateUser(user)
+	assert.NoError(t, err)
+
+	// Only approved drivers receive orders
+	_, err = userRepo.UpdateUserStatus(user.ID, models.UserStatusApproved)
+	assert.NoError(t, err)
+
+	_, err = restaurantRepo.CreateRestaurant(restaurant)
+	assert.NoError(t, err)
+
+	// A delivery zone smaller than the distance to the driver leaves them out
+	zone, err := restaurantRepo.SetRestaurantDeliveryZone(&models.DeliveryZone{RestaurantID: restaurant.ID, Type: models.DeliveryZoneTypeRadius, Radius: 200})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, zone.Radius)
+	assert.Nil(t, zone.Polygon)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// A polygon replaces the radius, the driver is inside this one
+	polygon := [][]float64{{34.99, 54.99}, {35.01, 54.99}, {35.01, 55.01}, {34.99, 55.01}, {34.99, 54.99}}
+	zone, err = restaurantRepo.SetRestaurantDeliveryZone(&models.DeliveryZone{RestaurantID: restaurant.ID, Type: models.DeliveryZoneTypePolygon, Polygon: polygon})
+	assert.NoError(t, err)
+	assert.Equal(t, polygon, zone.Polygon)
+	assert.Zero(t, zone.Radius)
+
+	userToReceiveOrder, err := userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, userToReceiveOrder.ID)
+
+	// The driver isn't inside a polygon that stops west of them
+	_, err = restaurantRepo.SetRestaurantDeliveryZone(&models.DeliveryZone{RestaurantID: restaurant.ID, Type: models.DeliveryZoneTypePolygon, Polygon: [][]float64{{34.99, 54.99}, {35.002, 54.99}, {35.002, 55.01}, {34.99, 55.01}, {34.99, 54.99}}})
+	assert.NoError(t, err)
+
+	_, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	// A polygon whose edges cross isn't a valid zone and doesn't replace the current one
+	_, err = restaurantRepo.SetRestaurantDeliveryZone(&models.DeliveryZone{RestaurantID: restaurant.ID, Type: models.DeliveryZoneTypePolygon, Polygon: [][]float64{{34.99, 54.99}, {35.01, 55.01}, {35.01, 54.99}, {34.99, 55.01}, {34.99, 54.99}}})
+	assert.Equal(t, utils.ErrInvalidZone, err)
+
+	zone, err = restaurantRepo.GetRestaurantDeliveryZone(restaurant.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 35.002, zone.Polygon[1][0])
+
+	// Without a zone the radius of the driver is enough
+	err = restaurantRepo.DeleteRestaurantDeliveryZone(restaurant.ID)
+	assert.NoError(t, err)
+
+	userToReceiveOrder, err = userRepo.GetUserToReceiveOrder(restaurant.ID, DispatchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, userToReceiveOrder.ID)
+
+	_, err = restaurantRepo.GetRestaurantDeliveryZone(restaurant.ID)
+	assert.Equal(t, utils.ErrNotFound, err)
+}
@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository interface {
+	// CreateWebhookEndpoint creates a new webhook endpoint
+	CreateWebhookEndpoint(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	// GetWebhookEndpoint returns a webhook endpoint that belongs to a restaurant
+	GetWebhookEndpoint(id int, restaurantID string) (*models.WebhookEndpoint, error)
+	// GetWebhookEndpointByID returns a webhook endpoint regardless of the restaurant it belongs to
+	GetWebhookEndpointByID(id int) (*models.WebhookEndpoint, error)
+	// GetWebhookEndpoints returns the webhook endpoints of a restaurant
+	GetWebhookEndpoints(restaurantID string) ([]*models.WebhookEndpoint, error)
+	// GetActiveWebhookEndpointsForEvent returns the active endpoints of a restaurant subscribed to the event type
+	GetActiveWebhookEndpointsForEvent(restaurantID string, eventType string) ([]*models.WebhookEndpoint, error)
+	// DeleteWebhookEndpoint deletes a webhook endpoint and its deliveries
+	DeleteWebhookEndpoint(id int, restaurantID string) error
+	// CreateWebhookDelivery creates a pending delivery that is due after the given delay
+	CreateWebhookDelivery(delivery *models.WebhookDelivery, dueIn time.Duration) (*models.WebhookDelivery, error)
+	// GetWebhookDelivery returns a delivery of an endpoint with its attempts
+	GetWebhookDelivery(id int, endpointID int) (*models.WebhookDelivery, error)
+	// GetWebhookDeliveries returns the latest deliveries of an endpoint
+	GetWebhookDeliveries(endpointID int, limit int) ([]*models.WebhookDelivery, error)
+	// ClaimDueWebhookDeliveries returns the pending deliveries that are due and postpones them by the lease so no other worker picks them up
+	ClaimDueWebhookDeliveries(limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	// RecordWebhookDeliveryAttempt logs an attempt and updates the state of its delivery. Pending deliveries are due again after retryIn
+	RecordWebhookDeliveryAttempt(attempt *models.WebhookDeliveryAttempt, state string, retryIn time.Duration) error
+	// ResetWebhookDelivery makes a delivery pending again. Like claimed deliveries, it is leased to the caller so the workers don't send it at the same time
+	ResetWebhookDelivery(id int, endpointID int, lease time.Duration) error
+}
+
+type WebhookRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &WebhookRepositoryImpl{db: db}
+}
+
+const webhookEndpointColumns = "id, restaurant_id, url, secret, event_types, is_active, created_at, updated_at"
+
+const webhookDeliveryColumns = "id, webhook_endpoint_id, event_type, payload, state, attempt_count, next_attempt_at, created_at, updated_at"
+
+func (r *WebhookRepositoryImpl) CreateWebhookEndpoint(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	const query = "INSERT INTO webhook_endpoints (restaurant_id, url, secret, event_types, is_active, created_at, updated_at) VALUES ($1, $2, $3, $4, true, CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING " + webhookEndpointColumns
+	err := scanWebhookEndpoint(r.db.QueryRow(query, endpoint.RestaurantID, endpoint.URL, endpoint.Secret, pq.Array(endpoint.EventTypes)), endpoint)
+	return endpoint, err
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookEndpoint(id int, restaurantID string) (*models.WebhookEndpoint, error) {
+	endpoint := &models.WebhookEndpoint{}
+	err := scanWebhookEndpoint(r.db.QueryRow("SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = $1 AND restaurant_id = $2", id, restaurantID), endpoint)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return endpoint, err
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookEndpointByID(id int) (*models.WebhookEndpoint, error) {
+	endpoint := &models.WebhookEndpoint{}
+	err := scanWebhookEndpoint(r.db.QueryRow("SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = $1", id), endpoint)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	return endpoint, err
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookEndpoints(restaurantID string) ([]*models.WebhookEndpoint, error) {
+	rows, err := r.db.Query("SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE restaurant_id = $1 ORDER BY id", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookEndpoints(rows)
+}
+
+func (r *WebhookRepositoryImpl) GetActiveWebhookEndpointsForEvent(restaurantID string, eventType string) ([]*models.WebhookEndpoint, error) {
+	rows, err := r.db.Query("SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE restaurant_id = $1 AND is_active = true AND $2 = ANY(event_types)", restaurantID, eventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookEndpoints(rows)
+}
+
+func (r *WebhookRepositoryImpl) DeleteWebhookEndpoint(id int, restaurantID string) error {
+	result, err := r.db.Exec("DELETE FROM webhook_endpoints WHERE id = $1 AND restaurant_id = $2", id, restaurantID)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+// The delays are added to the database clock so every instance schedules deliveries against the same clock
+func (r *WebhookRepositoryImpl) CreateWebhookDelivery(delivery *models.WebhookDelivery, dueIn time.Duration) (*models.WebhookDelivery, error) {
+	const query = "INSERT INTO webhook_deliveries (webhook_endpoint_id, event_type, payload, state, attempt_count, next_attempt_at, created_at, updated_at) VALUES ($1, $2, $3, 'PENDING', 0, CLOCK_TIMESTAMP() + $4::float8 * INTERVAL '1 second', CLOCK_TIMESTAMP(), CLOCK_TIMESTAMP()) RETURNING " + webhookDeliveryColumns
+	err := scanWebhookDelivery(r.db.QueryRow(query, delivery.WebhookEndpointID, delivery.EventType, []byte(delivery.Payload), dueIn.Seconds()), delivery)
+	return delivery, err
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookDelivery(id int, endpointID int) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := scanWebhookDelivery(r.db.QueryRow("SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = $1 AND webhook_endpoint_id = $2", id, endpointID), delivery)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query("SELECT id, webhook_delivery_id, attempt_number, status_code, error, duration_ms, created_at FROM webhook_delivery_attempts WHERE webhook_delivery_id = $1 ORDER BY attempt_number", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delivery.Attempts = []*models.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		attempt := &models.WebhookDeliveryAttempt{}
+		var statusCode sql.NullInt64
+		var attemptError sql.NullString
+		err := rows.Scan(&attempt.ID, &attempt.WebhookDeliveryID, &attempt.AttemptNumber, &statusCode, &attemptError, &attempt.DurationMs, &attempt.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			attempt.StatusCode = &code
+		}
+		attempt.Error = attemptError.String
+		delivery.Attempts = append(delivery.Attempts, attempt)
+	}
+
+	return delivery, rows.Err()
+}
+
+func (r *WebhookRepositoryImpl) GetWebhookDeliveries(endpointID int, limit int) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.Query("SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE webhook_endpoint_id = $1 ORDER BY id DESC LIMIT $2", endpointID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *WebhookRepositoryImpl) ClaimDueWebhookDeliveries(limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	// SKIP LOCKED lets several instances claim deliveries at the same time without sending any of them twice
+	const query = `
+	UPDATE webhook_deliveries SET next_attempt_at = CLOCK_TIMESTAMP() + $2::float8 * INTERVAL '1 second', updated_at = CLOCK_TIMESTAMP()
+	WHERE id IN (
+		SELECT id FROM webhook_deliveries
+		WHERE state = 'PENDING' AND next_attempt_at <= CLOCK_TIMESTAMP()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING ` + webhookDeliveryColumns
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *WebhookRepositoryImpl) RecordWebhookDeliveryAttempt(attempt *models.WebhookDeliveryAttempt, state string, retryIn time.Duration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("INSERT INTO webhook_delivery_attempts (webhook_delivery_id, attempt_number, status_code, error, duration_ms, created_at) VALUES ($1, $2, $3, NULLIF($4, ''), $5, CLOCK_TIMESTAMP()) RETURNING id, created_at", attempt.WebhookDeliveryID, attempt.AttemptNumber, attempt.StatusCode, attempt.Error, attempt.DurationMs).Scan(&attempt.ID, &attempt.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Only pending deliveries have a next attempt
+	const query = "UPDATE webhook_deliveries SET state = $1, attempt_count = $2, next_attempt_at = CASE WHEN $1 = 'PENDING' THEN CLOCK_TIMESTAMP() + $3::float8 * INTERVAL '1 second' END, updated_at = CLOCK_TIMESTAMP() WHERE id = $4"
+	_, err = tx.Exec(query, state, attempt.AttemptNumber, retryIn.Seconds(), attempt.WebhookDeliveryID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *WebhookRepositoryImpl) ResetWebhookDelivery(id int, endpointID int, lease time.Duration) error {
+	result, err := r.db.Exec("UPDATE webhook_deliveries SET state = 'PENDING', next_attempt_at = CLOCK_TIMESTAMP() + $3::float8 * INTERVAL '1 second', updated_at = CLOCK_TIMESTAMP() WHERE id = $1 AND webhook_endpoint_id = $2", id, endpointID, lease.Seconds())
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(result)
+}
+
+func scanWebhookEndpoint(row rowScanner, endpoint *models.WebhookEndpoint) error {
+	return row.Scan(&endpoint.ID, &endpoint.RestaurantID, &endpoint.URL, &endpoint.Secret, pq.Array(&endpoint.EventTypes), &endpoint.IsActive, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+}
+
+func scanWebhookEndpoints(rows *sql.Rows) ([]*models.WebhookEndpoint, error) {
+	endpoints := []*models.WebhookEndpoint{}
+	for rows.Next() {
+		endpoint := &models.WebhookEndpoint{}
+		err := scanWebhookEndpoint(rows, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+func scanWebhookDelivery(row rowScanner, delivery *models.WebhookDelivery) error {
+	var payload []byte
+	var nextAttemptAt sql.NullTime
+
+	err := row.Scan(&delivery.ID, &delivery.WebhookEndpointID, &delivery.EventType, &payload, &delivery.State, &delivery.AttemptCount, &nextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	delivery.Payload = payload
+	delivery.NextAttemptAt = nil
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	return nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*models.WebhookDelivery, error) {
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{}
+		err := scanWebhookDelivery(rows, delivery)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepository_WebhookDeliveries(t *testing.T) {
+	webhookRepo := NewWebhookRepository(Db)
+
+	endpoint, err := webhookRepo.CreateWebhookEndpoint(&models.WebhookEndpoint{
+		RestaurantID: "restaurant1", // Restaurant from the seed
+		URL:          "https://pos.example.com/webhooks",
+		Secret:       "whsec_test",
+		EventTypes:   []string{models.OrderEventAccepted, models.OrderEventRejected},
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, endpoint)
+	assert.True(t, endpoint.IsActive)
+
+	// Only the endpoints subscribed to the event are returned
+	endpoints, err := webhookRepo.GetActiveWebhookEndpointsForEvent("restaurant1", models.OrderEventAccepted)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, endpoints)
+
+	endpoints, err = webhookRepo.GetActiveWebhookEndpointsForEvent("restaurant1", models.OrderEventSeen)
+	assert.NoError(t, err)
+	for _, e := range endpoints {
+		assert.NotEqual(t, endpoint.ID, e.ID)
+	}
+
+	delivery, err := webhookRepo.CreateWebhookDelivery(&models.WebhookDelivery{
+		WebhookEndpointID: endpoint.ID,
+		EventType:         models.OrderEventAccepted,
+		Payload:           []byte(`{"order_id":1}`),
+	}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.State)
+
+	// A claimed delivery is leased and can't be claimed again until the lease is over
+	claimed, err := webhookRepo.ClaimDueWebhookDeliveries(100, time.Minute)
+	assert.NoError(t, err)
+	assert.Contains(t, deliveryIDs(claimed), delivery.ID)
+
+	claimed, err = webhookRepo.ClaimDueWebhookDeliveries(100, time.Minute)
+	assert.NoError(t, err)
+	assert.NotContains(t, deliveryIDs(claimed), delivery.ID)
+
+	statusCode := 500
+	err = webhookRepo.RecordWebhookDeliveryAttempt(&models.WebhookDeliveryAttempt{
+		WebhookDeliveryID: delivery.ID,
+		AttemptNumber:     1,
+		StatusCode:        &statusCode,
+		Error:             "endpoint responded with status 500",
+	}, models.WebhookDeliveryFailed, 0)
+	assert.NoError(t, err)
+
+	failedDelivery, err := webhookRepo.GetWebhookDelivery(delivery.ID, endpoint.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryFailed, failedDelivery.State)
+	assert.Equal(t, 1, failedDelivery.AttemptCount)
+	assert.Nil(t, failedDelivery.NextAttemptAt)
+	assert.Len(t, failedDelivery.Attempts, 1)
+
+	err = webhookRepo.ResetWebhookDelivery(delivery.ID, endpoint.ID, time.Minute)
+	assert.NoError(t, err)
+
+	// Only the owning restaurant can delete the endpoint
+	err = webhookRepo.DeleteWebhookEndpoint(endpoint.ID, "restaurant2")
+	assert.Equal(t, utils.ErrNotFound, err)
+
+	err = webhookRepo.DeleteWebhookEndpoint(endpoint.ID, "restaurant1")
+	assert.NoError(t, err)
+
+	_, err = webhookRepo.GetWebhookDelivery(delivery.ID, endpoint.ID)
+	assert.Equal(t, utils.ErrNotFound, err)
+}
+
+func deliveryIDs(deliveries []*models.WebhookDelivery) []int {
+	ids := make([]int, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+	return ids
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"Tamra/internal/app/tamra/handlers"
+	"Tamra/internal/pkg/models"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminRouter struct {
+	adminHandler *handlers.AdminHandler
+	requireRole  func(roles ...string) func(http.Handler) http.Handler
+	auditActions func(http.Handler) http.Handler
+	logger       logrus.FieldLogger
+}
+
+func NewAdminRouter(adminHandler *handlers.AdminHandler, requireRole func(roles ...string) func(http.Handler) http.Handler, auditActions func(http.Handler) http.Handler, logger logrus.FieldLogger) *AdminRouter {
+	return &AdminRouter{adminHandler: adminHandler, requireRole: requireRole, auditActions: auditActions, logger: logger}
+}
+
+func (router *AdminRouter) GetRouter() chi.Router {
+	r := chi.NewRouter()
+	// Only admins can use these routes
+	r.Use(router.requireRole(models.RoleAdmin))
+	// Every change the admins make is recorded in the audit logs
+	r.Use(router.auditActions)
+
+	r.Get("/users", router.adminHandler.GetUsers)
+	r.Post("/users/{userID}/approve", router.adminHandler.ApproveUser)
+	r.Post("/users/{userID}/suspend", router.adminHandler.SuspendUser)
+	r.Post("/users/{userID}/activate", router.adminHandler.ActivateUser)
+	r.Post("/users/{userID}/deactivate", router.adminHandler.DeactivateUser)
+	r.Put("/users/{userID}/role", router.adminHandler.AssignRole)
+	r.Post("/users/{userID}/signout", router.adminHandler.SignOutUser)
+
+	r.Get("/restaurants", router.adminHandler.GetRestaurants)
+
+	r.Get("/orders", router.adminHandler.GetOrders)
+	r.Post("/orders/{orderID}/cancel", router.adminHandler.CancelOrder)
+	r.Post("/orders/{orderID}/reassign", router.adminHandler.ReassignOrder)
+
+	r.Get("/audit-logs", router.adminHandler.GetAuditLogs)
+	return r
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"Tamra/internal/app/tamra/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type AuthRouter struct {
+	authHandler *handlers.AuthHandler
+	requireRole func(roles ...string) func(http.Handler) http.Handler
+	logger      logrus.FieldLogger
+}
+
+func NewAuthRouter(authHandler *handlers.AuthHandler, requireRole func(roles ...string) func(http.Handler) http.Handler, logger logrus.FieldLogger) *AuthRouter {
+	return &AuthRouter{authHandler: authHandler, requireRole: requireRole, logger: logger}
+}
+
+func (router *AuthRouter) GetRouter() chi.Router {
+	r := chi.NewRouter()
+	// Tokens are requested before signing in, the handler only issues them with the local auth provider
+	r.Post("/token", router.authHandler.IssueToken)
+
+	// Any signed in account can use these routes, new accounts don't have a role yet
+	r.With(router.requireRole()).Group(func(r chi.Router) {
+		r.Post("/role", router.authHandler.AssignRole)
+		r.Post("/signout", router.authHandler.SignOut)
+	})
+	return r
+}
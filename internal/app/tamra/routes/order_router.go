@@ -2,6 +2,7 @@ package routes
 
 import (
 	"Tamra/internal/app/tamra/handlers"
+	"Tamra/internal/pkg/models"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -9,31 +10,38 @@ import (
 )
 
 type OrderRouter struct {
-	orderHandler             *handlers.OrderHandler
-	restaurantAuthMiddleware func(http.Handler) http.Handler
-	userAuthMiddleware       func(http.Handler) http.Handler
-	logger                   logrus.FieldLogger
+	orderHandler      *handlers.OrderHandler
+	requireRole       func(roles ...string) func(http.Handler) http.Handler
+	resolveRestaurant func(memberRoles ...string) func(http.Handler) http.Handler
+	logger            logrus.FieldLogger
 }
 
-func NewOrderRouter(orderHandler *handlers.OrderHandler, restaurantAuthMiddleware func(http.Handler) http.Handler, userAuthMiddleware func(http.Handler) http.Handler, logger logrus.FieldLogger) *OrderRouter {
-	return &OrderRouter{orderHandler: orderHandler, userAuthMiddleware: userAuthMiddleware, restaurantAuthMiddleware: restaurantAuthMiddleware, logger: logger}
+func NewOrderRouter(orderHandler *handlers.OrderHandler, requireRole func(roles ...string) func(http.Handler) http.Handler, resolveRestaurant func(memberRoles ...string) func(http.Handler) http.Handler, logger logrus.FieldLogger) *OrderRouter {
+	return &OrderRouter{orderHandler: orderHandler, requireRole: requireRole, resolveRestaurant: resolveRestaurant, logger: logger}
 }
 
 func (router *OrderRouter) GetRouter() chi.Router {
 	r := chi.NewRouter()
 
-	r.With(router.restaurantAuthMiddleware).Group(func(r chi.Router) {
+	// Every member of a restaurant handles its orders
+	r.With(router.requireRole(models.RoleRestaurant, models.RoleRestaurantStaff), router.resolveRestaurant()).Group(func(r chi.Router) {
 		r.Post("/", router.orderHandler.CreateOrder)
 		r.Get("/restaurant", router.orderHandler.GetRestaurantOrders)
 		r.Post("/{order_id}/reassign", router.orderHandler.ReassignOrder)
 		r.Patch("/{order_id}/fulfill", router.orderHandler.FulfillOrder)
 		r.Patch("/{order_id}/cancel", router.orderHandler.CancelOrder)
+		r.Get("/{order_id}/location", router.orderHandler.GetOrderLocation)
 	})
 
-	r.With(router.userAuthMiddleware).Group(func(r chi.Router) {
+	// The stream is filtered by the restaurant or the UID of the drivers so both get their own orders
+	r.With(router.requireRole(models.RoleDriver, models.RoleRestaurant, models.RoleRestaurantStaff), router.resolveRestaurant()).Get("/stream", router.orderHandler.StreamOrderEvents)
+
+	r.With(router.requireRole(models.RoleDriver)).Group(func(r chi.Router) {
 		r.Get("/user", router.orderHandler.GetUserOrders)
 		r.Patch("/{id}/accept", router.orderHandler.AcceptOrder)
 		r.Patch("/{id}/reject", router.orderHandler.RejectOrder)
+		r.Patch("/{id}/delivered", router.orderHandler.MarkOrderDelivered)
+		r.Patch("/{id}/seen", router.orderHandler.MarkOrderSeen)
 	})
 	// r.Patch("/{id}", router.orderHandler.UpdateOrder)
 
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"Tamra/internal/app/tamra/handlers"
+	"Tamra/internal/pkg/models"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type OrganizationRouter struct {
+	organizationHandler *handlers.OrganizationHandler
+	requireRole         func(roles ...string) func(http.Handler) http.Handler
+	resolveRestaurant   func(memberRoles ...string) func(http.Handler) http.Handler
+	logger              logrus.FieldLogger
+}
+
+func NewOrganizationRouter(organizationHandler *handlers.OrganizationHandler, requireRole func(roles ...string) func(http.Handler) http.Handler, resolveRestaurant func(memberRoles ...string) func(http.Handler) http.Handler, logger logrus.FieldLogger) *OrganizationRouter {
+	return &OrganizationRouter{organizationHandler: organizationHandler, requireRole: requireRole, resolveRestaurant: resolveRestaurant, logger: logger}
+}
+
+func (router *OrganizationRouter) GetRouter() chi.Router {
+	r := chi.NewRouter()
+	// The organization is managed from the restaurant that owns it, by its owner and managers
+	r.Use(router.requireRole(models.RoleRestaurant, models.RoleRestaurantStaff))
+
+	r.With(router.resolveRestaurant(models.RestaurantMemberRoleOwner, models.RestaurantMemberRoleManager)).Group(func(r chi.Router) {
+		r.Get("/me", router.organizationHandler.GetOrganization)
+		r.Get("/me/branches", router.organizationHandler.GetBranches)
+		r.Patch("/me/branches/{branchID}", router.organizationHandler.UpdateBranch)
+		r.Get("/me/orders", router.organizationHandler.GetOrders)
+	})
+
+	r.With(router.resolveRestaurant(models.RestaurantMemberRoleOwner)).Group(func(r chi.Router) {
+		r.Post("/", router.organizationHandler.CreateOrganization)
+		r.Post("/me/branches", router.organizationHandler.CreateBranch)
+		r.Delete("/me/branches/{branchID}", router.organizationHandler.DeleteBranch)
+		r.Post("/me/branches/{branchID}/invites", router.organizationHandler.CreateBranchInvite)
+	})
+
+	return r
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"Tamra/internal/app/tamra/handlers"
+	"Tamra/internal/pkg/models"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -9,31 +10,76 @@ import (
 )
 
 type RestaurantRouter struct {
-	restaurantHandler       *handlers.RestaurantHandler
-	restaurantAuthMiddlware func(http.Handler) http.Handler
-	userAuthMiddleware      func(http.Handler) http.Handler
-	logger                  logrus.FieldLogger
+	restaurantHandler *handlers.RestaurantHandler
+	webhookHandler    *handlers.WebhookHandler
+	requireRole       func(roles ...string) func(http.Handler) http.Handler
+	resolveRestaurant func(memberRoles ...string) func(http.Handler) http.Handler
+	logger            logrus.FieldLogger
 }
 
-func NewRestaurantRouter(restaurantHandler *handlers.RestaurantHandler, restaurantAuthMiddlware func(http.Handler) http.Handler, userAuthMiddleware func(http.Handler) http.Handler, logger logrus.FieldLogger) *RestaurantRouter {
-	return &RestaurantRouter{restaurantHandler: restaurantHandler, restaurantAuthMiddlware: restaurantAuthMiddlware, userAuthMiddleware: userAuthMiddleware, logger: logger}
+func NewRestaurantRouter(restaurantHandler *handlers.RestaurantHandler, webhookHandler *handlers.WebhookHandler, requireRole func(roles ...string) func(http.Handler) http.Handler, resolveRestaurant func(memberRoles ...string) func(http.Handler) http.Handler, logger logrus.FieldLogger) *RestaurantRouter {
+	return &RestaurantRouter{restaurantHandler: restaurantHandler, webhookHandler: webhookHandler, requireRole: requireRole, resolveRestaurant: resolveRestaurant, logger: logger}
 }
 
 func (router *RestaurantRouter) GetRouter() chi.Router {
 	r := chi.NewRouter()
-	// Use the restaurantAuthMiddlware for all routes in the restaurant route
 	// Middleware checks if the token is valid and if it is, it will call the next handler in the chain
-	// It will also append the UUID of the user to the request context so we can use it in the handler
-	r.With(router.restaurantAuthMiddlware).Group(func(r chi.Router) {
-		r.Post("/", router.restaurantHandler.CreateRestaurant)
-		r.Get("/me", router.restaurantHandler.GetRestaurant)
-		r.Get("/logo/uploadurl", router.restaurantHandler.GetLogoUploadURL)
-		r.Patch("/me", router.restaurantHandler.UpdateRestaurant)
-		r.Delete("/me", router.restaurantHandler.DeleteRestaurant)
+	// It will also append the principal to the request context so we can use it in the handler
+	// Only restaurants can create a restaurant, the account becomes its owner
+	r.With(router.requireRole(models.RoleRestaurant)).Post("/", router.restaurantHandler.CreateRestaurant)
+
+	// The owner and the staff act for the restaurant they are a member of, what they can do depends on their role in the restaurant
+	r.With(router.requireRole(models.RoleRestaurant, models.RoleRestaurantStaff)).Group(func(r chi.Router) {
+		r.With(router.resolveRestaurant()).Group(func(r chi.Router) {
+			r.Get("/me", router.restaurantHandler.GetRestaurant)
+			r.Post("/me/devices", router.restaurantHandler.RegisterDevice)
+			r.Get("/me/devices", router.restaurantHandler.GetDevices)
+			r.Delete("/me/devices/{deviceID}", router.restaurantHandler.DeleteDevice)
+		})
+
+		r.With(router.resolveRestaurant(models.RestaurantMemberRoleOwner, models.RestaurantMemberRoleManager)).Group(func(r chi.Router) {
+			r.Get("/logo/uploadurl", router.restaurantHandler.GetLogoUploadURL)
+			r.Patch("/me", router.restaurantHandler.UpdateRestaurant)
+			r.Get("/me/members", router.restaurantHandler.GetMembers)
+			r.Get("/me/opening-hours", router.restaurantHandler.GetOpeningHours)
+			r.Put("/me/opening-hours", router.restaurantHandler.UpdateOpeningHours)
+			r.Get("/me/closures", router.restaurantHandler.GetClosures)
+			r.Post("/me/closures", router.restaurantHandler.CreateClosure)
+			r.Delete("/me/closures/{closureID}", router.restaurantHandler.DeleteClosure)
+			r.Get("/me/delivery-zone", router.restaurantHandler.GetDeliveryZone)
+			r.Put("/me/delivery-zone", router.restaurantHandler.SetDeliveryZone)
+			r.Delete("/me/delivery-zone", router.restaurantHandler.DeleteDeliveryZone)
+			r.Get("/me/drivers", router.restaurantHandler.GetDriverPreferences)
+			r.Put("/me/drivers/{userID}", router.restaurantHandler.SetDriverPreference)
+			r.Delete("/me/drivers/{userID}", router.restaurantHandler.DeleteDriverPreference)
+			r.Post("/me/webhooks", router.webhookHandler.CreateWebhook)
+			r.Get("/me/webhooks", router.webhookHandler.GetWebhooks)
+			r.Delete("/me/webhooks/{webhookID}", router.webhookHandler.DeleteWebhook)
+			r.Get("/me/webhooks/{webhookID}/deliveries", router.webhookHandler.GetWebhookDeliveries)
+			r.Get("/me/webhooks/{webhookID}/deliveries/{deliveryID}", router.webhookHandler.GetWebhookDelivery)
+			r.Post("/me/webhooks/{webhookID}/deliveries/{deliveryID}/replay", router.webhookHandler.ReplayWebhookDelivery)
+		})
+
+		r.With(router.resolveRestaurant(models.RestaurantMemberRoleOwner)).Group(func(r chi.Router) {
+			r.Delete("/me", router.restaurantHandler.DeleteRestaurant)
+			r.Delete("/me/members/{uid}", router.restaurantHandler.RemoveMember)
+			r.Post("/me/invites", router.restaurantHandler.CreateInvite)
+			r.Get("/me/invites", router.restaurantHandler.GetInvites)
+			r.Delete("/me/invites/{inviteID}", router.restaurantHandler.DeleteInvite)
+		})
 	})
 
-	r.With(router.userAuthMiddleware).Group(func(r chi.Router) {
-		// Users will call this route to get restaurant details of the restaurant that sent them the order
+	// Invited staff don't have a role until they accept their first invite
+	r.With(router.requireRole()).Group(func(r chi.Router) {
+		r.Get("/invites", router.restaurantHandler.GetReceivedInvites)
+		r.Post("/invites/{inviteID}/accept", router.restaurantHandler.AcceptInvite)
+	})
+
+	// Drivers look for the restaurants around them to decide where to wait for orders
+	r.With(router.requireRole(models.RoleDriver)).Get("/nearby", router.restaurantHandler.GetNearbyRestaurants)
+
+	r.With(router.requireRole(models.RoleDriver, models.RoleRestaurant, models.RoleRestaurantStaff)).Group(func(r chi.Router) {
+		// Users will call this route to get restaurant details of the restaurant that sent them the order, and when it is open
 		r.Get("/{restaurantID}", router.restaurantHandler.GetRestaurantByID)
 	})
 
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"Tamra/internal/app/tamra/handlers"
+	"Tamra/internal/pkg/models"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -9,27 +10,36 @@ import (
 )
 
 type UserRouter struct {
-	userHandler       *handlers.UserHandler
-	userAuthMiddlware func(http.Handler) http.Handler
-	logger            logrus.FieldLogger
+	userHandler *handlers.UserHandler
+	requireRole func(roles ...string) func(http.Handler) http.Handler
+	logger      logrus.FieldLogger
 }
 
-func NewUserRouter(userHandler *handlers.UserHandler, userAuthMiddlware func(http.Handler) http.Handler, logger logrus.FieldLogger) *UserRouter {
-	return &UserRouter{userHandler: userHandler, userAuthMiddlware: userAuthMiddlware, logger: logger}
+func NewUserRouter(userHandler *handlers.UserHandler, requireRole func(roles ...string) func(http.Handler) http.Handler, logger logrus.FieldLogger) *UserRouter {
+	return &UserRouter{userHandler: userHandler, requireRole: requireRole, logger: logger}
 }
 
 // ? Is this an overkill? Should we just return a chi.Router instead of a chi.Router wrapped in a Router struct?
 // ? Should we just pass the handlers to GetRouter instead of creating a new Router struct?
 func (router *UserRouter) GetRouter() chi.Router {
 	r := chi.NewRouter()
-	// Use the authMiddleware for all routes in the user route
+	// Only drivers can use the user routes
 	// Middleware checks if the token is valid and if it is, it will call the next handler in the chain
 	// It will also append the UUID of the user to the request context so we can use it in the handler
-	r.Use(router.userAuthMiddlware)
+	r.Use(router.requireRole(models.RoleDriver))
 	r.Post("/", router.userHandler.CreateUser)
 	// r.Get("/", router.userHandler.GetUsers)
 	r.Get("/me", router.userHandler.GetUser)
 	r.Patch("/me", router.userHandler.UpdateUser)
+	r.Get("/me/id-photo/uploadurl", router.userHandler.GetIDPhotoUploadURL)
+	r.Put("/me/location", router.userHandler.UpdateLocation)
+	r.Post("/me/location/batch", router.userHandler.UpdateLocationBatch)
+	r.Get("/me/schedule", router.userHandler.GetSchedule)
+	r.Put("/me/schedule", router.userHandler.UpdateSchedule)
+	r.Get("/me/time-off", router.userHandler.GetTimeOffs)
+	r.Post("/me/time-off", router.userHandler.CreateTimeOff)
+	r.Delete("/me/time-off/{timeOffID}", router.userHandler.DeleteTimeOff)
+	r.Get("/me/stats", router.userHandler.GetStats)
 	r.Delete("/me", router.userHandler.DeleteUser)
 	return r
 }
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AuditService keeps track of the changes the admins make
+type AuditService interface {
+	// RecordAdminAction records a change made by an admin
+	RecordAdminAction(log *models.AdminAuditLog) error
+	// GetAdminAuditLogs returns a page of the audit logs matching the filter, the newest first, and how many match it
+	GetAdminAuditLogs(filter *models.AdminAuditLogFilter) ([]*models.AdminAuditLog, int, error)
+}
+
+type AuditServiceImpl struct {
+	auditRepository repositories.AuditRepository
+	logger          logrus.FieldLogger
+}
+
+func NewAuditService(auditRepository repositories.AuditRepository, logger logrus.FieldLogger) AuditService {
+	return &AuditServiceImpl{auditRepository: auditRepository, logger: logger}
+}
+
+func (s *AuditServiceImpl) RecordAdminAction(log *models.AdminAuditLog) error {
+	_, err := s.auditRepository.CreateAdminAuditLog(log)
+	if err != nil {
+		return fmt.Errorf("failed to create admin audit log: %w", err)
+	}
+	return nil
+}
+
+func (s *AuditServiceImpl) GetAdminAuditLogs(filter *models.AdminAuditLogFilter) ([]*models.AdminAuditLog, int, error) {
+	logs, total, err := s.auditRepository.SearchAdminAuditLogs(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get admin audit logs: %w", err)
+	}
+	return logs, total, nil
+}
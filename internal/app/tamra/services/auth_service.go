@@ -0,0 +1,292 @@
+package services
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"firebase.google.com/go/auth"
+	"github.com/sirupsen/logrus"
+)
+
+// AuthProvider manages the accounts people sign in with. The roles are stored on the accounts so they end up in the tokens
+type AuthProvider interface {
+	// GetRole returns the role of an account, empty if it doesn't have one yet
+	GetRole(uid string) (string, error)
+	// SetRole replaces the role of an account
+	SetRole(uid string, role string) error
+	// RevokeTokens signs the account out of all its sessions
+	RevokeTokens(uid string) error
+	// DeleteAccount deletes the account, it can't sign in anymore. It fails with utils.ErrNotFound if the account doesn't exist
+	DeleteAccount(uid string) error
+}
+
+// FirebaseAuthProvider stores the roles as Firebase custom claims
+type FirebaseAuthProvider struct {
+	authClient *auth.Client
+}
+
+func NewFirebaseAuthProvider(authClient *auth.Client) AuthProvider {
+	return &FirebaseAuthProvider{authClient: authClient}
+}
+
+func (p *FirebaseAuthProvider) GetRole(uid string) (string, error) {
+	user, err := p.authClient.GetUser(context.Background(), uid)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return "", utils.ErrNotFound
+		}
+		return "", err
+	}
+	role, _ := user.CustomClaims[models.RoleClaim].(string)
+	return role, nil
+}
+
+// SetRole keeps the other custom claims of the account, Firebase replaces all of them at once
+func (p *FirebaseAuthProvider) SetRole(uid string, role string) error {
+	user, err := p.authClient.GetUser(context.Background(), uid)
+	if err != nil {
+		if auth.IsUserNotFound(err) {
+			return utils.ErrNotFound
+		}
+		return err
+	}
+
+	claims := map[string]interface{}{}
+	for key, value := range user.CustomClaims {
+		claims[key] = value
+	}
+	claims[models.RoleClaim] = role
+	return p.authClient.SetCustomUserClaims(context.Background(), uid, claims)
+}
+
+// RevokeTokens invalidates the refresh tokens of the account. Its ID tokens are rejected once they expire,
+// or right away if the tokens are verified with the revocation check
+func (p *FirebaseAuthProvider) RevokeTokens(uid string) error {
+	err := p.authClient.RevokeRefreshTokens(context.Background(), uid)
+	if auth.IsUserNotFound(err) {
+		return utils.ErrNotFound
+	}
+	return err
+}
+
+func (p *FirebaseAuthProvider) DeleteAccount(uid string) error {
+	err := p.authClient.DeleteUser(context.Background(), uid)
+	if auth.IsUserNotFound(err) {
+		return utils.ErrNotFound
+	}
+	return err
+}
+
+// InMemoryAuthProvider keeps the roles in memory, it goes with the local token issuer used during development and in the tests.
+// Every UID is considered an existing account, and the local tokens can't be revoked
+type InMemoryAuthProvider struct {
+	mu    sync.Mutex
+	roles map[string]string
+}
+
+func NewInMemoryAuthProvider() AuthProvider {
+	return &InMemoryAuthProvider{roles: map[string]string{}}
+}
+
+func (p *InMemoryAuthProvider) GetRole(uid string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.roles[uid], nil
+}
+
+func (p *InMemoryAuthProvider) SetRole(uid string, role string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roles[uid] = role
+	return nil
+}
+
+func (p *InMemoryAuthProvider) RevokeTokens(uid string) error {
+	return nil
+}
+
+func (p *InMemoryAuthProvider) DeleteAccount(uid string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.roles, uid)
+	return nil
+}
+
+// AuthService assigns the roles that decide which routes an account can use.
+// Tokens issued before a role change keep the old role, so clients have to refresh their token afterwards
+type AuthService interface {
+	// GetRole returns the role of an account, empty if it doesn't have one yet
+	GetRole(uid string) (string, error)
+	// AssignOwnRole lets a new account pick whether it is a driver or a restaurant. It fails with utils.ErrRoleAlreadyAssigned if it already has a role,
+	// and with utils.ErrRoleMismatch if the account is already a driver or works for a restaurant and picks the other role
+	AssignOwnRole(uid string, role string) error
+	// AssignRole sets the role of any account, used by the admins
+	AssignRole(uid string, role string) error
+	// BackfillRoles gives the accounts created before the roles existed the role of their driver or restaurant rows.
+	// Accounts that already have a role keep it. It returns how many accounts got a role
+	BackfillRoles() (int, error)
+	// SignOut revokes the tokens of all the sessions of an account
+	SignOut(uid string) error
+	// DeleteAccount signs the account out and deletes it. Deleting an account that doesn't exist anymore succeeds
+	DeleteAccount(uid string) error
+}
+
+type AuthServiceImpl struct {
+	authProvider         AuthProvider
+	userRepository       repositories.UserRepository
+	restaurantRepository repositories.RestaurantRepository
+	logger               logrus.FieldLogger
+}
+
+func NewAuthService(authProvider AuthProvider, userRepository repositories.UserRepository, restaurantRepository repositories.RestaurantRepository, logger logrus.FieldLogger) AuthService {
+	return &AuthServiceImpl{authProvider: authProvider, userRepository: userRepository, restaurantRepository: restaurantRepository, logger: logger}
+}
+
+func (s *AuthServiceImpl) GetRole(uid string) (string, error) {
+	role, err := s.authProvider.GetRole(uid)
+	if err != nil {
+		return "", fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+func (s *AuthServiceImpl) AssignOwnRole(uid string, role string) error {
+	currentRole, err := s.authProvider.GetRole(uid)
+	if err != nil {
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	// Changing roles would let a driver take over the routes of a restaurant, only the admins can do it
+	if currentRole != "" {
+		return utils.ErrRoleAlreadyAssigned
+	}
+
+	// Accounts created before the roles existed have no role yet, they can only pick the one matching their rows
+	accountRole, err := s.accountRole(uid)
+	if err != nil {
+		return err
+	}
+	if accountRole != "" && accountRole != role {
+		return fmt.Errorf("account %s is a %s and can't pick the %s role: %w", uid, accountRole, role, utils.ErrRoleMismatch)
+	}
+
+	err = s.authProvider.SetRole(uid, role)
+	if err != nil {
+		return fmt.Errorf("failed to set role: %w", err)
+	}
+	return nil
+}
+
+func (s *AuthServiceImpl) AssignRole(uid string, role string) error {
+	err := s.authProvider.SetRole(uid, role)
+	if err != nil {
+		return fmt.Errorf("failed to set role: %w", err)
+	}
+	s.logger.Infof("Assigned role %s to %s", role, uid)
+	return nil
+}
+
+func (s *AuthServiceImpl) BackfillRoles() (int, error) {
+	// GetUsers returns ErrNotFound when there are no drivers yet, the restaurant members still need their roles
+	users, err := s.userRepository.GetUsers()
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return 0, fmt.Errorf("failed to get users: %w", err)
+	}
+	members, err := s.restaurantRepository.GetAllRestaurantMembers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get restaurant members: %w", err)
+	}
+
+	roles := map[string]string{}
+	for _, member := range members {
+		roles[member.UID] = restaurantMemberAccountRole(member)
+	}
+	for _, user := range users {
+		if role, ok := roles[user.ID]; ok {
+			s.logger.Warnf("Account %s is both a driver and a %s, skipping it", user.ID, role)
+			delete(roles, user.ID)
+			continue
+		}
+		roles[user.ID] = models.RoleDriver
+	}
+
+	assigned := 0
+	for uid, role := range roles {
+		currentRole, err := s.authProvider.GetRole(uid)
+		if errors.Is(err, utils.ErrNotFound) {
+			// Rows of deleted accounts don't need a role
+			continue
+		}
+		if err != nil {
+			return assigned, fmt.Errorf("failed to get role of %s: %w", uid, err)
+		}
+		if currentRole != "" {
+			continue
+		}
+
+		err = s.authProvider.SetRole(uid, role)
+		if err != nil {
+			return assigned, fmt.Errorf("failed to set role of %s: %w", uid, err)
+		}
+		s.logger.Infof("Assigned role %s to %s", role, uid)
+		assigned++
+	}
+
+	return assigned, nil
+}
+
+// accountRole returns the role matching the rows of the account, empty if it isn't a driver and doesn't work for a restaurant yet
+func (s *AuthServiceImpl) accountRole(uid string) (string, error) {
+	_, err := s.userRepository.GetUser(uid)
+	if err == nil {
+		return models.RoleDriver, nil
+	}
+	if !errors.Is(err, utils.ErrNotFound) {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	member, err := s.restaurantRepository.GetRestaurantMember(uid)
+	if err == nil {
+		return restaurantMemberAccountRole(member), nil
+	}
+	if !errors.Is(err, utils.ErrNotFound) {
+		return "", fmt.Errorf("failed to get restaurant member: %w", err)
+	}
+	return "", nil
+}
+
+// restaurantMemberAccountRole returns the role of the account of a restaurant member. Only the owners have the restaurant role
+func restaurantMemberAccountRole(member *models.RestaurantMember) string {
+	if member.Role == models.RestaurantMemberRoleOwner {
+		return models.RoleRestaurant
+	}
+	return models.RoleRestaurantStaff
+}
+
+func (s *AuthServiceImpl) SignOut(uid string) error {
+	err := s.authProvider.RevokeTokens(uid)
+	if err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	s.logger.Infof("Signed out %s", uid)
+	return nil
+}
+
+func (s *AuthServiceImpl) DeleteAccount(uid string) error {
+	err := s.authProvider.RevokeTokens(uid)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	err = s.authProvider.DeleteAccount(uid)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	s.logger.Infof("Deleted account %s", uid)
+	return nil
+}
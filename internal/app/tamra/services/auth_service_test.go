@@ -0,0 +1,125 @@
+package services
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeUserRepository only implements the methods the tests use, the others panic
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users          []*models.User
+	assignedOrders []*models.Order
+}
+
+func (r *fakeUserRepository) GetUser(userID string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+	return nil, utils.ErrNotFound
+}
+
+// GetUsers fails when there are no users, like the database does
+func (r *fakeUserRepository) GetUsers() ([]*models.User, error) {
+	if len(r.users) == 0 {
+		return nil, utils.ErrNotFound
+	}
+	return r.users, nil
+}
+
+// fakeRestaurantRepository only implements the methods the tests use, the others panic
+type fakeRestaurantRepository struct {
+	repositories.RestaurantRepository
+	members []*models.RestaurantMember
+}
+
+func (r *fakeRestaurantRepository) GetRestaurantMember(uid string) (*models.RestaurantMember, error) {
+	for _, member := range r.members {
+		if member.UID == uid {
+			return member, nil
+		}
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (r *fakeRestaurantRepository) GetAllRestaurantMembers() ([]*models.RestaurantMember, error) {
+	return r.members, nil
+}
+
+func newTestAuthService() (AuthService, AuthProvider) {
+	authProvider := NewInMemoryAuthProvider()
+	userRepository := &fakeUserRepository{users: []*models.User{{ID: "driver1"}}}
+	restaurantRepository := &fakeRestaurantRepository{members: []*models.RestaurantMember{
+		{RestaurantID: "restaurant1", UID: "restaurant1", Role: models.RestaurantMemberRoleOwner},
+		{RestaurantID: "restaurant1", UID: "cashier1", Role: models.RestaurantMemberRoleCashier},
+	}}
+	return NewAuthService(authProvider, userRepository, restaurantRepository, newTestLogger()), authProvider
+}
+
+func TestAuthService_AssignOwnRole(t *testing.T) {
+	authService, authProvider := newTestAuthService()
+
+	// New accounts pick any role, once
+	err := authService.AssignOwnRole("newaccount", models.RoleRestaurant)
+	assert.NoError(t, err)
+	err = authService.AssignOwnRole("newaccount", models.RoleDriver)
+	assert.ErrorIs(t, err, utils.ErrRoleAlreadyAssigned)
+
+	// Existing accounts without a role can only pick the role of their rows
+	err = authService.AssignOwnRole("driver1", models.RoleRestaurant)
+	assert.ErrorIs(t, err, utils.ErrRoleMismatch)
+	err = authService.AssignOwnRole("cashier1", models.RoleRestaurant)
+	assert.ErrorIs(t, err, utils.ErrRoleMismatch)
+	err = authService.AssignOwnRole("restaurant1", models.RoleDriver)
+	assert.ErrorIs(t, err, utils.ErrRoleMismatch)
+
+	err = authService.AssignOwnRole("driver1", models.RoleDriver)
+	assert.NoError(t, err)
+	role, _ := authProvider.GetRole("driver1")
+	assert.Equal(t, models.RoleDriver, role)
+}
+
+func TestAuthService_BackfillRoles(t *testing.T) {
+	authService, authProvider := newTestAuthService()
+	err := authProvider.SetRole("restaurant1", models.RoleAdmin)
+	assert.NoError(t, err)
+
+	assigned, err := authService.BackfillRoles()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, assigned)
+
+	role, _ := authProvider.GetRole("driver1")
+	assert.Equal(t, models.RoleDriver, role)
+	role, _ = authProvider.GetRole("cashier1")
+	assert.Equal(t, models.RoleRestaurantStaff, role)
+	// Roles that were already set are kept
+	role, _ = authProvider.GetRole("restaurant1")
+	assert.Equal(t, models.RoleAdmin, role)
+
+	// Running it again doesn't change anything
+	assigned, err = authService.BackfillRoles()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, assigned)
+}
+
+func TestAuthService_BackfillRolesWithoutDrivers(t *testing.T) {
+	authProvider := NewInMemoryAuthProvider()
+	restaurantRepository := &fakeRestaurantRepository{members: []*models.RestaurantMember{
+		{RestaurantID: "restaurant1", UID: "restaurant1", Role: models.RestaurantMemberRoleOwner},
+	}}
+	authService := NewAuthService(authProvider, &fakeUserRepository{}, restaurantRepository, newTestLogger())
+
+	// The restaurant members get their roles even though there are no drivers yet
+	assigned, err := authService.BackfillRoles()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, assigned)
+
+	role, _ := authProvider.GetRole("restaurant1")
+	assert.Equal(t, models.RoleRestaurant, role)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"Tamra/internal/pkg/utils"
+	"Tamra/internal/pkg/utils/sms"
+	"context"
+	"errors"
+	"fmt"
+
+	"firebase.google.com/go/messaging"
+	"github.com/sirupsen/logrus"
+)
+
+// Names of the channels used in the notification policy.
+const (
+	ChannelPush = "push"
+	ChannelSMS  = "sms"
+)
+
+// NotificationChannel is a way of reaching a recipient, e.g. push notifications or SMS.
+// Send returns utils.ErrRecipientUnreachable if the recipient has no address for the channel.
+type NotificationChannel interface {
+	Name() string
+	Send(recipient Recipient, title string, body string) error
+}
+
+// FCMChannel sends push notifications to every FCM token of the recipient.
+type FCMChannel struct {
+	messagingClient *messaging.Client
+}
+
+func NewFCMChannel(messagingClient *messaging.Client) NotificationChannel {
+	return &FCMChannel{messagingClient: messagingClient}
+}
+
+func (c *FCMChannel) Name() string {
+	return ChannelPush
+}
+
+// Send succeeds if at least one of the recipient's devices received the notification.
+func (c *FCMChannel) Send(recipient Recipient, title string, body string) error {
+	if len(recipient.FCMTokens) == 0 {
+		return utils.ErrRecipientUnreachable
+	}
+
+	var errs []error
+	for _, token := range recipient.FCMTokens {
+		message := &messaging.Message{
+			Notification: &messaging.Notification{
+				Title: title,
+				Body:  body,
+			},
+			Token: token,
+		}
+
+		_, err := c.messagingClient.Send(context.Background(), message)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == len(recipient.FCMTokens) {
+		return fmt.Errorf("failed to send message: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// SMSChannel sends the notification as a text message to the recipient's phone number.
+type SMSChannel struct {
+	gateway sms.Gateway
+}
+
+func NewSMSChannel(gateway sms.Gateway) NotificationChannel {
+	return &SMSChannel{gateway: gateway}
+}
+
+func (c *SMSChannel) Name() string {
+	return ChannelSMS
+}
+
+func (c *SMSChannel) Send(recipient Recipient, title string, body string) error {
+	if recipient.Phone == "" {
+		return utils.ErrRecipientUnreachable
+	}
+
+	return c.gateway.SendSMS(context.Background(), recipient.Phone, fmt.Sprintf("%s\n%s", title, body))
+}
+
+// LogChannel only logs the notifications. It is used for local development where there are no FCM credentials or SMS gateway.
+type LogChannel struct {
+	name   string
+	logger logrus.FieldLogger
+}
+
+func NewLogChannel(name string, logger logrus.FieldLogger) NotificationChannel {
+	return &LogChannel{name: name, logger: logger}
+}
+
+func (c *LogChannel) Name() string {
+	return c.name
+}
+
+func (c *LogChannel) Send(recipient Recipient, title string, body string) error {
+	c.logger.Infof("[%s notification] to %s: %s - %s", c.name, recipient.ID, title, body)
+	return nil
+}
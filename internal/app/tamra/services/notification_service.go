@@ -1,38 +1,178 @@
 package services
 
 import (
-	"context"
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"errors"
 	"fmt"
+	"time"
 
-	"firebase.google.com/go/messaging"
 	"github.com/sirupsen/logrus"
 )
 
+// NotificationEvent describes why a notification is sent. Each event has its own channel policy.
+type NotificationEvent string
+
+const (
+	EventNewOrder       NotificationEvent = "NEW_ORDER"
+	EventOrderCancelled NotificationEvent = "ORDER_CANCELLED"
+	EventOrderAccepted  NotificationEvent = "ORDER_ACCEPTED"
+	EventOrderRejected  NotificationEvent = "ORDER_REJECTED"
+	EventOrderExpired   NotificationEvent = "ORDER_EXPIRED"
+	// EventDriverPenalized tells a driver they won't receive orders until their cooldown is over
+	EventDriverPenalized NotificationEvent = "DRIVER_PENALIZED"
+)
+
+// Recipient holds every address someone can be reached at. Channels skip the addresses they don't use.
+type Recipient struct {
+	ID        string
+	FCMTokens []string
+	Phone     string
+}
+
+type Notification struct {
+	Event NotificationEvent
+	Title string
+	Body  string
+	// OrderID is the order the notification asks the driver to respond to. The delayed fallback is only sent if the order
+	// is still pending and never reached the driver's device. Notifications without it never get a delayed fallback.
+	OrderID int
+}
+
+// ChannelPolicy decides which channels a notification event is sent on.
+type ChannelPolicy struct {
+	// Channels are the channels the notification is sent on right away
+	Channels []string
+	// Fallback is the channel used when none of the channels above delivered the notification,
+	// or when the notification isn't acknowledged within FallbackAfter
+	Fallback string
+	// FallbackAfter is how long to wait for an acknowledgement before using the fallback. Zero means only fall back on failures
+	FallbackAfter time.Duration
+}
+
+// NotificationPolicy maps every event to its channel policy. Events without a policy are sent as push notifications.
+type NotificationPolicy map[NotificationEvent]ChannelPolicy
+
+// DefaultNotificationPolicy sends everything as a push notification. New orders and cancellations fall back to SMS since
+// drivers act on them, and a new order that isn't acknowledged within newOrderFallbackAfter is also sent by SMS.
+func DefaultNotificationPolicy(newOrderFallbackAfter time.Duration) NotificationPolicy {
+	return NotificationPolicy{
+		EventNewOrder:       {Channels: []string{ChannelPush}, Fallback: ChannelSMS, FallbackAfter: newOrderFallbackAfter},
+		EventOrderCancelled: {Channels: []string{ChannelPush}, Fallback: ChannelSMS},
+		EventOrderAccepted:  {Channels: []string{ChannelPush}},
+		EventOrderRejected:  {Channels: []string{ChannelPush}},
+		EventOrderExpired:   {Channels: []string{ChannelPush}},
+		// The driver can't do anything about a penalty, so it isn't worth an SMS
+		EventDriverPenalized: {Channels: []string{ChannelPush}},
+	}
+}
+
 type NotificationService interface {
-	NotifyUser(fcmToken string, title string, body string) error
+	// Notify sends the notification on the channels of the event policy. It only fails if no channel delivered it.
+	Notify(recipient Recipient, notification Notification) error
+	// SendDueFallbacks sends the delayed fallbacks whose order wasn't acknowledged in time
+	SendDueFallbacks() error
 }
 
+// NotificationServiceImpl is a composite of notification channels that routes every notification according to the policy.
 type NotificationServiceImpl struct {
-	logger          logrus.FieldLogger
-	messagingClient *messaging.Client
+	notificationRepository repositories.NotificationRepository
+	logger                 logrus.FieldLogger
+	policy                 NotificationPolicy
+	channels               map[string]NotificationChannel
+	// now is replaced in tests
+	now func() time.Time
 }
 
-func NewNotificationService(logger logrus.FieldLogger, messagingClient *messaging.Client) NotificationService {
-	return &NotificationServiceImpl{logger: logger, messagingClient: messagingClient}
+func NewNotificationService(notificationRepository repositories.NotificationRepository, logger logrus.FieldLogger, policy NotificationPolicy, channels ...NotificationChannel) NotificationService {
+	channelsByName := make(map[string]NotificationChannel, len(channels))
+	for _, channel := range channels {
+		channelsByName[channel.Name()] = channel
+	}
+	return &NotificationServiceImpl{notificationRepository: notificationRepository, logger: logger, policy: policy, channels: channelsByName, now: time.Now}
+}
+
+func (ns *NotificationServiceImpl) Notify(recipient Recipient, notification Notification) error {
+	policy, ok := ns.policy[notification.Event]
+	if !ok {
+		policy = ChannelPolicy{Channels: []string{ChannelPush}}
+	}
+
+	var errs []error
+	delivered := false
+	for _, channelName := range policy.Channels {
+		err := ns.send(channelName, recipient, notification)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delivered = true
+	}
+
+	if !delivered && policy.Fallback != "" {
+		ns.logger.Infof("Notification %s to %s was not delivered, falling back to %s", notification.Event, recipient.ID, policy.Fallback)
+		err := ns.send(policy.Fallback, recipient, notification)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			delivered = true
+		}
+	} else if delivered && policy.Fallback != "" && policy.FallbackAfter > 0 && notification.OrderID != 0 {
+		// The fallback is stored and sent by SendDueFallbacks since timers don't fire once a Lambda instance returned the response
+		_, err := ns.notificationRepository.CreateNotificationFallback(&models.NotificationFallback{
+			OrderID:     notification.OrderID,
+			Channel:     policy.Fallback,
+			Event:       string(notification.Event),
+			RecipientID: recipient.ID,
+			Phone:       recipient.Phone,
+			FCMTokens:   recipient.FCMTokens,
+			Title:       notification.Title,
+			Body:        notification.Body,
+			SendAt:      ns.now().Add(policy.FallbackAfter),
+		})
+		if err != nil {
+			// The notification was delivered, only its fallback is lost
+			ns.logger.WithError(err).Errorf("Failed to schedule fallback notification %s to %s", notification.Event, recipient.ID)
+		}
+	}
+
+	if !delivered {
+		return fmt.Errorf("failed to deliver notification %s: %w", notification.Event, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (ns *NotificationServiceImpl) SendDueFallbacks() error {
+	fallbacks, err := ns.notificationRepository.ClaimDueNotificationFallbacks(ns.now(), 100)
+	if err != nil {
+		return fmt.Errorf("failed to claim due notification fallbacks: %w", err)
+	}
+
+	// Claimed fallbacks are not retried, a failure is only logged
+	for _, fallback := range fallbacks {
+		ns.logger.Infof("Notification %s to %s was not acknowledged in time, falling back to %s", fallback.Event, fallback.RecipientID, fallback.Channel)
+		recipient := Recipient{ID: fallback.RecipientID, FCMTokens: fallback.FCMTokens, Phone: fallback.Phone}
+		notification := Notification{Event: NotificationEvent(fallback.Event), Title: fallback.Title, Body: fallback.Body, OrderID: fallback.OrderID}
+		err := ns.send(fallback.Channel, recipient, notification)
+		if err != nil {
+			ns.logger.WithError(err).Errorf("Failed to send fallback notification %s to %s", fallback.Event, fallback.RecipientID)
+		}
+	}
+
+	return nil
 }
 
-func (ns NotificationServiceImpl) NotifyUser(fcmToken string, title string, body string) error {
-	message := &messaging.Message{
-		Notification: &messaging.Notification{
-			Title: "New Order Received",
-			Body:  "You have a new order",
-		},
-		Token: fcmToken,
+func (ns *NotificationServiceImpl) send(channelName string, recipient Recipient, notification Notification) error {
+	channel, ok := ns.channels[channelName]
+	if !ok {
+		return fmt.Errorf("notification channel %s is not configured: %w", channelName, utils.ErrRecipientUnreachable)
 	}
 
-	_, err := ns.messagingClient.Send(context.Background(), message)
+	err := channel.Send(recipient, notification.Title, notification.Body)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to send notification on %s: %w", channelName, err)
 	}
 	return nil
 }
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChannel records the notifications it sends, or fails with err
+type fakeChannel struct {
+	name string
+	err  error
+	sent []string
+}
+
+func (c *fakeChannel) Name() string {
+	return c.name
+}
+
+func (c *fakeChannel) Send(recipient Recipient, title string, body string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.sent = append(c.sent, recipient.ID+": "+title)
+	return nil
+}
+
+// fakeNotificationRepository keeps the fallbacks in memory. Fallbacks of acknowledged orders are claimed but not returned, like in the database
+type fakeNotificationRepository struct {
+	fallbacks    []*models.NotificationFallback
+	acknowledged map[int]bool
+	err          error
+}
+
+func (r *fakeNotificationRepository) CreateNotificationFallback(fallback *models.NotificationFallback) (*models.NotificationFallback, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	fallback.ID = len(r.fallbacks) + 1
+	r.fallbacks = append(r.fallbacks, fallback)
+	return fallback, nil
+}
+
+func (r *fakeNotificationRepository) ClaimDueNotificationFallbacks(now time.Time, limit int) ([]*models.NotificationFallback, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var due, remaining []*models.NotificationFallback
+	for _, fallback := range r.fallbacks {
+		if fallback.SendAt.After(now) || len(due) == limit {
+			remaining = append(remaining, fallback)
+			continue
+		}
+		if !r.acknowledged[fallback.OrderID] {
+			due = append(due, fallback)
+		}
+	}
+	r.fallbacks = remaining
+	return due, nil
+}
+
+// fakeGateway records the text messages it sends
+type fakeGateway struct {
+	to      []string
+	message []string
+}
+
+func (g *fakeGateway) SendSMS(ctx context.Context, to string, message string) error {
+	g.to = append(g.to, to)
+	g.message = append(g.message, message)
+	return nil
+}
+
+func newTestLogger() logrus.FieldLogger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// newTestNotificationService returns a notification service whose clock only moves when the returned function is called
+func newTestNotificationService(repository *fakeNotificationRepository, policy NotificationPolicy, channels ...NotificationChannel) (*NotificationServiceImpl, func(time.Duration)) {
+	ns := NewNotificationService(repository, newTestLogger(), policy, channels...).(*NotificationServiceImpl)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ns.now = func() time.Time { return now }
+	return ns, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestNotificationService_Notify(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush}
+	sms := &fakeChannel{name: ChannelSMS}
+	ns, _ := newTestNotificationService(&fakeNotificationRepository{}, DefaultNotificationPolicy(2*time.Minute), push, sms)
+
+	err := ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventOrderAccepted, Title: "accepted"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user1: accepted"}, push.sent)
+	assert.Empty(t, sms.sent)
+
+	// Events without a policy are sent as push notifications
+	err = ns.Notify(Recipient{ID: "user1"}, Notification{Event: "UNKNOWN", Title: "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user1: accepted", "user1: unknown"}, push.sent)
+	assert.Empty(t, sms.sent)
+}
+
+func TestNotificationService_NotifyFallsBackOnFailure(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush, err: utils.ErrRecipientUnreachable}
+	sms := &fakeChannel{name: ChannelSMS}
+	ns, _ := newTestNotificationService(&fakeNotificationRepository{}, DefaultNotificationPolicy(2*time.Minute), push, sms)
+
+	err := ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventOrderCancelled, Title: "cancelled"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user1: cancelled"}, sms.sent)
+
+	// Events without a fallback fail when the push notification fails
+	err = ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventOrderAccepted, Title: "accepted"})
+	assert.ErrorIs(t, err, utils.ErrRecipientUnreachable)
+	assert.Equal(t, []string{"user1: cancelled"}, sms.sent)
+}
+
+func TestNotificationService_NotifyFailsWhenNoChannelDelivers(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush, err: errors.New("push failed")}
+	sms := &fakeChannel{name: ChannelSMS, err: errors.New("sms failed")}
+	ns, _ := newTestNotificationService(&fakeNotificationRepository{}, DefaultNotificationPolicy(2*time.Minute), push, sms)
+
+	err := ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventNewOrder, Title: "new order", OrderID: 1})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "push failed")
+	assert.Contains(t, err.Error(), "sms failed")
+
+	// A channel that isn't configured is unreachable
+	ns, _ = newTestNotificationService(&fakeNotificationRepository{}, DefaultNotificationPolicy(2*time.Minute))
+	err = ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventOrderAccepted, Title: "accepted"})
+	assert.ErrorIs(t, err, utils.ErrRecipientUnreachable)
+}
+
+func TestNotificationService_DelayedFallback(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush}
+	sms := &fakeChannel{name: ChannelSMS}
+	repository := &fakeNotificationRepository{acknowledged: map[int]bool{}}
+	ns, advance := newTestNotificationService(repository, DefaultNotificationPolicy(2*time.Minute), push, sms)
+
+	err := ns.Notify(Recipient{ID: "user1", Phone: "+201000000000"}, Notification{Event: EventNewOrder, Title: "new order", OrderID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user1: new order"}, push.sent)
+	assert.Len(t, repository.fallbacks, 1)
+	assert.Equal(t, ChannelSMS, repository.fallbacks[0].Channel)
+	assert.Equal(t, ns.now().Add(2*time.Minute), repository.fallbacks[0].SendAt)
+
+	// The fallback isn't sent before it is due
+	advance(time.Minute)
+	err = ns.SendDueFallbacks()
+	assert.NoError(t, err)
+	assert.Empty(t, sms.sent)
+
+	advance(time.Minute)
+	err = ns.SendDueFallbacks()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user1: new order"}, sms.sent)
+	assert.Empty(t, repository.fallbacks)
+
+	// It is only sent once
+	err = ns.SendDueFallbacks()
+	assert.NoError(t, err)
+	assert.Len(t, sms.sent, 1)
+}
+
+func TestNotificationService_DelayedFallbackOfAcknowledgedOrder(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush}
+	sms := &fakeChannel{name: ChannelSMS}
+	repository := &fakeNotificationRepository{acknowledged: map[int]bool{}}
+	ns, advance := newTestNotificationService(repository, DefaultNotificationPolicy(2*time.Minute), push, sms)
+
+	err := ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventNewOrder, Title: "new order", OrderID: 1})
+	assert.NoError(t, err)
+
+	repository.acknowledged[1] = true
+	advance(2 * time.Minute)
+	err = ns.SendDueFallbacks()
+	assert.NoError(t, err)
+	assert.Empty(t, sms.sent)
+	assert.Empty(t, repository.fallbacks)
+}
+
+func TestNotificationService_NoDelayedFallback(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush}
+	sms := &fakeChannel{name: ChannelSMS}
+	repository := &fakeNotificationRepository{}
+
+	// Notifications that aren't about an order can't be acknowledged, so they don't get a delayed fallback
+	ns, _ := newTestNotificationService(repository, DefaultNotificationPolicy(2*time.Minute), push, sms)
+	err := ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventNewOrder, Title: "new order"})
+	assert.NoError(t, err)
+	assert.Empty(t, repository.fallbacks)
+
+	// Neither do they when the delay is disabled
+	ns, _ = newTestNotificationService(repository, DefaultNotificationPolicy(0), push, sms)
+	err = ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventNewOrder, Title: "new order", OrderID: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, repository.fallbacks)
+}
+
+func TestNotificationService_NotifyWhenFallbackCantBeStored(t *testing.T) {
+	push := &fakeChannel{name: ChannelPush}
+	repository := &fakeNotificationRepository{err: errors.New("database is down")}
+	ns, _ := newTestNotificationService(repository, DefaultNotificationPolicy(2*time.Minute), push)
+
+	// The notification was delivered, so losing its fallback doesn't fail it
+	err := ns.Notify(Recipient{ID: "user1"}, Notification{Event: EventNewOrder, Title: "new order", OrderID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, push.sent, 1)
+
+	err = ns.SendDueFallbacks()
+	assert.Error(t, err)
+}
+
+func TestSMSChannel_Send(t *testing.T) {
+	gateway := &fakeGateway{}
+	channel := NewSMSChannel(gateway)
+
+	err := channel.Send(Recipient{ID: "user1"}, "title", "body")
+	assert.ErrorIs(t, err, utils.ErrRecipientUnreachable)
+	assert.Empty(t, gateway.to)
+
+	err = channel.Send(Recipient{ID: "user1", Phone: "+201000000000"}, "title", "body")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+201000000000"}, gateway.to)
+	assert.Equal(t, []string{"title\nbody"}, gateway.message)
+}
+
+func TestLogChannel_Send(t *testing.T) {
+	channel := NewLogChannel(ChannelSMS, newTestLogger())
+	assert.Equal(t, ChannelSMS, channel.Name())
+	assert.NoError(t, channel.Send(Recipient{ID: "user1"}, "title", "body"))
+}
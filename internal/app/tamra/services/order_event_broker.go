@@ -0,0 +1,145 @@
+package services
+
+import (
+	"Tamra/internal/pkg/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// orderEventsChannel is the Postgres channel the order events are sent on
+const orderEventsChannel = "order_events"
+
+// subscriberBufferSize is how many events a subscriber can fall behind before events are dropped for it
+const subscriberBufferSize = 32
+
+// OrderEventBroker delivers order events to whoever is subscribed to them, e.g. the open Server-Sent Events streams.
+type OrderEventBroker interface {
+	Publish(event *models.OrderEvent) error
+	// Subscribe returns a channel receiving the events that match the filter and a function to unsubscribe
+	Subscribe(filter func(event *models.OrderEvent) bool) (<-chan *models.OrderEvent, func())
+}
+
+type subscription struct {
+	events chan *models.OrderEvent
+	filter func(event *models.OrderEvent) bool
+}
+
+// InProcessOrderEventBroker fans events out to the subscribers of this process only.
+type InProcessOrderEventBroker struct {
+	mu            sync.RWMutex
+	subscriptions map[*subscription]struct{}
+	logger        logrus.FieldLogger
+}
+
+func NewInProcessOrderEventBroker(logger logrus.FieldLogger) *InProcessOrderEventBroker {
+	return &InProcessOrderEventBroker{subscriptions: map[*subscription]struct{}{}, logger: logger}
+}
+
+func (b *InProcessOrderEventBroker) Publish(event *models.OrderEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscriptions {
+		if !sub.filter(event) {
+			continue
+		}
+		// A slow subscriber must not block the others or the request that published the event
+		select {
+		case sub.events <- event:
+		default:
+			b.logger.Warnf("Dropping %s event of order %d for a slow subscriber", event.Type, event.OrderID)
+		}
+	}
+	return nil
+}
+
+func (b *InProcessOrderEventBroker) Subscribe(filter func(event *models.OrderEvent) bool) (<-chan *models.OrderEvent, func()) {
+	sub := &subscription{events: make(chan *models.OrderEvent, subscriberBufferSize), filter: filter}
+
+	b.mu.Lock()
+	b.subscriptions[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscriptions, sub)
+			b.mu.Unlock()
+			close(sub.events)
+		})
+	}
+	return sub.events, unsubscribe
+}
+
+// PostgresOrderEventBroker publishes events with NOTIFY so every instance of the API sees them.
+// Events are only delivered to the local subscribers once they come back through LISTEN, including the ones published by this instance.
+type PostgresOrderEventBroker struct {
+	db     *sql.DB
+	local  *InProcessOrderEventBroker
+	logger logrus.FieldLogger
+}
+
+func NewPostgresOrderEventBroker(db *sql.DB, logger logrus.FieldLogger) *PostgresOrderEventBroker {
+	return &PostgresOrderEventBroker{db: db, local: NewInProcessOrderEventBroker(logger), logger: logger}
+}
+
+func (b *PostgresOrderEventBroker) Publish(event *models.OrderEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode order event: %w", err)
+	}
+
+	_, err = b.db.Exec("SELECT pg_notify($1, $2)", orderEventsChannel, string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to notify order event: %w", err)
+	}
+	return nil
+}
+
+func (b *PostgresOrderEventBroker) Subscribe(filter func(event *models.OrderEvent) bool) (<-chan *models.OrderEvent, func()) {
+	return b.local.Subscribe(filter)
+}
+
+// Listen forwards the events of every instance to the local subscribers. It blocks, so it is meant to run in its own goroutine.
+// It is only needed by instances that serve streams, publishing works without it (e.g. on Lambda).
+func (b *PostgresOrderEventBroker) Listen(connectionString string) error {
+	listener := pq.NewListener(connectionString, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			b.logger.WithError(err).Errorf("Order events listener connection event %d", event)
+		}
+	})
+	defer listener.Close()
+
+	err := listener.Listen(orderEventsChannel)
+	if err != nil {
+		return fmt.Errorf("failed to listen to order events: %w", err)
+	}
+
+	for {
+		select {
+		case notification := <-listener.Notify:
+			// A nil notification means the connection was re-established, events sent in between are lost
+			if notification == nil {
+				continue
+			}
+
+			event := &models.OrderEvent{}
+			err := json.Unmarshal([]byte(notification.Extra), event)
+			if err != nil {
+				b.logger.WithError(err).Error("Failed to decode order event")
+				continue
+			}
+			b.local.Publish(event)
+		case <-time.After(90 * time.Second):
+			// Check that the connection is still alive since we would not notice otherwise
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"Tamra/internal/pkg/models"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func allEvents(event *models.OrderEvent) bool {
+	return true
+}
+
+// receivedEvents returns the IDs of the orders whose events are waiting in the channel
+func receivedEvents(events <-chan *models.OrderEvent) []int {
+	orderIDs := []int{}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return orderIDs
+			}
+			orderIDs = append(orderIDs, event.OrderID)
+		default:
+			return orderIDs
+		}
+	}
+}
+
+func TestInProcessOrderEventBroker_FanOut(t *testing.T) {
+	broker := NewInProcessOrderEventBroker(newTestLogger())
+	first, unsubscribeFirst := broker.Subscribe(allEvents)
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := broker.Subscribe(allEvents)
+	defer unsubscribeSecond()
+
+	assert.NoError(t, broker.Publish(&models.OrderEvent{Type: models.OrderEventCreated, OrderID: 1}))
+	assert.NoError(t, broker.Publish(&models.OrderEvent{Type: models.OrderEventAccepted, OrderID: 2}))
+
+	// Every subscriber receives every event, in the order they were published
+	assert.Equal(t, []int{1, 2}, receivedEvents(first))
+	assert.Equal(t, []int{1, 2}, receivedEvents(second))
+}
+
+func TestInProcessOrderEventBroker_Filter(t *testing.T) {
+	broker := NewInProcessOrderEventBroker(newTestLogger())
+	restaurant, unsubscribe := broker.Subscribe(func(event *models.OrderEvent) bool {
+		return event.RestaurantID == "restaurant1"
+	})
+	defer unsubscribe()
+
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 1, RestaurantID: "restaurant1"}))
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 2, RestaurantID: "restaurant2"}))
+
+	assert.Equal(t, []int{1}, receivedEvents(restaurant))
+}
+
+func TestInProcessOrderEventBroker_Unsubscribe(t *testing.T) {
+	broker := NewInProcessOrderEventBroker(newTestLogger())
+	events, unsubscribe := broker.Subscribe(allEvents)
+	other, unsubscribeOther := broker.Subscribe(allEvents)
+	defer unsubscribeOther()
+
+	unsubscribe()
+	assert.Len(t, broker.subscriptions, 1)
+
+	// The channel is closed so the stream reading it stops
+	_, ok := <-events
+	assert.False(t, ok)
+
+	// Publishing after a subscriber left only reaches the others, and unsubscribing again is harmless
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 1}))
+	assert.Equal(t, []int{1}, receivedEvents(other))
+	unsubscribe()
+	assert.Len(t, broker.subscriptions, 1)
+}
+
+func TestInProcessOrderEventBroker_SlowSubscriber(t *testing.T) {
+	broker := NewInProcessOrderEventBroker(newTestLogger())
+	slow, unsubscribeSlow := broker.Subscribe(allEvents)
+	defer unsubscribeSlow()
+
+	// The events that don't fit in the buffer of a slow subscriber are dropped instead of blocking the publisher
+	for orderID := 1; orderID <= subscriberBufferSize+1; orderID++ {
+		assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: orderID}))
+	}
+	received := receivedEvents(slow)
+	assert.Len(t, received, subscriberBufferSize)
+	assert.Equal(t, subscriberBufferSize, received[len(received)-1])
+
+	// The subscriber receives the next events once it caught up
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 100}))
+	assert.Equal(t, []int{100}, receivedEvents(slow))
+}
+
+func TestPostgresOrderEventBroker_Subscribe(t *testing.T) {
+	// Without a database the events only reach the subscribers through the listener, which forwards them to the local broker
+	broker := NewPostgresOrderEventBroker(nil, newTestLogger())
+	events, unsubscribe := broker.Subscribe(allEvents)
+
+	assert.NoError(t, broker.local.Publish(&models.OrderEvent{OrderID: 1}))
+	assert.Equal(t, []int{1}, receivedEvents(events))
+
+	unsubscribe()
+	assert.Empty(t, broker.local.subscriptions)
+}
+
+func TestOrderService_SubscribeToOrderEvents(t *testing.T) {
+	broker := NewInProcessOrderEventBroker(newTestLogger())
+	orderRepository := &fakeOrderRepository{managedRestaurantIDs: map[string][]string{
+		"owner": {"owner", "branch"},
+		"other": {"other"},
+	}}
+	orderService := &OrderServiceImpl{orderRepository: orderRepository, orderEventBroker: broker, logger: newTestLogger()}
+
+	owner, unsubscribeOwner, err := orderService.SubscribeToOrderEvents("owner")
+	assert.NoError(t, err)
+	defer unsubscribeOwner()
+	driver, unsubscribeDriver, err := orderService.SubscribeToOrderEvents("driver")
+	assert.NoError(t, err)
+	defer unsubscribeDriver()
+
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 1, RestaurantID: "owner", UserID: "driver"}))
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 2, RestaurantID: "branch", UserID: "otherdriver"}))
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 3, RestaurantID: "other", UserID: "driver"}))
+	assert.NoError(t, broker.Publish(&models.OrderEvent{OrderID: 4, RestaurantID: "other", UserID: "otherdriver"}))
+
+	// Owners receive the events of the branches they manage, drivers the events of their own orders
+	assert.Equal(t, []int{1, 2}, receivedEvents(owner))
+	assert.Equal(t, []int{1, 3}, receivedEvents(driver))
+}
+
+func TestOrderService_SubscribeToOrderEventsFails(t *testing.T) {
+	broker := NewInProcessOrderEventBroker(newTestLogger())
+	orderRepository := &fakeOrderRepository{err: errors.New("database is down")}
+	orderService := &OrderServiceImpl{orderRepository: orderRepository, orderEventBroker: broker, logger: newTestLogger()}
+
+	_, _, err := orderService.SubscribeToOrderEvents("owner")
+	assert.Error(t, err)
+	assert.Empty(t, broker.subscriptions)
+}
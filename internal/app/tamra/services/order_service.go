@@ -4,6 +4,7 @@ import (
 	"Tamra/internal/app/tamra/repositories"
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,6 +17,8 @@ type OrderService interface {
 	GetUserOrders(userID string) ([]*models.Order, error)
 	// GetRestaurantOrders returns a list of orders for a restaurant
 	GetRestaurantOrders(restaurantID string) ([]*models.Order, error)
+	// GetOrganizationOrders returns the orders of all the branches of an organization, the newest first
+	GetOrganizationOrders(organizationID int) ([]*models.Order, error)
 	UpdateOrder(order *models.Order) (*models.Order, error)
 	DeleteOrder(id int) error
 	AcceptOrder(id int, fbUID string) error
@@ -23,18 +26,48 @@ type OrderService interface {
 	RejectOrder(id int, fbUID string) error
 	CancelOrder(id int, fbUID string) error
 	ReassignOrder(id int, fbUID string) error
+	// ForceCancelOrder cancels an order on behalf of its restaurant, used by the admins
+	ForceCancelOrder(id int) error
+	// ForceReassignOrder reassigns an order on behalf of its restaurant, used by the admins
+	ForceReassignOrder(id int) error
+	// SearchOrders returns a page of the orders matching the filter, the newest first, and how many match it
+	SearchOrders(filter *models.OrderFilter) ([]*models.Order, int, error)
+	// MarkOrderDelivered is called by the driver app when the new order notification arrives
+	MarkOrderDelivered(id int, fbUID string) error
+	// MarkOrderSeen is called by the driver app when the driver opens the order
+	MarkOrderSeen(id int, fbUID string) error
+	// ExpireStaleOrders expires the pending orders that drivers didn't respond to in time
+	ExpireStaleOrders() error
+	// GetOrderLocation returns the latest position and the path of the driver carrying an order of the restaurant
+	GetOrderLocation(id int, fbUID string) (*models.OrderLocation, error)
+	// PurgeLocationTrails deletes the location trails older than the retention period
+	PurgeLocationTrails() error
+	// SubscribeToOrderEvents returns the events of the orders the restaurant or driver with the given ID is part of.
+	// Restaurants that own an organization also receive the events of its branches
+	SubscribeToOrderEvents(fbUID string) (<-chan *models.OrderEvent, func(), error)
 }
 
+// orderResponseTimeout is how long a driver has to respond to an order before it expires
+const orderResponseTimeout = 15 * time.Minute
+
 type OrderServiceImpl struct {
-	orderRepository     repositories.OrderRepository
-	userRepository      repositories.UserRepository
-	notificationService NotificationService
-	logger              logrus.FieldLogger
+	orderRepository      repositories.OrderRepository
+	userRepository       repositories.UserRepository
+	restaurantRepository repositories.RestaurantRepository
+	notificationService  NotificationService
+	penaltyService       PenaltyService
+	orderEventBroker     OrderEventBroker
+	webhookService       WebhookService
+	// locationTrailRetention is how long the location trails of the orders are kept
+	locationTrailRetention time.Duration
+	// dispatchOptions tweak how the driver receiving a new order is picked
+	dispatchOptions repositories.DispatchOptions
+	logger          logrus.FieldLogger
 }
 
 // We return an implementation of the OrderService interface. This is so that we can easily swap out the implementation or mock it in tests.
-func NewOrderService(orderRepository repositories.OrderRepository, userRepository repositories.UserRepository, notificationService NotificationService, logger logrus.FieldLogger) OrderService {
-	return &OrderServiceImpl{orderRepository: orderRepository, userRepository: userRepository, notificationService: notificationService, logger: logger}
+func NewOrderService(orderRepository repositories.OrderRepository, userRepository repositories.UserRepository, restaurantRepository repositories.RestaurantRepository, notificationService NotificationService, penaltyService PenaltyService, orderEventBroker OrderEventBroker, webhookService WebhookService, locationTrailRetention time.Duration, dispatchOptions repositories.DispatchOptions, logger logrus.FieldLogger) OrderService {
+	return &OrderServiceImpl{orderRepository: orderRepository, userRepository: userRepository, restaurantRepository: restaurantRepository, notificationService: notificationService, penaltyService: penaltyService, orderEventBroker: orderEventBroker, webhookService: webhookService, locationTrailRetention: locationTrailRetention, dispatchOptions: dispatchOptions, logger: logger}
 }
 
 // We first generate a 6 digit random number as the code for the order
@@ -43,44 +76,68 @@ func NewOrderService(orderRepository repositories.OrderRepository, userRepositor
 // we then notify the user that a new order has been created
 // we then return the created order
 func (s *OrderServiceImpl) CreateOrder(order *models.Order) (*models.Order, error) {
+	// Restaurants only create orders while they are open, drivers would otherwise go pick up orders from a closed restaurant
+	availability, err := s.restaurantRepository.GetRestaurantAvailability(order.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restaurant availability: %w", err)
+	}
+	switch availability.ClosedReason {
+	case models.ClosedReasonPaused:
+		return nil, fmt.Errorf("restaurant %s can't create orders: %w", order.RestaurantID, utils.ErrRestaurantPaused)
+	case models.ClosedReasonClosure:
+		return nil, fmt.Errorf("restaurant %s can't create orders: %w", order.RestaurantID, utils.ErrRestaurantClosed)
+	case models.ClosedReasonOutsideOpeningHours:
+		return nil, fmt.Errorf("restaurant %s can't create orders: %w", order.RestaurantID, utils.ErrOutsideOpeningHours)
+	}
+
+	return s.dispatchOrder(order)
+}
+
+// dispatchOrder offers the order to a driver. Unlike CreateOrder it doesn't check if the restaurant is open, so orders
+// the restaurant already took can be reassigned after closing time
+func (s *OrderServiceImpl) dispatchOrder(order *models.Order) (*models.Order, error) {
 	// Generate a 6 digit random number as the code for the order
 	order.Code = utils.GenerateCode()
 
-	// Find which user to send to based on the last_order_received of the user
-	user, err := s.userRepository.GetUserToReceiveOrder(order.RestaurantID)
-	s.logger.Infof("User to receive order: %v", user)
-	if err != nil {
-		if err == utils.ErrNotFound {
-			return nil, fmt.Errorf("no user found to receive order: %w", err)
+	// Orders that don't say which vehicle they need use the default of the restaurant
+	if order.RequiredVehicle == "" {
+		restaurant, err := s.restaurantRepository.GetRestaurantByID(order.RestaurantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get restaurant: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get user to receive order: %w", err)
+		order.RequiredVehicle = restaurant.DefaultVehicle
 	}
 
-	order.UserID = user.ID
-	// Create the order and update the last_order_received of the user
-	order, err = s.orderRepository.CreateOrder(order)
-	s.logger.Infof("Order created: %v", order)
+	// Find which user to send to based on the last_order_received of the user, create the order for them
+	// and update their last_order_received
+	dispatchOptions := s.dispatchOptions
+	dispatchOptions.RequiredVehicle = order.RequiredVehicle
+	order, user, err := s.userRepository.AssignOrderToNextUser(order, dispatchOptions)
 	if err != nil {
+		if err == utils.ErrNotFound {
+			return nil, fmt.Errorf("no user found to receive order: %w", err)
+		}
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	s.logger.Infof("Order %d created for user %s", order.ID, user.ID)
+
+	s.publishOrderEvent(models.OrderEventCreated, order)
 
 	// Notify the user that a new order has been created.
 	// Ideally this would be done in a seperate service that handles notifications
-	err = s.notificationService.NotifyUser(user.FCMToken, "لديك طلب جديد", "انقر لعرض تفاصيل الطلب والرد عليه")
+	// If the driver doesn't respond in time the notification policy falls back to another channel (e.g. SMS)
+	err = s.notificationService.Notify(driverRecipient(user), Notification{
+		Event:   EventNewOrder,
+		Title:   "لديك طلب جديد",
+		Body:    "انقر لعرض تفاصيل الطلب والرد عليه",
+		OrderID: order.ID,
+	})
 	s.logger.Info("User notified")
 	if err != nil {
 
 		return nil, fmt.Errorf("failed to notify user: %w", err)
 	}
 
-	// Update the last_order_received date of the user in the database
-	user.LastOrderReceived = order.CreatedAt
-	_, err = s.userRepository.UpdateUser(user)
-	if err != nil {
-
-		return nil, fmt.Errorf("failed to update user: %w", err)
-	}
-
 	return order, nil
 }
 
@@ -90,25 +147,12 @@ func (s *OrderServiceImpl) GetUserOrders(userID string) ([]*models.Order, error)
 		return nil, fmt.Errorf("failed to get user orders: %w", err)
 	}
 
-	// Iterate over the orders and if the time since the order was created is more than 15 minutes, we update the order state to "REJECTED"
+	// Iterate over the orders and if the time since the order was created is more than 15 minutes, we expire the order
 	for _, order := range orders {
-		if order.CreatedAt.Add(15*time.Minute).Before(time.Now()) && order.State == "PENDING" {
-			s.logger.Infof("Order %d is more than 15 minutes old. Rejecting it", order.ID)
-			// Update the order state to "REJECTED". We could have used UpdateUserOrderState as well. It doesn't matter.
-			err = s.orderRepository.UpdateRestaurantOrderState(order.ID, order.RestaurantID, "EXPIRED")
-			if err != nil {
-				return nil, fmt.Errorf("failed to reject order: %w", err)
-			}
-			// Update the user is_active to false
-			user, err := s.userRepository.GetUser(userID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get user: %w", err)
-			}
-
-			user.IsActive = false
-			_, err = s.userRepository.UpdateUser(user)
+		if order.CreatedAt.Add(orderResponseTimeout).Before(time.Now()) && order.State == "PENDING" {
+			err = s.expireOrder(order)
 			if err != nil {
-				return nil, fmt.Errorf("failed to update user: %w", err)
+				return nil, err
 			}
 		}
 	}
@@ -130,32 +174,42 @@ func (s *OrderServiceImpl) GetRestaurantOrders(restaurantID string) ([]*models.O
 	}
 
 	for _, order := range orders {
-		if order.CreatedAt.Add(15*time.Minute).Before(time.Now()) && order.State == "PENDING" {
-			s.logger.Infof("Order %d is more than 15 minutes old. Rejecting it", order.ID)
-			// Update the order state to "REJECTED". We could have used UpdateUserOrderState as well. It doesn't matter.
-			err = s.orderRepository.UpdateRestaurantOrderState(order.ID, order.RestaurantID, "EXPIRED")
+		if order.CreatedAt.Add(orderResponseTimeout).Before(time.Now()) && order.State == "PENDING" {
+			err = s.expireOrder(order)
 			if err != nil {
-				return nil, fmt.Errorf("failed to reject order: %w", err)
+				return nil, err
 			}
+		}
+	}
 
-			// Update the user is_active to false
-			user, err := s.userRepository.GetUser(order.UserID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get user: %w", err)
-			}
+	// Get the updated list of orders
+	orders, err = s.orderRepository.GetRestaurantOrders(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restaurant orders: %w", err)
+	}
 
-			user.IsActive = false
-			_, err = s.userRepository.UpdateUser(user)
+	return orders, nil
+}
+
+func (s *OrderServiceImpl) GetOrganizationOrders(organizationID int) ([]*models.Order, error) {
+	orders, err := s.orderRepository.GetOrganizationOrders(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization orders: %w", err)
+	}
+
+	for _, order := range orders {
+		if order.CreatedAt.Add(orderResponseTimeout).Before(time.Now()) && order.State == "PENDING" {
+			err = s.expireOrder(order)
 			if err != nil {
-				return nil, fmt.Errorf("failed to update user: %w", err)
+				return nil, err
 			}
 		}
 	}
 
 	// Get the updated list of orders
-	orders, err = s.orderRepository.GetRestaurantOrders(restaurantID)
+	orders, err = s.orderRepository.GetOrganizationOrders(organizationID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get restaurant orders: %w", err)
+		return nil, fmt.Errorf("failed to get organization orders: %w", err)
 	}
 
 	return orders, nil
@@ -177,15 +231,26 @@ func (s *OrderServiceImpl) AcceptOrder(id int, fbUID string) error {
 		return fmt.Errorf("failed to accept order: %w", err)
 	}
 
+	order, err := s.orderRepository.GetOrderByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	s.publishOrderEvent(models.OrderEventAccepted, order)
+	s.notifyRestaurant(order.RestaurantID, EventOrderAccepted, "تم قبول الطلب", fmt.Sprintf("قبل السائق الطلب رقم %s", order.Code))
+
 	return nil
 }
 
 func (s *OrderServiceImpl) FulfillOrder(id int, fbUID string) error {
-	err := s.orderRepository.UpdateRestaurantOrderState(id, fbUID, "FULFILLED")
+	// The event is only published when the order was actually fulfilled, integrators act on it
+	order, err := s.orderRepository.UpdateRestaurantOrderState(id, fbUID, "FULFILLED")
 	if err != nil {
 		return fmt.Errorf("failed to fulfill order: %w", err)
 	}
 
+	s.publishOrderEvent(models.OrderEventFulfilled, order)
+
 	return nil
 }
 
@@ -195,16 +260,25 @@ func (s *OrderServiceImpl) RejectOrder(id int, fbUID string) error {
 		return fmt.Errorf("failed to reject order: %w", err)
 	}
 
+	order, err := s.orderRepository.GetOrderByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	s.publishOrderEvent(models.OrderEventRejected, order)
+	s.notifyRestaurant(order.RestaurantID, EventOrderRejected, "تم رفض الطلب", fmt.Sprintf("رفض السائق الطلب رقم %s، يمكنك إعادة تعيينه لسائق آخر", order.Code))
+
 	return nil
 }
 
 func (s *OrderServiceImpl) CancelOrder(id int, fbUID string) error {
-	err := s.orderRepository.UpdateRestaurantOrderState(id, fbUID, "CANCELLED")
+	// The event is only published when the order was actually cancelled, integrators act on it
+	order, err := s.orderRepository.UpdateRestaurantOrderState(id, fbUID, "CANCELLED")
 	if err != nil {
 		return fmt.Errorf("failed to cancel order: %w", err)
 	}
 
-	order, err := s.orderRepository.GetOrder(id, fbUID)
+	s.publishOrderEvent(models.OrderEventCancelled, order)
 
 	// Get the user to send the notification to
 	user, err := s.userRepository.GetUser(order.UserID)
@@ -214,45 +288,108 @@ func (s *OrderServiceImpl) CancelOrder(id int, fbUID string) error {
 
 	s.logger.Infof("Notifying user %s that their order has been cancelled", user.ID)
 	// Notify the user that the order has been cancelled
-	err = s.notificationService.NotifyUser(user.FCMToken, "تم الغاء طلبك", "قام المطعم بالغاء طلبك")
+	err = s.notificationService.Notify(driverRecipient(user), Notification{
+		Event: EventOrderCancelled,
+		Title: "تم الغاء طلبك",
+		Body:  "قام المطعم بالغاء طلبك",
+	})
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to notify user %s that order %d was cancelled", user.ID, id)
+	}
 
 	return nil
 }
 
 // TODO: Turn this into a transaction
 func (s *OrderServiceImpl) ReassignOrder(id int, fbUID string) error {
-	// Update the order state to "REJECTED"
-	err := s.orderRepository.UpdateRestaurantOrderState(id, fbUID, "EXPIRED")
+	// Get the order before it expires to know if the driver was still holding it
+	order, err := s.orderRepository.GetOrder(id, fbUID)
+	if err != nil {
+
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	previousState := order.State
+
+	// Update the order state to "EXPIRED". Fulfilled and cancelled orders can't be reassigned, it would deliver them twice
+	expiredOrder, err := s.orderRepository.UpdateRestaurantOrderState(id, fbUID, "EXPIRED")
 	if err != nil {
 
 		return fmt.Errorf("failed to reassign order: %w", err)
 	}
+	order.State = expiredOrder.State
 
-	// Get the order
-	order, err := s.orderRepository.GetOrder(id, fbUID)
+	s.publishOrderEvent(models.OrderEventExpired, order)
+
+	// Drivers who rejected the order released it, the others are penalized for making the restaurant wait
+	if previousState == "PENDING" || previousState == "ACCEPTED" {
+		s.penalizeDriver(order, models.PenaltyReasonOrderReassigned)
+	}
+
+	// Create the new order. The restaurant may have closed since it took the order, which must not keep the order from being delivered
+	_, err = s.dispatchOrder(order)
 	if err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
 
+	return nil
+}
+
+func (s *OrderServiceImpl) ForceCancelOrder(id int) error {
+	order, err := s.orderRepository.GetOrderByID(id)
+	if err != nil {
 		return fmt.Errorf("failed to get order: %w", err)
 	}
 
-	// Get the user and update the is_active to false
-	user, err := s.userRepository.GetUser(order.UserID)
+	// The order is cancelled as if its restaurant did it, so the driver is notified the same way
+	return s.CancelOrder(id, order.RestaurantID)
+}
+
+func (s *OrderServiceImpl) ForceReassignOrder(id int) error {
+	order, err := s.orderRepository.GetOrderByID(id)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	return s.ReassignOrder(id, order.RestaurantID)
+}
+
+func (s *OrderServiceImpl) SearchOrders(filter *models.OrderFilter) ([]*models.Order, int, error) {
+	orders, total, err := s.orderRepository.SearchOrders(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search orders: %w", err)
 	}
+	return orders, total, nil
+}
 
-	user.IsActive = false
-	_, err = s.userRepository.UpdateUser(user)
+func (s *OrderServiceImpl) MarkOrderDelivered(id int, fbUID string) error {
+	err := s.orderRepository.MarkOrderDelivered(id, fbUID)
 	if err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+		return fmt.Errorf("failed to mark order as delivered: %w", err)
 	}
 
-	// Create the new order
-	_, err = s.CreateOrder(order)
+	order, err := s.orderRepository.GetOrderByID(id)
 	if err != nil {
-		return fmt.Errorf("failed to create order: %w", err)
+		return fmt.Errorf("failed to get order: %w", err)
 	}
 
+	s.publishOrderEvent(models.OrderEventDelivered, order)
+
+	return nil
+}
+
+func (s *OrderServiceImpl) MarkOrderSeen(id int, fbUID string) error {
+	err := s.orderRepository.MarkOrderSeen(id, fbUID)
+	if err != nil {
+		return fmt.Errorf("failed to mark order as seen: %w", err)
+	}
+
+	order, err := s.orderRepository.GetOrderByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	s.publishOrderEvent(models.OrderEventSeen, order)
+
 	return nil
 }
 
@@ -264,3 +401,162 @@ func (s *OrderServiceImpl) DeleteOrder(id int) error {
 	}
 	return nil
 }
+
+// ExpireStaleOrders is run periodically so that orders expire, and their events are published, even if nobody lists the orders
+func (s *OrderServiceImpl) ExpireStaleOrders() error {
+	orders, err := s.orderRepository.GetPendingOrdersCreatedBefore(time.Now().Add(-orderResponseTimeout))
+	if err != nil {
+		return fmt.Errorf("failed to get stale orders: %w", err)
+	}
+
+	for _, order := range orders {
+		err = s.expireOrder(order)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *OrderServiceImpl) GetOrderLocation(id int, fbUID string) (*models.OrderLocation, error) {
+	// GetOrder only returns the order if it belongs to the restaurant
+	order, err := s.orderRepository.GetOrder(id, fbUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	points, err := s.orderRepository.GetOrderLocationPoints(id, s.locationTrailRetention)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order location points: %w", err)
+	}
+
+	orderLocation := &models.OrderLocation{OrderID: order.ID, UserID: order.UserID, State: order.State, Path: points}
+	if len(points) > 0 {
+		orderLocation.Latest = points[len(points)-1]
+	}
+
+	return orderLocation, nil
+}
+
+func (s *OrderServiceImpl) PurgeLocationTrails() error {
+	deleted, err := s.orderRepository.DeleteOrderLocationPointsOlderThan(s.locationTrailRetention)
+	if err != nil {
+		return fmt.Errorf("failed to delete order location points: %w", err)
+	}
+
+	s.logger.Infof("Purged %d order location points", deleted)
+	return nil
+}
+
+func (s *OrderServiceImpl) SubscribeToOrderEvents(fbUID string) (<-chan *models.OrderEvent, func(), error) {
+	// The branches are resolved once, a branch created while the stream is open is only streamed after reconnecting.
+	// Drivers don't manage restaurants, only their own ID is returned for them
+	restaurantIDs, err := s.orderRepository.GetManagedRestaurantIDs(fbUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get managed restaurants: %w", err)
+	}
+	managedRestaurants := make(map[string]bool, len(restaurantIDs))
+	for _, restaurantID := range restaurantIDs {
+		managedRestaurants[restaurantID] = true
+	}
+
+	// Restaurant and driver IDs are both firebase UIDs, so the same filter works for both
+	events, unsubscribe := s.orderEventBroker.Subscribe(func(event *models.OrderEvent) bool {
+		return managedRestaurants[event.RestaurantID] || event.UserID == fbUID
+	})
+	return events, unsubscribe, nil
+}
+
+// expireOrder marks a pending order that the driver didn't respond to as expired, penalizes the driver and lets the restaurant know.
+// The order may have been accepted or expired by someone else since it was read, in which case there is nothing to do
+func (s *OrderServiceImpl) expireOrder(order *models.Order) error {
+	s.logger.Infof("Order %d is more than 15 minutes old. Expiring it", order.ID)
+	expiredOrder, err := s.orderRepository.ExpirePendingOrder(order.ID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			s.logger.Infof("Order %d isn't pending anymore. Skipping it", order.ID)
+			return nil
+		}
+		return fmt.Errorf("failed to expire order: %w", err)
+	}
+	*order = *expiredOrder
+	s.publishOrderEvent(models.OrderEventExpired, order)
+
+	s.penalizeDriver(order, models.PenaltyReasonOrderExpired)
+
+	s.notifyRestaurant(order.RestaurantID, EventOrderExpired, "انتهت مهلة الطلب", fmt.Sprintf("لم يرد السائق على الطلب رقم %s خلال 15 دقيقة، يمكنك إعادة تعيينه لسائق آخر", order.Code))
+
+	return nil
+}
+
+// penalizeDriver puts the driver of the order on a cooldown instead of deactivating them, so they are told why they don't
+// receive orders and receive them again once the cooldown is over.
+// The order already expired at this point, so failing to penalize is logged instead of keeping it from being redispatched
+func (s *OrderServiceImpl) penalizeDriver(order *models.Order, reason string) {
+	// The driver deleted their account since they received the order
+	if order.UserID == "" {
+		s.logger.Infof("Order %d has no driver anymore. Not penalizing anyone", order.ID)
+		return
+	}
+
+	user, err := s.userRepository.GetUser(order.UserID)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to get driver %s of order %d to penalize", order.UserID, order.ID)
+		return
+	}
+
+	_, err = s.penaltyService.PenalizeDriver(user, order, reason)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to penalize driver %s for order %d", order.UserID, order.ID)
+	}
+}
+
+// notifyRestaurant sends a notification to every device the restaurant registered.
+// The order transition already happened at this point, so failing to notify is logged instead of failing the request.
+func (s *OrderServiceImpl) notifyRestaurant(restaurantID string, event NotificationEvent, title string, body string) {
+	devices, err := s.restaurantRepository.GetRestaurantDevices(restaurantID)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to get devices of restaurant %s", restaurantID)
+		return
+	}
+
+	recipient := Recipient{ID: restaurantID}
+	for _, device := range devices {
+		recipient.FCMTokens = append(recipient.FCMTokens, device.FCMToken)
+	}
+
+	err = s.notificationService.Notify(recipient, Notification{Event: event, Title: title, Body: body})
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to notify restaurant %s", restaurantID)
+	}
+}
+
+// publishOrderEvent lets the subscribers (e.g. open streams) know that an order changed.
+// Like notifications, failing to publish doesn't fail the request since the order already changed.
+func (s *OrderServiceImpl) publishOrderEvent(eventType string, order *models.Order) {
+	event := &models.OrderEvent{
+		Type:         eventType,
+		OrderID:      order.ID,
+		RestaurantID: order.RestaurantID,
+		UserID:       order.UserID,
+		Code:         order.Code,
+		State:        order.State,
+		OccurredAt:   time.Now(),
+	}
+
+	err := s.orderEventBroker.Publish(event)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to publish %s event of order %d", eventType, order.ID)
+	}
+
+	err = s.webhookService.DispatchOrderEvent(event)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to dispatch %s event of order %d to webhooks", eventType, order.ID)
+	}
+}
+
+// driverRecipient returns every address a driver can be notified at
+func driverRecipient(user *models.User) Recipient {
+	return Recipient{ID: user.ID, FCMTokens: []string{user.FCMToken}, Phone: user.Phone}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOrderRepository keeps the orders in memory and returns the restaurants managed by each restaurant, the others manage none
+type fakeOrderRepository struct {
+	repositories.OrderRepository
+	orders               map[int]*models.Order
+	managedRestaurantIDs map[string][]string
+	err                  error
+}
+
+func (r *fakeOrderRepository) GetManagedRestaurantIDs(restaurantID string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.managedRestaurantIDs[restaurantID], nil
+}
+
+func (r *fakeOrderRepository) GetOrder(id int, fbUID string) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok || order.RestaurantID != fbUID {
+		return nil, utils.ErrNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrderRepository) GetOrderByID(id int) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// UpdateRestaurantOrderState refuses to move fulfilled and cancelled orders, and to fulfill orders that aren't accepted
+func (r *fakeOrderRepository) UpdateRestaurantOrderState(id int, fbUID string, state string) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok || order.RestaurantID != fbUID {
+		return nil, utils.ErrNotFound
+	}
+	if order.State == "FULFILLED" || order.State == "CANCELLED" || (state == "FULFILLED" && order.State != "ACCEPTED") {
+		return nil, utils.ErrInvalidOrderState
+	}
+	order.State = state
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrderRepository) ExpirePendingOrder(id int) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok || order.State != "PENDING" {
+		return nil, utils.ErrNotFound
+	}
+	order.State = "EXPIRED"
+	copied := *order
+	return &copied, nil
+}
+
+// AssignOrderToNextUser gives every order to the first user
+func (r *fakeUserRepository) AssignOrderToNextUser(order *models.Order, options repositories.DispatchOptions) (*models.Order, *models.User, error) {
+	if len(r.users) == 0 {
+		return nil, nil, utils.ErrNotFound
+	}
+	assigned := *order
+	assigned.ID = 100 + len(r.assignedOrders)
+	assigned.UserID = r.users[0].ID
+	assigned.State = "PENDING"
+	r.assignedOrders = append(r.assignedOrders, &assigned)
+	return &assigned, r.users[0], nil
+}
+
+func (r *fakeRestaurantRepository) GetRestaurantDevices(restaurantID string) ([]*models.RestaurantDevice, error) {
+	return []*models.RestaurantDevice{{FCMToken: restaurantID + "token"}}, nil
+}
+
+// fakePenaltyService records the drivers it penalizes, or fails with err
+type fakePenaltyService struct {
+	PenaltyService
+	err       error
+	penalized []string
+}
+
+func (s *fakePenaltyService) PenalizeDriver(user *models.User, order *models.Order, reason string) (*models.DriverPenalty, error) {
+	s.penalized = append(s.penalized, user.ID)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.DriverPenalty{UserID: user.ID, Reason: reason}, nil
+}
+
+// fakeNotificationService records the events it is asked to notify
+type fakeNotificationService struct {
+	NotificationService
+	events []NotificationEvent
+}
+
+func (s *fakeNotificationService) Notify(recipient Recipient, notification Notification) error {
+	s.events = append(s.events, notification.Event)
+	return nil
+}
+
+// fakeWebhookService records the types of the events it dispatches
+type fakeWebhookService struct {
+	WebhookService
+	events []string
+}
+
+func (s *fakeWebhookService) DispatchOrderEvent(event *models.OrderEvent) error {
+	s.events = append(s.events, event.Type)
+	return nil
+}
+
+type orderServiceFakes struct {
+	orderRepository     *fakeOrderRepository
+	userRepository      *fakeUserRepository
+	penaltyService      *fakePenaltyService
+	notificationService *fakeNotificationService
+	webhookService      *fakeWebhookService
+}
+
+func newTestOrderService(orders ...*models.Order) (*OrderServiceImpl, *orderServiceFakes) {
+	fakes := &orderServiceFakes{
+		orderRepository:     &fakeOrderRepository{orders: map[int]*models.Order{}},
+		userRepository:      &fakeUserRepository{users: []*models.User{{ID: "driver1"}, {ID: "driver2"}}},
+		penaltyService:      &fakePenaltyService{},
+		notificationService: &fakeNotificationService{},
+		webhookService:      &fakeWebhookService{},
+	}
+	for _, order := range orders {
+		fakes.orderRepository.orders[order.ID] = order
+	}
+	orderService := &OrderServiceImpl{
+		orderRepository:      fakes.orderRepository,
+		userRepository:       fakes.userRepository,
+		restaurantRepository: &fakeRestaurantRepository{},
+		notificationService:  fakes.notificationService,
+		penaltyService:       fakes.penaltyService,
+		orderEventBroker:     NewInProcessOrderEventBroker(newTestLogger()),
+		webhookService:       fakes.webhookService,
+		logger:               newTestLogger(),
+	}
+	return orderService, fakes
+}
+
+func TestOrderService_ReassignOrderWhenPenaltyFails(t *testing.T) {
+	orderService, fakes := newTestOrderService(&models.Order{ID: 1, RestaurantID: "restaurant1", UserID: "driver2", State: "PENDING", RequiredVehicle: models.VehicleTypeMotorcycle})
+	fakes.penaltyService.err = errors.New("database is down")
+
+	// The order is redispatched even though its driver couldn't be penalized
+	err := orderService.ReassignOrder(1, "restaurant1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"driver2"}, fakes.penaltyService.penalized)
+	assert.Equal(t, "EXPIRED", fakes.orderRepository.orders[1].State)
+	assert.Len(t, fakes.userRepository.assignedOrders, 1)
+	assert.Equal(t, []NotificationEvent{EventNewOrder}, fakes.notificationService.events)
+}
+
+func TestOrderService_ReassignOrderOfDeletedDriver(t *testing.T) {
+	// The driver deleted their account, which cleared the driver of the order
+	orderService, fakes := newTestOrderService(&models.Order{ID: 1, RestaurantID: "restaurant1", State: "ACCEPTED", RequiredVehicle: models.VehicleTypeMotorcycle})
+
+	err := orderService.ReassignOrder(1, "restaurant1")
+	assert.NoError(t, err)
+	assert.Empty(t, fakes.penaltyService.penalized)
+	assert.Len(t, fakes.userRepository.assignedOrders, 1)
+}
+
+func TestOrderService_ExpireOrderWhenPenaltyFails(t *testing.T) {
+	order := &models.Order{ID: 1, RestaurantID: "restaurant1", UserID: "driver1", State: "PENDING"}
+	orderService, fakes := newTestOrderService(order)
+	fakes.penaltyService.err = errors.New("database is down")
+
+	// The restaurant is told the order expired even though its driver couldn't be penalized
+	err := orderService.expireOrder(&models.Order{ID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPIRED", order.State)
+	assert.Equal(t, []string{"driver1"}, fakes.penaltyService.penalized)
+	assert.Equal(t, []NotificationEvent{EventOrderExpired}, fakes.notificationService.events)
+
+	// Orders that aren't pending anymore are skipped
+	err = orderService.expireOrder(&models.Order{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, fakes.notificationService.events, 1)
+}
+
+func TestOrderService_ForceReassignFinalOrder(t *testing.T) {
+	orderService, fakes := newTestOrderService(
+		&models.Order{ID: 1, RestaurantID: "restaurant1", UserID: "driver2", State: "FULFILLED"},
+		&models.Order{ID: 2, RestaurantID: "restaurant1", UserID: "driver2", State: "CANCELLED"},
+	)
+
+	// Reassigning a fulfilled or cancelled order would deliver it twice
+	err := orderService.ForceReassignOrder(1)
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+	err = orderService.ForceReassignOrder(2)
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+	assert.Equal(t, "FULFILLED", fakes.orderRepository.orders[1].State)
+	assert.Equal(t, "CANCELLED", fakes.orderRepository.orders[2].State)
+	assert.Empty(t, fakes.penaltyService.penalized)
+	assert.Empty(t, fakes.userRepository.assignedOrders)
+
+	err = orderService.ForceCancelOrder(1)
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+	assert.Equal(t, "FULFILLED", fakes.orderRepository.orders[1].State)
+
+	err = orderService.ForceReassignOrder(3)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+}
+
+func TestOrderService_PublishesOnlyChangedOrders(t *testing.T) {
+	orderService, fakes := newTestOrderService(
+		&models.Order{ID: 1, RestaurantID: "restaurant1", UserID: "driver1", State: "PENDING"},
+		&models.Order{ID: 2, RestaurantID: "restaurant1", UserID: "driver1", State: "ACCEPTED"},
+		&models.Order{ID: 3, RestaurantID: "restaurant1", UserID: "driver1", State: "EXPIRED"},
+	)
+
+	// Orders that aren't accepted yet can't be fulfilled, so no event goes out
+	err := orderService.FulfillOrder(1, "restaurant1")
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+	assert.Empty(t, fakes.webhookService.events)
+
+	err = orderService.FulfillOrder(2, "restaurant1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.OrderEventFulfilled}, fakes.webhookService.events)
+
+	// A fulfilled order can't be cancelled anymore, an expired one can
+	err = orderService.CancelOrder(2, "restaurant1")
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+	err = orderService.CancelOrder(3, "restaurant1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.OrderEventFulfilled, models.OrderEventCancelled}, fakes.webhookService.events)
+	assert.Equal(t, []NotificationEvent{EventOrderCancelled}, fakes.notificationService.events)
+
+	// Cancelling it again doesn't tell anyone twice
+	err = orderService.CancelOrder(3, "restaurant1")
+	assert.ErrorIs(t, err, utils.ErrInvalidOrderState)
+	assert.Len(t, fakes.webhookService.events, 2)
+	assert.Len(t, fakes.notificationService.events, 1)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OrganizationService manages the branches of the organizations. Every method takes the restaurant that owns the organization,
+// they fail with utils.ErrNotFound if the restaurant doesn't own one
+type OrganizationService interface {
+	// CreateOrganization creates an organization owned by the restaurant, the restaurant becomes its first branch
+	CreateOrganization(organization *models.Organization) (*models.Organization, error)
+	GetOrganization(restaurantID string) (*models.Organization, error)
+	GetBranches(restaurantID string) ([]*models.Restaurant, error)
+	CreateBranch(restaurantID string, branch *models.Restaurant) (*models.Restaurant, error)
+	// GetBranch returns a branch of the organization, it fails with utils.ErrNotFound if the branch belongs to another organization
+	GetBranch(restaurantID string, branchID string) (*models.Restaurant, error)
+	UpdateBranch(restaurantID string, branchID string, patch *models.RestaurantPatch) (*models.Restaurant, error)
+	// DeleteBranch deletes a branch of the organization, the owner can't be deleted
+	DeleteBranch(restaurantID string, branchID string) error
+}
+
+type OrganizationServiceImpl struct {
+	organizationRepository repositories.OrganizationRepository
+	restaurantRepository   repositories.RestaurantRepository
+	logger                 logrus.FieldLogger
+}
+
+func NewOrganizationService(organizationRepository repositories.OrganizationRepository, restaurantRepository repositories.RestaurantRepository, logger logrus.FieldLogger) OrganizationService {
+	return &OrganizationServiceImpl{organizationRepository: organizationRepository, restaurantRepository: restaurantRepository, logger: logger}
+}
+
+func (s *OrganizationServiceImpl) CreateOrganization(organization *models.Organization) (*models.Organization, error) {
+	createdOrganization, err := s.organizationRepository.CreateOrganization(organization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return createdOrganization, nil
+}
+
+func (s *OrganizationServiceImpl) GetOrganization(restaurantID string) (*models.Organization, error) {
+	organization, err := s.organizationRepository.GetOrganizationByOwner(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return organization, nil
+}
+
+func (s *OrganizationServiceImpl) GetBranches(restaurantID string) ([]*models.Restaurant, error) {
+	organization, err := s.GetOrganization(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := s.organizationRepository.GetBranches(organization.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	return branches, nil
+}
+
+func (s *OrganizationServiceImpl) CreateBranch(restaurantID string, branch *models.Restaurant) (*models.Restaurant, error) {
+	organization, err := s.GetOrganization(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	createdBranch, err := s.organizationRepository.CreateBranch(organization.ID, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch: %w", err)
+	}
+	return createdBranch, nil
+}
+
+func (s *OrganizationServiceImpl) GetBranch(restaurantID string, branchID string) (*models.Restaurant, error) {
+	organization, err := s.GetOrganization(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := s.organizationRepository.GetBranch(organization.ID, branchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch: %w", err)
+	}
+	return branch, nil
+}
+
+func (s *OrganizationServiceImpl) UpdateBranch(restaurantID string, branchID string, patch *models.RestaurantPatch) (*models.Restaurant, error) {
+	// The branch is looked up first so only the branches of the organization can be updated
+	_, err := s.GetBranch(restaurantID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedBranch, err := s.restaurantRepository.UpdateRestaurant(branchID, patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update branch: %w", err)
+	}
+	return updatedBranch, nil
+}
+
+func (s *OrganizationServiceImpl) DeleteBranch(restaurantID string, branchID string) error {
+	organization, err := s.GetOrganization(restaurantID)
+	if err != nil {
+		return err
+	}
+
+	err = s.organizationRepository.DeleteBranch(organization.ID, branchID)
+	if err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PenaltyPolicy decides how long a driver stops receiving orders after missing one.
+// The cooldown escalates with every penalty the driver got within Window, the last cooldown is used for any further penalty
+type PenaltyPolicy struct {
+	Cooldowns []time.Duration
+	Window    time.Duration
+}
+
+// DefaultPenaltyPolicy is used when no cooldowns are configured
+func DefaultPenaltyPolicy() PenaltyPolicy {
+	return PenaltyPolicy{Cooldowns: []time.Duration{15 * time.Minute, time.Hour, 4 * time.Hour, 24 * time.Hour}, Window: 24 * time.Hour}
+}
+
+// Cooldown returns the cooldown of the nth penalty within the window, starting at 1
+func (p PenaltyPolicy) Cooldown(offenseNumber int) time.Duration {
+	if len(p.Cooldowns) == 0 {
+		return 0
+	}
+	index := min(max(offenseNumber, 1), len(p.Cooldowns)) - 1
+	return p.Cooldowns[index]
+}
+
+type PenaltyService interface {
+	// PenalizeDriver records a penalty with the cooldown of the policy and tells the driver about it
+	PenalizeDriver(user *models.User, order *models.Order, reason string) (*models.DriverPenalty, error)
+	// GetActivePenalty returns the penalty whose cooldown isn't over, or nil if the driver can receive orders
+	GetActivePenalty(userID string) (*models.DriverPenalty, error)
+}
+
+type PenaltyServiceImpl struct {
+	userRepository      repositories.UserRepository
+	notificationService NotificationService
+	policy              PenaltyPolicy
+	logger              logrus.FieldLogger
+}
+
+func NewPenaltyService(userRepository repositories.UserRepository, notificationService NotificationService, policy PenaltyPolicy, logger logrus.FieldLogger) PenaltyService {
+	return &PenaltyServiceImpl{userRepository: userRepository, notificationService: notificationService, policy: policy, logger: logger}
+}
+
+func (s *PenaltyServiceImpl) PenalizeDriver(user *models.User, order *models.Order, reason string) (*models.DriverPenalty, error) {
+	previousPenalties, err := s.userRepository.CountUserPenaltiesSince(user.ID, s.policy.Window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count driver penalties: %w", err)
+	}
+
+	offenseNumber := previousPenalties + 1
+	cooldown := s.policy.Cooldown(offenseNumber)
+	penalty, err := s.userRepository.CreateUserPenalty(&models.DriverPenalty{
+		UserID:        user.ID,
+		OrderID:       &order.ID,
+		Reason:        reason,
+		OffenseNumber: offenseNumber,
+	}, cooldown)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver penalty: %w", err)
+	}
+
+	s.logger.Infof("Driver %s penalized for order %d (%s), offense %d, cooldown %s", user.ID, order.ID, reason, offenseNumber, cooldown)
+
+	// The penalty is in place even if the driver can't be told about it, they'll see it in their profile
+	err = s.notificationService.Notify(driverRecipient(user), Notification{
+		Event: EventDriverPenalized,
+		Title: "تم إيقاف استلام الطلبات مؤقتاً",
+		Body:  fmt.Sprintf("لم يتم الرد على الطلب رقم %s في الوقت المحدد، لن تستلم طلبات جديدة لمدة %d دقيقة", order.Code, int(cooldown.Minutes())),
+	})
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to notify driver %s of their penalty", user.ID)
+	}
+
+	return penalty, nil
+}
+
+func (s *PenaltyServiceImpl) GetActivePenalty(userID string) (*models.DriverPenalty, error) {
+	penalty, err := s.userRepository.GetActiveUserPenalty(userID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active driver penalty: %w", err)
+	}
+	return penalty, nil
+}
@@ -5,6 +5,7 @@ import (
 	"Tamra/internal/pkg/models"
 	"Tamra/internal/pkg/utils"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -13,18 +14,70 @@ type RestaurantService interface {
 	CreateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error)
 	GetRestaurant(fbUID string) (*models.Restaurant, error)
 	GetRestaurantByID(restaurantID string) (*models.Restaurant, error)
-	UpdateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error)
+	// UpdateRestaurant updates the fields of a restaurant that are set in the patch
+	UpdateRestaurant(restaurantID string, patch *models.RestaurantPatch) (*models.Restaurant, error)
 	GetLogoUploadURL(UID, uploadBucketName string) (string, string, error)
+	// SearchRestaurants returns a page of the restaurants matching the filter, the newest first, and how many match it
+	SearchRestaurants(filter *models.RestaurantFilter) ([]*models.Restaurant, int, error)
+	// GetNearbyRestaurants returns a page of the restaurants around a location, the closest first, and how many there are.
+	// The last location and the radius of the driver are used when the filter doesn't set them
+	GetNearbyRestaurants(userID string, filter *models.NearbyRestaurantFilter) ([]*models.NearbyRestaurant, int, error)
+	// DeleteRestaurant deletes the restaurant and its account, signing it out of all its sessions
 	DeleteRestaurant(restaurantID string) error
+	RegisterDevice(device *models.RestaurantDevice) (*models.RestaurantDevice, error)
+	GetDevices(restaurantID string) ([]*models.RestaurantDevice, error)
+	DeleteDevice(id int, restaurantID string) error
+	// SetDriverPreference marks a driver as preferred or blocked by the restaurant
+	SetDriverPreference(preference *models.RestaurantDriverPreference) (*models.RestaurantDriverPreference, error)
+	// GetDriverPreferences returns the preferred and blocked drivers of the restaurant, only the ones with the given preference if it isn't empty
+	GetDriverPreferences(restaurantID string, preference string) ([]*models.RestaurantDriverPreference, error)
+	DeleteDriverPreference(restaurantID string, userID string) error
+	// GetMembership returns the restaurant an account works for and its role there
+	GetMembership(uid string) (*models.RestaurantMember, error)
+	GetMembers(restaurantID string) ([]*models.RestaurantMember, error)
+	// RemoveMember stops a staff member from acting for the restaurant, the owner can't be removed
+	RemoveMember(restaurantID string, uid string) error
+	// InviteMember invites the account with the email to join the restaurant, the invite expires after a week
+	InviteMember(invite *models.RestaurantInvite) (*models.RestaurantInvite, error)
+	// GetInvites returns the pending invites sent by the restaurant
+	GetInvites(restaurantID string) ([]*models.RestaurantInvite, error)
+	DeleteInvite(id int, restaurantID string) error
+	// GetInvitesForEmail returns the pending invites sent to an email
+	GetInvitesForEmail(email string) ([]*models.RestaurantInvite, error)
+	// AcceptInvite makes the account a staff member of the restaurant that invited its verified email.
+	// Accounts that don't have a role yet get the restaurant staff role, the others can't join a restaurant
+	AcceptInvite(id int, uid string, email string) (*models.RestaurantMember, error)
+	GetOpeningHours(restaurantID string) (*models.RestaurantOpeningHours, error)
+	// UpdateOpeningHours replaces the weekly opening hours of a restaurant. Restaurants without opening hours take orders at any time
+	UpdateOpeningHours(restaurantID string, openingHours *models.RestaurantOpeningHours) (*models.RestaurantOpeningHours, error)
+	CreateClosure(closure *models.RestaurantClosure) (*models.RestaurantClosure, error)
+	// GetClosures returns the current and upcoming closures of a restaurant
+	GetClosures(restaurantID string) ([]*models.RestaurantClosure, error)
+	DeleteClosure(id int, restaurantID string) error
+	// GetAvailability returns whether a restaurant takes orders right now, and why not if it doesn't
+	GetAvailability(restaurantID string) (*models.RestaurantAvailability, error)
+	// GetRestaurantDetails returns a restaurant with its opening hours, closures and whether it takes orders right now
+	GetRestaurantDetails(restaurantID string) (*models.RestaurantDetails, error)
+	GetDeliveryZone(restaurantID string) (*models.DeliveryZone, error)
+	// SetDeliveryZone replaces the delivery zone of a restaurant, only the drivers inside it receive its orders.
+	// It fails with utils.ErrInvalidZone if the polygon of the zone isn't valid
+	SetDeliveryZone(zone *models.DeliveryZone) (*models.DeliveryZone, error)
+	// DeleteDeliveryZone removes the delivery zone of a restaurant, the drivers are then only limited by their radius
+	DeleteDeliveryZone(restaurantID string) error
 }
 
+// restaurantInviteTTL is how long the staff have to accept an invite
+const restaurantInviteTTL = 7 * 24 * time.Hour
+
 type RestaurantServiceImpl struct {
 	restaurantRepository repositories.RestaurantRepository
+	userRepository       repositories.UserRepository
+	authService          AuthService
 	logger               logrus.FieldLogger
 }
 
-func NewRestaurantService(restaurantRepository repositories.RestaurantRepository, logger logrus.FieldLogger) RestaurantService {
-	return &RestaurantServiceImpl{restaurantRepository: restaurantRepository, logger: logger}
+func NewRestaurantService(restaurantRepository repositories.RestaurantRepository, userRepository repositories.UserRepository, authService AuthService, logger logrus.FieldLogger) RestaurantService {
+	return &RestaurantServiceImpl{restaurantRepository: restaurantRepository, userRepository: userRepository, authService: authService, logger: logger}
 }
 
 func (s *RestaurantServiceImpl) CreateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error) {
@@ -54,8 +107,8 @@ func (s *RestaurantServiceImpl) GetRestaurantByID(restaurantID string) (*models.
 	return restaurant, nil
 }
 
-func (s *RestaurantServiceImpl) UpdateRestaurant(restaurant *models.Restaurant) (*models.Restaurant, error) {
-	updatedRestaurant, err := s.restaurantRepository.UpdateRestaurant(restaurant)
+func (s *RestaurantServiceImpl) UpdateRestaurant(restaurantID string, patch *models.RestaurantPatch) (*models.Restaurant, error) {
+	updatedRestaurant, err := s.restaurantRepository.UpdateRestaurant(restaurantID, patch)
 	if err != nil {
 		// Wrap the error returned by the repository and add some context
 		return nil, fmt.Errorf("failed to update restaurant: %w", err)
@@ -72,11 +125,306 @@ func (s *RestaurantServiceImpl) GetLogoUploadURL(UID, uploadBucketName string) (
 	return presignedURL, storedFileURL, nil
 }
 
+func (s *RestaurantServiceImpl) SearchRestaurants(filter *models.RestaurantFilter) ([]*models.Restaurant, int, error) {
+	restaurants, total, err := s.restaurantRepository.SearchRestaurants(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search restaurants: %w", err)
+	}
+	return restaurants, total, nil
+}
+
+func (s *RestaurantServiceImpl) GetNearbyRestaurants(userID string, filter *models.NearbyRestaurantFilter) ([]*models.NearbyRestaurant, int, error) {
+	if filter.Longitude == nil || filter.Latitude == nil || filter.Radius == 0 {
+		user, err := s.userRepository.GetUser(userID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get user: %w", err)
+		}
+		if filter.Longitude == nil || filter.Latitude == nil {
+			filter.Longitude, filter.Latitude = &user.Longitude, &user.Latitude
+		}
+		if filter.Radius == 0 {
+			filter.Radius = user.Radius
+		}
+	}
+
+	restaurants, total, err := s.restaurantRepository.SearchNearbyRestaurants(*filter.Longitude, *filter.Latitude, filter.Radius, filter.Pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search nearby restaurants: %w", err)
+	}
+	return restaurants, total, nil
+}
+
 func (s *RestaurantServiceImpl) DeleteRestaurant(restaurantID string) error {
 	err := s.restaurantRepository.DeleteRestaurant(restaurantID)
 	if err != nil {
 		// Wrap the error returned by the repository and add some context
 		return fmt.Errorf("failed to delete restaurant: %w", err)
 	}
+
+	// Without its account the restaurant can't sign back in and its current tokens stop working
+	err = s.authService.DeleteAccount(restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete restaurant account: %w", err)
+	}
+	return nil
+}
+
+func (s *RestaurantServiceImpl) RegisterDevice(device *models.RestaurantDevice) (*models.RestaurantDevice, error) {
+	registeredDevice, err := s.restaurantRepository.CreateRestaurantDevice(device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register restaurant device: %w", err)
+	}
+	return registeredDevice, nil
+}
+
+func (s *RestaurantServiceImpl) GetDevices(restaurantID string) ([]*models.RestaurantDevice, error) {
+	devices, err := s.restaurantRepository.GetRestaurantDevices(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restaurant devices: %w", err)
+	}
+	return devices, nil
+}
+
+func (s *RestaurantServiceImpl) DeleteDevice(id int, restaurantID string) error {
+	err := s.restaurantRepository.DeleteRestaurantDevice(id, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete restaurant device: %w", err)
+	}
+	return nil
+}
+
+func (s *RestaurantServiceImpl) SetDriverPreference(preference *models.RestaurantDriverPreference) (*models.RestaurantDriverPreference, error) {
+	updatedPreference, err := s.restaurantRepository.SetRestaurantDriverPreference(preference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set driver preference: %w", err)
+	}
+	return updatedPreference, nil
+}
+
+func (s *RestaurantServiceImpl) GetDriverPreferences(restaurantID string, preference string) ([]*models.RestaurantDriverPreference, error) {
+	preferences, err := s.restaurantRepository.GetRestaurantDriverPreferences(restaurantID, preference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+func (s *RestaurantServiceImpl) DeleteDriverPreference(restaurantID string, userID string) error {
+	err := s.restaurantRepository.DeleteRestaurantDriverPreference(restaurantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete driver preference: %w", err)
+	}
+	return nil
+}
+
+func (s *RestaurantServiceImpl) GetMembership(uid string) (*models.RestaurantMember, error) {
+	member, err := s.restaurantRepository.GetRestaurantMember(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restaurant membership: %w", err)
+	}
+	return member, nil
+}
+
+func (s *RestaurantServiceImpl) GetMembers(restaurantID string) ([]*models.RestaurantMember, error) {
+	members, err := s.restaurantRepository.GetRestaurantMembers(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restaurant members: %w", err)
+	}
+	return members, nil
+}
+
+func (s *RestaurantServiceImpl) RemoveMember(restaurantID string, uid string) error {
+	err := s.restaurantRepository.DeleteRestaurantMember(restaurantID, uid)
+	if err != nil {
+		return fmt.Errorf("failed to remove restaurant member: %w", err)
+	}
+	return nil
+}
+
+func (s *RestaurantServiceImpl) InviteMember(invite *models.RestaurantInvite) (*models.RestaurantInvite, error) {
+	createdInvite, err := s.restaurantRepository.CreateRestaurantInvite(invite, restaurantInviteTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restaurant invite: %w", err)
+	}
+	return createdInvite, nil
+}
+
+func (s *RestaurantServiceImpl) GetInvites(restaurantID string) ([]*models.RestaurantInvite, error) {
+	invites, err := s.restaurantRepository.GetRestaurantInvites(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restaurant invites: %w", err)
+	}
+	return invites, nil
+}
+
+func (s *RestaurantServiceImpl) DeleteInvite(id int, restaurantID string) error {
+	err := s.restaurantRepository.DeleteRestaurantInvite(id, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete restaurant invite: %w", err)
+	}
+	return nil
+}
+
+func (s *RestaurantServiceImpl) GetInvitesForEmail(email string) ([]*models.RestaurantInvite, error) {
+	invites, err := s.restaurantRepository.GetRestaurantInvitesByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restaurant invites: %w", err)
+	}
+	return invites, nil
+}
+
+func (s *RestaurantServiceImpl) AcceptInvite(id int, uid string, email string) (*models.RestaurantMember, error) {
+	// Drivers and restaurant owners keep their role, an account has a single role
+	role, err := s.authService.GetRole(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept restaurant invite: %w", err)
+	}
+	if role != "" && role != models.RoleRestaurantStaff {
+		return nil, utils.ErrRoleAlreadyAssigned
+	}
+
+	// The role is set first so that if it fails the invite can still be accepted again
+	if role == "" {
+		err = s.authService.AssignRole(uid, models.RoleRestaurantStaff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to accept restaurant invite: %w", err)
+		}
+	}
+
+	member, err := s.restaurantRepository.AcceptRestaurantInvite(id, email, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept restaurant invite: %w", err)
+	}
+	return member, nil
+}
+
+func (s *RestaurantServiceImpl) GetOpeningHours(restaurantID string) (*models.RestaurantOpeningHours, error) {
+	openingHours, err := s.restaurantRepository.GetRestaurantOpeningHours(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening hours: %w", err)
+	}
+	return openingHours, nil
+}
+
+func (s *RestaurantServiceImpl) UpdateOpeningHours(restaurantID string, openingHours *models.RestaurantOpeningHours) (*models.RestaurantOpeningHours, error) {
+	// A period closing before it opens is an overnight period, one closing when it opens has no length
+	for _, period := range openingHours.Periods {
+		if period.CloseTime == period.OpenTime {
+			return nil, fmt.Errorf("opening period on weekday %d closes when it opens: %w", period.Weekday, utils.ErrInvalidSchedule)
+		}
+	}
+
+	updatedOpeningHours, err := s.restaurantRepository.ReplaceRestaurantOpeningHours(restaurantID, openingHours)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update opening hours: %w", err)
+	}
+	return updatedOpeningHours, nil
+}
+
+func (s *RestaurantServiceImpl) CreateClosure(closure *models.RestaurantClosure) (*models.RestaurantClosure, error) {
+	createdClosure, err := s.restaurantRepository.CreateRestaurantClosure(closure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create closure: %w", err)
+	}
+	return createdClosure, nil
+}
+
+func (s *RestaurantServiceImpl) GetClosures(restaurantID string) ([]*models.RestaurantClosure, error) {
+	closures, err := s.restaurantRepository.GetRestaurantClosures(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closures: %w", err)
+	}
+	return closures, nil
+}
+
+func (s *RestaurantServiceImpl) DeleteClosure(id int, restaurantID string) error {
+	err := s.restaurantRepository.DeleteRestaurantClosure(id, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete closure: %w", err)
+	}
 	return nil
 }
+
+func (s *RestaurantServiceImpl) GetAvailability(restaurantID string) (*models.RestaurantAvailability, error) {
+	availability, err := s.restaurantRepository.GetRestaurantAvailability(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability: %w", err)
+	}
+	return availability, nil
+}
+
+func (s *RestaurantServiceImpl) GetRestaurantDetails(restaurantID string) (*models.RestaurantDetails, error) {
+	restaurant, err := s.GetRestaurantByID(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, err := s.GetAvailability(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	openingHours, err := s.GetOpeningHours(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := s.GetClosures(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RestaurantDetails{Restaurant: restaurant, RestaurantAvailability: *availability, OpeningHours: openingHours, Closures: closures}, nil
+}
+
+func (s *RestaurantServiceImpl) GetDeliveryZone(restaurantID string) (*models.DeliveryZone, error) {
+	zone, err := s.restaurantRepository.GetRestaurantDeliveryZone(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery zone: %w", err)
+	}
+	return zone, nil
+}
+
+func (s *RestaurantServiceImpl) SetDeliveryZone(zone *models.DeliveryZone) (*models.DeliveryZone, error) {
+	if zone.Type == models.DeliveryZoneTypePolygon {
+		polygon, err := closePolygon(zone.Polygon)
+		if err != nil {
+			return nil, err
+		}
+		zone.Polygon = polygon
+	}
+
+	updatedZone, err := s.restaurantRepository.SetRestaurantDeliveryZone(zone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set delivery zone: %w", err)
+	}
+	return updatedZone, nil
+}
+
+func (s *RestaurantServiceImpl) DeleteDeliveryZone(restaurantID string) error {
+	err := s.restaurantRepository.DeleteRestaurantDeliveryZone(restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete delivery zone: %w", err)
+	}
+	return nil
+}
+
+// closePolygon checks that the points of a polygon are coordinates and that it has at least 3 different points,
+// and repeats the first point at the end if it isn't there. The rest of the geometry is checked by PostGIS
+func closePolygon(points [][]float64) ([][]float64, error) {
+	for i, point := range points {
+		if point[0] < -180 || point[0] > 180 || point[1] < -90 || point[1] > 90 {
+			return nil, fmt.Errorf("point %d of the polygon isn't a longitude and a latitude: %w", i, utils.ErrInvalidZone)
+		}
+	}
+
+	first, last := points[0], points[len(points)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		points = append(points, first)
+	}
+	// A closed ring needs 3 points and the closing one to enclose an area
+	if len(points) < 4 {
+		return nil, fmt.Errorf("polygon has fewer than 3 points: %w", utils.ErrInvalidZone)
+	}
+	return points, nil
+}
@@ -3,7 +3,10 @@ package services
 import (
 	"Tamra/internal/app/tamra/repositories"
 	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -13,18 +16,55 @@ import (
 type UserService interface {
 	CreateUser(user *models.User) (*models.User, error)
 	GetUser(userID string) (*models.User, error)
-	UpdateUser(user *models.User) (*models.User, error)
+	// UpdateUser updates the fields of a user that are set in the patch
+	UpdateUser(userID string, patch *models.UserPatch) (*models.User, error)
+	// UpdateLocation stores the most recent of the points that passes the LocationPolicy
+	UpdateLocation(userID string, points []*models.LocationPoint) (*models.LocationUpdateResponse, error)
 	GetUsers() ([]*models.User, error)
+	// DeleteUser deletes the driver and their account, signing them out of all their sessions
 	DeleteUser(id string) error
+	GetSchedule(userID string) (*models.DriverSchedule, error)
+	// UpdateSchedule replaces the weekly schedule of a driver. Dispatch only considers drivers with a schedule during their shifts
+	UpdateSchedule(userID string, schedule *models.DriverSchedule) (*models.DriverSchedule, error)
+	CreateTimeOff(timeOff *models.DriverTimeOff) (*models.DriverTimeOff, error)
+	// GetTimeOffs returns the current and upcoming time off periods of a driver
+	GetTimeOffs(userID string) ([]*models.DriverTimeOff, error)
+	DeleteTimeOff(id int, userID string) error
+	// GetStats returns the performance of a driver over the last 30 days
+	GetStats(userID string) (*models.DriverStats, error)
+	// GetIDPhotoUploadURL returns a presigned URL to upload the ID photo of a driver and the URL the photo will be stored at
+	GetIDPhotoUploadURL(UID, uploadBucketName string) (string, string, error)
+	// GetUsersByStatus returns the drivers with the given status, e.g. the ones waiting for an approval
+	GetUsersByStatus(status string) ([]*models.User, error)
+	// ApproveUser lets a driver receive orders. Drivers have to provide their vehicle type and upload their ID photo to the documents bucket first
+	ApproveUser(userID string, documentsBucketName string) (*models.User, error)
+	// SuspendUser stops a driver from receiving orders until they are approved again
+	SuspendUser(userID string) (*models.User, error)
+	// SearchUsers returns a page of the users matching the filter, the newest first, and how many match it
+	SearchUsers(filter *models.UserFilter) ([]*models.User, int, error)
+	// SetUserActive puts a driver on or off duty for them, e.g. when they forgot to go off duty
+	SetUserActive(userID string, isActive bool) (*models.User, error)
+}
+
+// LocationPolicy decides which driver locations are stored. Drivers report their location every few seconds,
+// so we only write it once per MinInterval and skip the imprecise or old points
+type LocationPolicy struct {
+	MinInterval time.Duration
+	MaxAccuracy float64 // In meters
+	MaxAge      time.Duration
 }
 
 type UserServiceImpl struct {
-	userRepository repositories.UserRepository
-	logger         logrus.FieldLogger
+	userRepository  repositories.UserRepository
+	orderRepository repositories.OrderRepository
+	penaltyService  PenaltyService
+	authService     AuthService
+	locationPolicy  LocationPolicy
+	logger          logrus.FieldLogger
 }
 
-func NewUserService(userRepository repositories.UserRepository, logger logrus.FieldLogger) UserService {
-	return &UserServiceImpl{userRepository: userRepository, logger: logger}
+func NewUserService(userRepository repositories.UserRepository, orderRepository repositories.OrderRepository, penaltyService PenaltyService, authService AuthService, locationPolicy LocationPolicy, logger logrus.FieldLogger) UserService {
+	return &UserServiceImpl{userRepository: userRepository, orderRepository: orderRepository, penaltyService: penaltyService, authService: authService, locationPolicy: locationPolicy, logger: logger}
 }
 
 func (s *UserServiceImpl) CreateUser(user *models.User) (*models.User, error) {
@@ -42,11 +82,17 @@ func (s *UserServiceImpl) GetUser(userID string) (*models.User, error) {
 		// Wrap the error returned by the repository and add some context
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+
+	// Drivers see why they don't receive orders in their profile
+	user.ActivePenalty, err = s.penaltyService.GetActivePenalty(userID)
+	if err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
-func (s *UserServiceImpl) UpdateUser(user *models.User) (*models.User, error) {
-	updatedUser, err := s.userRepository.UpdateUser(user)
+func (s *UserServiceImpl) UpdateUser(userID string, patch *models.UserPatch) (*models.User, error) {
+	updatedUser, err := s.userRepository.UpdateUser(userID, patch)
 	if err != nil {
 		// Wrap the error returned by the repository and add some context
 		return nil, fmt.Errorf("failed to update user: %w", err)
@@ -54,6 +100,78 @@ func (s *UserServiceImpl) UpdateUser(user *models.User) (*models.User, error) {
 	return updatedUser, nil
 }
 
+func (s *UserServiceImpl) UpdateLocation(userID string, points []*models.LocationPoint) (*models.LocationUpdateResponse, error) {
+	now := time.Now()
+
+	// Keep the points that are precise and fresh enough, the most recent one becomes the driver's location
+	var latest *models.LocationPoint
+	validPoints := []*models.LocationPoint{}
+	reason := ""
+	for _, point := range points {
+		if point.Accuracy != nil && *point.Accuracy > s.locationPolicy.MaxAccuracy {
+			reason = models.LocationRejectedLowAccuracy
+			continue
+		}
+		if now.Sub(point.RecordedAt) > s.locationPolicy.MaxAge {
+			reason = models.LocationRejectedStale
+			continue
+		}
+		validPoints = append(validPoints, point)
+		if latest == nil || point.RecordedAt.After(latest.RecordedAt) {
+			latest = point
+		}
+	}
+
+	if latest == nil {
+		return &models.LocationUpdateResponse{Accepted: false, Reason: reason}, nil
+	}
+
+	// Devices with a clock ahead of ours would otherwise store a location from the future
+	age := now.Sub(latest.RecordedAt)
+	if age < 0 {
+		age = 0
+	}
+
+	locationUpdatedAt, err := s.userRepository.UpdateUserLocation(userID, latest.Longitude, latest.Latitude, age, s.locationPolicy.MinInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user location: %w", err)
+	}
+
+	if locationUpdatedAt == nil {
+		// Nothing was updated, either because the location is throttled or because the user doesn't exist
+		user, err := s.userRepository.GetUser(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return &models.LocationUpdateResponse{Accepted: false, Reason: models.LocationRejectedThrottled, LocationUpdatedAt: user.LocationUpdatedAt}, nil
+	}
+
+	// Drivers carrying an order leave a trail the restaurant can follow. It is thinned to the same interval as the location
+	err = s.orderRepository.AddOrderLocationPoints(userID, thinLocationPoints(validPoints, s.locationPolicy.MinInterval))
+	if err != nil {
+		// The location itself was stored, a gap in the trail isn't worth failing the update for
+		s.logger.WithError(err).Errorf("Failed to add the location of user %s to the trail of their orders", userID)
+	}
+
+	return &models.LocationUpdateResponse{Accepted: true, LocationUpdatedAt: locationUpdatedAt}, nil
+}
+
+// thinLocationPoints sorts the points by the time they were recorded and keeps the ones at least minInterval apart
+func thinLocationPoints(points []*models.LocationPoint, minInterval time.Duration) []*models.LocationPoint {
+	sorted := make([]*models.LocationPoint, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].RecordedAt.Before(sorted[j].RecordedAt) })
+
+	thinned := []*models.LocationPoint{}
+	for _, point := range sorted {
+		if len(thinned) > 0 && point.RecordedAt.Sub(thinned[len(thinned)-1].RecordedAt) < minInterval {
+			continue
+		}
+		thinned = append(thinned, point)
+	}
+	return thinned
+}
+
 func (s *UserServiceImpl) GetUsers() ([]*models.User, error) {
 	users, err := s.userRepository.GetUsers()
 	if err != nil {
@@ -69,5 +187,131 @@ func (s *UserServiceImpl) DeleteUser(id string) error {
 		// Wrap the error returned by the repository and add some context
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
+
+	// Without its account the driver can't sign back in and its current tokens stop working
+	err = s.authService.DeleteAccount(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user account: %w", err)
+	}
 	return nil
 }
+
+func (s *UserServiceImpl) GetSchedule(userID string) (*models.DriverSchedule, error) {
+	schedule, err := s.userRepository.GetUserSchedule(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+func (s *UserServiceImpl) UpdateSchedule(userID string, schedule *models.DriverSchedule) (*models.DriverSchedule, error) {
+	// A shift ending before it starts is an overnight shift, one ending when it starts has no length
+	for _, shift := range schedule.Shifts {
+		if shift.EndTime == shift.StartTime {
+			return nil, fmt.Errorf("shift on weekday %d ends when it starts: %w", shift.Weekday, utils.ErrInvalidSchedule)
+		}
+	}
+
+	updatedSchedule, err := s.userRepository.ReplaceUserSchedule(userID, schedule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return updatedSchedule, nil
+}
+
+func (s *UserServiceImpl) CreateTimeOff(timeOff *models.DriverTimeOff) (*models.DriverTimeOff, error) {
+	createdTimeOff, err := s.userRepository.CreateUserTimeOff(timeOff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create time off: %w", err)
+	}
+	return createdTimeOff, nil
+}
+
+func (s *UserServiceImpl) GetTimeOffs(userID string) ([]*models.DriverTimeOff, error) {
+	timeOffs, err := s.userRepository.GetUserTimeOffs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time offs: %w", err)
+	}
+	return timeOffs, nil
+}
+
+func (s *UserServiceImpl) DeleteTimeOff(id int, userID string) error {
+	err := s.userRepository.DeleteUserTimeOff(id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete time off: %w", err)
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) GetStats(userID string) (*models.DriverStats, error) {
+	stats, err := s.userRepository.GetUserStats(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (s *UserServiceImpl) GetIDPhotoUploadURL(UID, uploadBucketName string) (string, string, error) {
+	presignedURL, storedFileURL, err := utils.GetS3PresignedURL(UID, uploadBucketName)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get presigned URL: %w", err)
+	}
+	return presignedURL, storedFileURL, nil
+}
+
+func (s *UserServiceImpl) GetUsersByStatus(status string) ([]*models.User, error) {
+	users, err := s.userRepository.GetUsersByStatus(status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	return users, nil
+}
+
+func (s *UserServiceImpl) ApproveUser(userID string, documentsBucketName string) (*models.User, error) {
+	user, err := s.userRepository.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.VehicleType == "" || user.IDPhotoURL == "" {
+		return nil, fmt.Errorf("user %s can't be approved: %w", userID, utils.ErrMissingDocuments)
+	}
+
+	// The ID photo has to be the one the driver uploaded with GetIDPhotoUploadURL, not a file hosted anywhere else or uploaded by someone else
+	if !utils.IsS3ObjectURL(user.IDPhotoURL, documentsBucketName, userID) {
+		return nil, fmt.Errorf("user %s can't be approved, the ID photo isn't in the documents bucket: %w", userID, utils.ErrInvalidDocuments)
+	}
+
+	approvedUser, err := s.userRepository.UpdateUserStatus(userID, models.UserStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve user: %w", err)
+	}
+	s.logger.Infof("User %s approved", userID)
+	return approvedUser, nil
+}
+
+func (s *UserServiceImpl) SuspendUser(userID string) (*models.User, error) {
+	suspendedUser, err := s.userRepository.UpdateUserStatus(userID, models.UserStatusSuspended)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suspend user: %w", err)
+	}
+	s.logger.Infof("User %s suspended", userID)
+	return suspendedUser, nil
+}
+
+func (s *UserServiceImpl) SearchUsers(filter *models.UserFilter) ([]*models.User, int, error) {
+	users, total, err := s.userRepository.SearchUsers(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+	return users, total, nil
+}
+
+func (s *UserServiceImpl) SetUserActive(userID string, isActive bool) (*models.User, error) {
+	user, err := s.userRepository.UpdateUser(userID, &models.UserPatch{IsActive: &isActive})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	s.logger.Infof("User %s active set to %t", userID, isActive)
+	return user, nil
+}
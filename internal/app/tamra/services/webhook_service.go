@@ -0,0 +1,264 @@
+package services
+
+import (
+	"Tamra/internal/app/tamra/repositories"
+	"Tamra/internal/pkg/models"
+	"Tamra/internal/pkg/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// webhookRetryBackoff is how long to wait before retrying a failed delivery. A delivery that failed on its last attempt is marked as failed
+var webhookRetryBackoff = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 6 * time.Hour}
+
+// webhookDeliveryLease is how long a delivery that is being attempted is hidden from the other workers
+const webhookDeliveryLease = time.Minute
+
+// webhookTimeout is how long an endpoint has to respond. Requests wait for the first attempt, so it is kept short
+const webhookTimeout = 5 * time.Second
+
+// webhookDeliveriesListLimit is how many of the latest deliveries are listed for an endpoint
+const webhookDeliveriesListLimit = 50
+
+type WebhookService interface {
+	CreateEndpoint(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	GetEndpoints(restaurantID string) ([]*models.WebhookEndpoint, error)
+	DeleteEndpoint(id int, restaurantID string) error
+	GetDeliveries(endpointID int, restaurantID string) ([]*models.WebhookDelivery, error)
+	GetDelivery(id int, endpointID int, restaurantID string) (*models.WebhookDelivery, error)
+	// ReplayDelivery sends a delivery again, whatever its state is
+	ReplayDelivery(id int, endpointID int, restaurantID string) error
+	// DispatchOrderEvent creates a delivery for every endpoint of the restaurant subscribed to the event and attempts them right away.
+	// It returns once the first attempts are over since goroutines don't outlive the request on Lambda
+	DispatchOrderEvent(event *models.OrderEvent) error
+	// ProcessDueDeliveries attempts the deliveries that are due, i.e. the retries and the ones whose first attempt didn't finish
+	ProcessDueDeliveries() error
+}
+
+type WebhookServiceImpl struct {
+	webhookRepository repositories.WebhookRepository
+	httpClient        *http.Client
+	logger            logrus.FieldLogger
+}
+
+func NewWebhookService(webhookRepository repositories.WebhookRepository, logger logrus.FieldLogger) WebhookService {
+	return &WebhookServiceImpl{webhookRepository: webhookRepository, httpClient: utils.NewWebhookHTTPClient(webhookTimeout), logger: logger}
+}
+
+func (s *WebhookServiceImpl) CreateEndpoint(endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	secret, err := utils.GenerateWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	endpoint.Secret = secret
+
+	createdEndpoint, err := s.webhookRepository.CreateWebhookEndpoint(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return createdEndpoint, nil
+}
+
+func (s *WebhookServiceImpl) GetEndpoints(restaurantID string) ([]*models.WebhookEndpoint, error) {
+	endpoints, err := s.webhookRepository.GetWebhookEndpoints(restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+func (s *WebhookServiceImpl) DeleteEndpoint(id int, restaurantID string) error {
+	err := s.webhookRepository.DeleteWebhookEndpoint(id, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *WebhookServiceImpl) GetDeliveries(endpointID int, restaurantID string) ([]*models.WebhookDelivery, error) {
+	// Make sure the endpoint belongs to the restaurant
+	_, err := s.webhookRepository.GetWebhookEndpoint(endpointID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	deliveries, err := s.webhookRepository.GetWebhookDeliveries(endpointID, webhookDeliveriesListLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *WebhookServiceImpl) GetDelivery(id int, endpointID int, restaurantID string) (*models.WebhookDelivery, error) {
+	_, err := s.webhookRepository.GetWebhookEndpoint(endpointID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	delivery, err := s.webhookRepository.GetWebhookDelivery(id, endpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (s *WebhookServiceImpl) ReplayDelivery(id int, endpointID int, restaurantID string) error {
+	endpoint, err := s.webhookRepository.GetWebhookEndpoint(endpointID, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	delivery, err := s.webhookRepository.GetWebhookDelivery(id, endpointID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	err = s.webhookRepository.ResetWebhookDelivery(id, endpointID, webhookDeliveryLease)
+	if err != nil {
+		return fmt.Errorf("failed to reset webhook delivery: %w", err)
+	}
+
+	s.attemptDelivery(endpoint, delivery)
+
+	return nil
+}
+
+func (s *WebhookServiceImpl) DispatchOrderEvent(event *models.OrderEvent) error {
+	endpoints, err := s.webhookRepository.GetActiveWebhookEndpointsForEvent(event.RestaurantID, event.Type)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook endpoints: %w", err)
+	}
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode order event: %w", err)
+	}
+
+	// The endpoints are attempted in parallel so a slow endpoint only delays the request by its own timeout
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for _, endpoint := range endpoints {
+		// The delivery is leased to us while we attempt it. If the attempt doesn't finish (e.g. the instance stops), a worker picks it up once the lease is over
+		delivery, err := s.webhookRepository.CreateWebhookDelivery(&models.WebhookDelivery{
+			WebhookEndpointID: endpoint.ID,
+			EventType:         event.Type,
+			Payload:           payload,
+		}, webhookDeliveryLease)
+		if err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+
+		wg.Add(1)
+		go func(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			s.attemptDelivery(endpoint, delivery)
+		}(endpoint, delivery)
+	}
+
+	return nil
+}
+
+func (s *WebhookServiceImpl) ProcessDueDeliveries() error {
+	deliveries, err := s.webhookRepository.ClaimDueWebhookDeliveries(100, webhookDeliveryLease)
+	if err != nil {
+		return fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+	}
+
+	// Deliveries of the same endpoint share the endpoint, so we only fetch each one once
+	endpoints := map[int]*models.WebhookEndpoint{}
+	for _, delivery := range deliveries {
+		endpoint, ok := endpoints[delivery.WebhookEndpointID]
+		if !ok {
+			endpoint, err = s.webhookRepository.GetWebhookEndpointByID(delivery.WebhookEndpointID)
+			if err != nil {
+				s.logger.WithError(err).Errorf("Failed to get webhook endpoint %d", delivery.WebhookEndpointID)
+				continue
+			}
+			endpoints[delivery.WebhookEndpointID] = endpoint
+		}
+
+		s.attemptDelivery(endpoint, delivery)
+	}
+
+	return nil
+}
+
+// attemptDelivery posts the delivery to the endpoint and records the attempt. Failed deliveries are retried with an increasing backoff
+func (s *WebhookServiceImpl) attemptDelivery(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) {
+	attempt := &models.WebhookDeliveryAttempt{WebhookDeliveryID: delivery.ID, AttemptNumber: delivery.AttemptCount + 1}
+
+	start := time.Now()
+	statusCode, err := s.post(endpoint, delivery)
+	attempt.DurationMs = int(time.Since(start).Milliseconds())
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+
+	state := models.WebhookDeliverySucceeded
+	var retryIn time.Duration
+	if err != nil {
+		attempt.Error = err.Error()
+		s.logger.WithError(err).Warnf("Webhook delivery %d to endpoint %d failed on attempt %d", delivery.ID, endpoint.ID, attempt.AttemptNumber)
+
+		// Replayed deliveries can go past the number of retries, they get a single attempt
+		retryIndex := attempt.AttemptNumber - 1
+		if retryIndex < len(webhookRetryBackoff) {
+			state = models.WebhookDeliveryPending
+			retryIn = webhookRetryBackoff[retryIndex]
+		} else {
+			state = models.WebhookDeliveryFailed
+		}
+	}
+
+	err = s.webhookRepository.RecordWebhookDeliveryAttempt(attempt, state, retryIn)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to record attempt of webhook delivery %d", delivery.ID)
+	}
+}
+
+// post sends the signed delivery and returns the status code of the response, if any
+func (s *WebhookServiceImpl) post(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(&models.WebhookPayload{DeliveryID: delivery.ID, EventType: delivery.EventType, Data: delivery.Payload})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Tamra-Webhooks/1")
+	req.Header.Set("X-Tamra-Event", delivery.EventType)
+	req.Header.Set("X-Tamra-Delivery", strconv.Itoa(delivery.ID))
+	req.Header.Set("X-Tamra-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Tamra-Signature", utils.SignWebhookPayload(endpoint.Secret, timestamp, body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
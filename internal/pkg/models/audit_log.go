@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AdminAuditLog is a change an admin made, or tried to make, through the admin routes
+type AdminAuditLog struct {
+	ID          int             `json:"id"`
+	AdminUID    string          `json:"admin_uid"`
+	Action      string          `json:"action"` // Method and route of the request, e.g. POST /admin/orders/{orderID}/cancel
+	Path        string          `json:"path"`   // Path of the request, with the IDs of what was changed
+	RequestID   string          `json:"request_id"`
+	RequestBody json.RawMessage `json:"request_body"` // Nil if the request didn't have a JSON body
+	StatusCode  int             `json:"status_code"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// AdminAuditLogFilter selects the audit logs, the empty fields don't filter
+type AdminAuditLogFilter struct {
+	AdminUID string
+	Pagination
+}
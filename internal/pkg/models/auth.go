@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// RoleClaim is the Firebase custom claim holding the role of an account
+const RoleClaim = "role"
+
+// Roles of the accounts, stored in the RoleClaim of their token
+const (
+	RoleDriver          = "driver"
+	RoleRestaurant      = "restaurant"
+	RoleRestaurantStaff = "restaurant_staff"
+	RoleAdmin           = "admin"
+)
+
+// AssignRoleRequest is sent by new accounts to pick their role, they can only pick it once
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=driver restaurant"`
+}
+
+// AdminAssignRoleRequest is sent by admins to set the role of any account
+type AdminAssignRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=driver restaurant restaurant_staff admin"`
+}
+
+type RoleResponse struct {
+	UID  string `json:"uid"`
+	Role string `json:"role"`
+}
+
+// IssueTokenRequest asks the local token issuer for a token, it is only available during local development.
+// The role of the account is used if the request doesn't set one
+type IssueTokenRequest struct {
+	UID   string `json:"uid" validate:"required"`
+	Role  string `json:"role" validate:"omitempty,oneof=driver restaurant restaurant_staff admin"`
+	Email string `json:"email" validate:"omitempty,email"`
+	Phone string `json:"phone" validate:"omitempty,e164"`
+}
+
+type IssueTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Types of delivery zones
+const (
+	DeliveryZoneTypePolygon = "POLYGON" // The zone is an area drawn by the restaurant
+	DeliveryZoneTypeRadius  = "RADIUS"  // The zone is a circle around the restaurant
+)
+
+// DeliveryZone is the area a restaurant delivers in, only the drivers inside it receive its orders
+type DeliveryZone struct {
+	RestaurantID string      `json:"restaurant_id"`
+	Type         string      `json:"type"`
+	Polygon      [][]float64 `json:"polygon,omitempty"` // [longitude, latitude] points of the boundary, the last one is the first one. Only set for polygons
+	Radius       int         `json:"radius,omitempty"`  // In meters, only set for radiuses
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// SetDeliveryZoneRequest replaces the delivery zone of a restaurant. Polygons are closed if their last point isn't their first one
+type SetDeliveryZoneRequest struct {
+	Type    string      `json:"type" validate:"required,oneof=POLYGON RADIUS"`
+	Polygon [][]float64 `json:"polygon" validate:"required_if=Type POLYGON,excluded_unless=Type POLYGON,omitempty,min=3,max=500,dive,len=2"`
+	Radius  int         `json:"radius" validate:"required_if=Type RADIUS,excluded_unless=Type RADIUS,omitempty,min=100,max=100000"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Reasons a driver is penalized
+const (
+	PenaltyReasonOrderExpired    = "ORDER_EXPIRED"    // The driver didn't respond to the order in time
+	PenaltyReasonOrderReassigned = "ORDER_REASSIGNED" // The restaurant gave the order to another driver
+)
+
+// DriverPenalty keeps a driver from receiving orders until the cooldown is over
+type DriverPenalty struct {
+	ID            int       `json:"id"`
+	UserID        string    `json:"user_id"`
+	OrderID       *int      `json:"order_id"`
+	Reason        string    `json:"reason"`
+	OffenseNumber int       `json:"offense_number"` // How many penalties the driver got within the penalty window, this one included
+	CooldownUntil time.Time `json:"cooldown_until"`
+	CreatedAt     time.Time `json:"created_at"`
+}
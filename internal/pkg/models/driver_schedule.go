@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// DriverSchedule is the weekly working hours of a driver. Times are in the driver's timezone
+type DriverSchedule struct {
+	Timezone string         `json:"timezone"`
+	Shifts   []*DriverShift `json:"shifts"`
+}
+
+type DriverShift struct {
+	ID        int    `json:"id"`
+	Weekday   int    `json:"weekday"`    // 0 is Sunday
+	StartTime string `json:"start_time"` // HH:MM
+	EndTime   string `json:"end_time"`   // HH:MM, the next day when it isn't after StartTime
+}
+
+// UpdateDriverScheduleRequest replaces the whole weekly schedule. An empty list of shifts makes the driver available at any time
+type UpdateDriverScheduleRequest struct {
+	Timezone string                `json:"timezone" validate:"required,timezone"`
+	Shifts   []*DriverShiftRequest `json:"shifts" validate:"max=50,dive,required"`
+}
+
+type DriverShiftRequest struct {
+	Weekday   *int   `json:"weekday" validate:"required,min=0,max=6"` // Pointer so Sunday (0) passes the required validation
+	StartTime string `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime   string `json:"end_time" validate:"required,datetime=15:04"`
+}
+
+type DriverTimeOff struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"user_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateDriverTimeOffRequest struct {
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	Reason   string    `json:"reason" validate:"max=255"`
+}
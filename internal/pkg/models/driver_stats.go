@@ -0,0 +1,15 @@
+package models
+
+// DriverStats is the performance of a driver over the last 30 days
+type DriverStats struct {
+	UserID                string   `json:"user_id"`
+	Offers                int      `json:"offers"`
+	Acceptances           int      `json:"acceptances"`
+	Rejections            int      `json:"rejections"`
+	Expiries              int      `json:"expiries"`
+	Fulfilments           int      `json:"fulfilments"`
+	MedianSecondsToAccept *float64 `json:"median_seconds_to_accept"` // Nil until the driver accepts an order
+	AcceptanceRate        *float64 `json:"acceptance_rate"`          // Nil until the driver receives an order
+	FulfilmentRate        *float64 `json:"fulfilment_rate"`          // Nil until an accepted order is fulfilled, cancelled or expired
+	Score                 float64  `json:"score"`                    // Reliability between 0 and 1, new drivers start at 1
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationFallback is a notification about an order that is sent on another channel (e.g. SMS) if the driver
+// doesn't acknowledge the order before SendAt
+type NotificationFallback struct {
+	ID          int       `json:"id"`
+	OrderID     int       `json:"order_id"`
+	Channel     string    `json:"channel"`
+	Event       string    `json:"event"`
+	RecipientID string    `json:"recipient_id"`
+	Phone       string    `json:"phone"`
+	FCMTokens   []string  `json:"fcm_tokens"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	SendAt      time.Time `json:"send_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -5,18 +5,35 @@ import (
 )
 
 type Order struct {
-	ID           int       `json:"id"`
-	UserID       string    `json:"user_id" validate:"required"`
-	RestaurantID string    `json:"restaurant_id" validate:"required"`
-	Code         string    `json:"code" validate:"required"`
-	Description  string    `json:"description"`
-	State        string    `json:"state" validate:"required"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              int        `json:"id"`
+	UserID          string     `json:"user_id" validate:"required"`
+	RestaurantID    string     `json:"restaurant_id" validate:"required"`
+	Code            string     `json:"code" validate:"required"`
+	Description     string     `json:"description"`
+	State           string     `json:"state" validate:"required"`
+	RequiredVehicle string     `json:"required_vehicle"` // Smallest vehicle able to carry the order, empty if any vehicle can
+	DeliveredAt     *time.Time `json:"delivered_at"`     // Set when the driver app received the new order notification
+	SeenAt          *time.Time `json:"seen_at"`          // Set when the driver opened the order
+	AcceptedAt      *time.Time `json:"accepted_at"`      // Set when the driver accepted the order
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// OrderFilter selects the orders listed by the admins, the empty fields don't filter
+type OrderFilter struct {
+	State         string
+	Code          string
+	RestaurantID  string
+	UserID        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Pagination
 }
 
 type CreateOrderRequest struct {
-	Description string `json:"description"`
+	Description     string `json:"description"`
+	RequiredVehicle string `json:"required_vehicle" validate:"omitempty,oneof=BICYCLE MOTORCYCLE CAR VAN"` // Optional, defaults to the default vehicle of the restaurant
+	BranchID        string `json:"branch_id"`                                                              // Optional, branch of the organization of the restaurant the order is sent from
 }
 
 type UpdateOrderRequest struct {
@@ -28,12 +45,25 @@ type UpdateOrderRequest struct {
 }
 
 type OrderResponse struct {
-	ID           int       `json:"id"`
-	UserID       string    `json:"user_id"`
-	RestaurantID string    `json:"restaurant_id"`
-	Code         string    `json:"code"`
-	Description  string    `json:"description"`
-	State        string    `json:"state"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              int        `json:"id"`
+	UserID          string     `json:"user_id"`
+	RestaurantID    string     `json:"restaurant_id"`
+	Code            string     `json:"code"`
+	Description     string     `json:"description"`
+	State           string     `json:"state"`
+	RequiredVehicle string     `json:"required_vehicle"`
+	DeliveredAt     *time.Time `json:"delivered_at"`
+	SeenAt          *time.Time `json:"seen_at"`
+	AcceptedAt      *time.Time `json:"accepted_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// OrderLocation is where the driver carrying an order is, and the path they took since accepting it
+type OrderLocation struct {
+	OrderID int              `json:"order_id"`
+	UserID  string           `json:"user_id"`
+	State   string           `json:"state"`
+	Latest  *LocationPoint   `json:"latest"` // Nil until the driver reports a location after accepting the order
+	Path    []*LocationPoint `json:"path"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Types of the order events published when an order changes
+const (
+	OrderEventCreated   = "ORDER_CREATED"
+	OrderEventAccepted  = "ORDER_ACCEPTED"
+	OrderEventRejected  = "ORDER_REJECTED"
+	OrderEventExpired   = "ORDER_EXPIRED"
+	OrderEventCancelled = "ORDER_CANCELLED"
+	OrderEventFulfilled = "ORDER_FULFILLED"
+	OrderEventDelivered = "ORDER_DELIVERED"
+	OrderEventSeen      = "ORDER_SEEN"
+)
+
+type OrderEvent struct {
+	Type         string    `json:"type"`
+	OrderID      int       `json:"order_id"`
+	RestaurantID string    `json:"restaurant_id"`
+	UserID       string    `json:"user_id"`
+	Code         string    `json:"code"`
+	State        string    `json:"state"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Organization groups the restaurants of a chain, its branches. It is managed by the restaurant that created it, which is also one of its branches
+type Organization struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	OwnerRestaurantID string    `json:"owner_restaurant_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required"`
+}
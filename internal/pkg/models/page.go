@@ -0,0 +1,15 @@
+package models
+
+// Pagination selects a page of a list
+type Pagination struct {
+	Limit  int
+	Offset int
+}
+
+// Page is a page of a list, with the number of items of the whole list
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
@@ -12,10 +12,34 @@ type Restaurant struct {
 	Name                string    `json:"name" validate:"required"`
 	PhoneNumber         string    `json:"phone_number"`
 	LocationDescription string    `json:"location_description" validate:"required"`
+	DefaultVehicle      string    `json:"default_vehicle"` // Required vehicle of the orders that don't set one, empty if any vehicle can
+	IsPaused            bool      `json:"is_paused"`       // Paused restaurants can't create orders
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 }
 
+// RestaurantFilter selects the restaurants listed by the admins, the empty fields don't filter
+type RestaurantFilter struct {
+	Search         string // Part of the ID or the name of the restaurants
+	OrganizationID *int
+	Pagination
+}
+
+// NearbyRestaurantFilter selects the restaurants within a radius of a location. The location and radius of the driver
+// searching are used when they aren't set
+type NearbyRestaurantFilter struct {
+	Longitude *float64
+	Latitude  *float64
+	Radius    int // In meters
+	Pagination
+}
+
+// NearbyRestaurant is a restaurant with its distance from the location searched around
+type NearbyRestaurant struct {
+	*Restaurant
+	Distance float64 // In meters
+}
+
 type CreateRestaurantRequest struct {
 	Longitude           float64 `json:"longitude" validate:"required"`
 	Latitude            float64 `json:"latitude" validate:"required"`
@@ -23,15 +47,33 @@ type CreateRestaurantRequest struct {
 	Name                string  `json:"name" validate:"required"`
 	LocationDescription string  `json:"location_description" validate:"required"`
 	PhoneNumber         string  `json:"phone_number"`
+	DefaultVehicle      string  `json:"default_vehicle" validate:"omitempty,oneof=BICYCLE MOTORCYCLE CAR VAN"`
 }
 
+// UpdateRestaurantRequest is a partial update of a restaurant, the fields left out keep their current value.
+// The longitude and latitude have to be sent together
 type UpdateRestaurantRequest struct {
-	Longitude           float64 `json:"longitude" validate:"required"`
-	Latitude            float64 `json:"latitude" validate:"required"`
-	LogoURL             string  `json:"logo_url" validate:"required,url"`
-	Name                string  `json:"name" validate:"required"`
-	LocationDescription string  `json:"location_description" validate:"required"`
-	PhoneNumber         string  `json:"phone_number"`
+	Longitude           *float64 `json:"longitude" validate:"required_with=Latitude,omitnil,longitude"`
+	Latitude            *float64 `json:"latitude" validate:"required_with=Longitude,omitnil,latitude"`
+	LogoURL             *string  `json:"logo_url" validate:"omitnil,url"`
+	Name                *string  `json:"name" validate:"omitnil,min=1"`
+	LocationDescription *string  `json:"location_description" validate:"omitnil,min=1"`
+	PhoneNumber         *string  `json:"phone_number"`
+	DefaultVehicle      *string  `json:"default_vehicle" validate:"omitempty,oneof=BICYCLE MOTORCYCLE CAR VAN"` // An empty string removes the default
+	IsPaused            *bool    `json:"is_paused"`
+}
+
+// RestaurantPatch holds the fields of a restaurant to update, the nil ones are left unchanged.
+// The location is only updated if both the longitude and the latitude are set
+type RestaurantPatch struct {
+	Longitude           *float64
+	Latitude            *float64
+	LogoURL             *string
+	Name                *string
+	LocationDescription *string
+	PhoneNumber         *string
+	DefaultVehicle      *string
+	IsPaused            *bool
 }
 
 type RestaurantResponse struct {
@@ -42,12 +84,96 @@ type RestaurantResponse struct {
 	Name                string    `json:"name"`
 	PhoneNumber         string    `json:"phone_number"`
 	LocationDescription string    `json:"location_description"`
+	DefaultVehicle      string    `json:"default_vehicle"`
+	IsPaused            bool      `json:"is_paused"`
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 }
 
+type NearbyRestaurantResponse struct {
+	*RestaurantResponse
+	Distance float64 `json:"distance"` // In meters
+}
+
 type RestaurantLogoUploadResponse struct {
 	PresignedURL  string `json:"presigned_url"`
 	StoredFileURL string `json:"stored_file_url"`
 	Description   string `json:"description"`
 }
+
+// RestaurantDevice is a device (mobile app or web push subscription) that the restaurant registered to receive notifications on.
+type RestaurantDevice struct {
+	ID           int       `json:"id"`
+	RestaurantID string    `json:"restaurant_id"`
+	FCMToken     string    `json:"fcm_token"`
+	Platform     string    `json:"platform"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type RegisterRestaurantDeviceRequest struct {
+	FCMToken string `json:"fcm_token" validate:"required"`
+	Platform string `json:"platform" validate:"required,oneof=ANDROID IOS WEB"`
+}
+
+type RestaurantDeviceResponse struct {
+	ID        int       `json:"id"`
+	Platform  string    `json:"platform"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Preferences of a restaurant for a driver
+const (
+	DriverPreferencePreferred = "PREFERRED" // The driver receives the orders of the restaurant before the other drivers in reach
+	DriverPreferenceBlocked   = "BLOCKED"   // The driver never receives the orders of the restaurant
+)
+
+// RestaurantDriverPreference is how a restaurant wants a driver to be considered when dispatching its orders
+type RestaurantDriverPreference struct {
+	RestaurantID string    `json:"restaurant_id"`
+	UserID       string    `json:"user_id"`
+	Preference   string    `json:"preference"`
+	Note         string    `json:"note"` // Why the driver is preferred or blocked, only seen by the restaurant
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type SetDriverPreferenceRequest struct {
+	Preference string `json:"preference" validate:"required,oneof=PREFERRED BLOCKED"`
+	Note       string `json:"note" validate:"max=500"`
+}
+
+// Roles of the members of a restaurant
+const (
+	RestaurantMemberRoleOwner   = "OWNER"   // Created the restaurant, the only one who can manage its staff or delete it
+	RestaurantMemberRoleManager = "MANAGER" // Manages the settings, drivers and webhooks of the restaurant
+	RestaurantMemberRoleCashier = "CASHIER" // Handles the orders
+)
+
+// RestaurantMember is an account that works for a restaurant
+type RestaurantMember struct {
+	RestaurantID string    `json:"restaurant_id"`
+	UID          string    `json:"uid"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RestaurantInvite lets the account with the email join the restaurant with the role until it expires
+type RestaurantInvite struct {
+	ID           int        `json:"id"`
+	RestaurantID string     `json:"restaurant_id"`
+	Email        string     `json:"email"`
+	Role         string     `json:"role"`
+	AcceptedBy   *string    `json:"accepted_by"` // UID of the account that accepted the invite, nil while it is pending
+	AcceptedAt   *time.Time `json:"accepted_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type CreateRestaurantInviteRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=MANAGER CASHIER"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// RestaurantOpeningHours is the weekly hours a restaurant takes orders in. Times are in the restaurant's timezone
+type RestaurantOpeningHours struct {
+	Timezone string           `json:"timezone"`
+	Periods  []*OpeningPeriod `json:"periods"`
+}
+
+type OpeningPeriod struct {
+	ID        int    `json:"id"`
+	Weekday   int    `json:"weekday"`    // 0 is Sunday
+	OpenTime  string `json:"open_time"`  // HH:MM
+	CloseTime string `json:"close_time"` // HH:MM, the next day when it isn't after OpenTime
+}
+
+// UpdateRestaurantOpeningHoursRequest replaces all the opening hours. An empty list of periods makes the restaurant open at any time
+type UpdateRestaurantOpeningHoursRequest struct {
+	Timezone string                  `json:"timezone" validate:"required,timezone"`
+	Periods  []*OpeningPeriodRequest `json:"periods" validate:"max=50,dive,required"`
+}
+
+type OpeningPeriodRequest struct {
+	Weekday   *int   `json:"weekday" validate:"required,min=0,max=6"` // Pointer so Sunday (0) passes the required validation
+	OpenTime  string `json:"open_time" validate:"required,datetime=15:04"`
+	CloseTime string `json:"close_time" validate:"required,datetime=15:04"`
+}
+
+type RestaurantClosure struct {
+	ID           int       `json:"id"`
+	RestaurantID string    `json:"restaurant_id"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateRestaurantClosureRequest struct {
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	Reason   string    `json:"reason" validate:"max=255"`
+}
+
+// Reasons a restaurant doesn't take orders
+const (
+	ClosedReasonPaused              = "PAUSED"                // The restaurant paused the orders
+	ClosedReasonClosure             = "CLOSURE"               // The restaurant is in one of its closures
+	ClosedReasonOutsideOpeningHours = "OUTSIDE_OPENING_HOURS" // None of the opening periods of the restaurant is on
+)
+
+// RestaurantAvailability is whether a restaurant takes orders right now
+type RestaurantAvailability struct {
+	IsOpen       bool   `json:"is_open"`
+	ClosedReason string `json:"closed_reason,omitempty"` // Why the restaurant is closed, empty when it is open
+}
+
+// RestaurantDetails is a restaurant with when it takes orders, shown to the drivers
+type RestaurantDetails struct {
+	*Restaurant
+	RestaurantAvailability
+	OpeningHours *RestaurantOpeningHours `json:"opening_hours"`
+	Closures     []*RestaurantClosure    `json:"closures"` // The current and upcoming closures
+}
@@ -5,42 +5,147 @@ import (
 )
 
 type User struct {
-	ID                string    `json:"id"`
-	Longitude         float64   `json:"longitude" validate:"required"`
-	Latitude          float64   `json:"latitude" validate:"required"`
-	IsActive          bool      `json:"is_active" validate:"required"`
-	Phone             string    `json:"phone" validate:"required,e164"`
-	Radius            int       `json:"radius" validate:"required"`
-	FCMToken          string    `json:"fcm_token" validate:"required"`
-	LastOrderReceived time.Time `json:"last_order_received"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                  string         `json:"id"`
+	Longitude           float64        `json:"longitude" validate:"required"`
+	Latitude            float64        `json:"latitude" validate:"required"`
+	IsActive            bool           `json:"is_active" validate:"required"`
+	Phone               string         `json:"phone" validate:"required,e164"`
+	Radius              int            `json:"radius" validate:"required"`
+	FCMToken            string         `json:"fcm_token" validate:"required"`
+	LastOrderReceived   time.Time      `json:"last_order_received"`
+	LocationUpdatedAt   *time.Time     `json:"location_updated_at"`
+	Timezone            string         `json:"timezone"`
+	MaxConcurrentOrders int            `json:"max_concurrent_orders"` // How many PENDING or ACCEPTED orders the driver can have at the same time
+	ActivePenalty       *DriverPenalty `json:"active_penalty"`        // Only set by GetUser, nil if the driver isn't on a cooldown
+	Status              string         `json:"status"`                // Only APPROVED drivers receive orders
+	VehicleType         string         `json:"vehicle_type"`
+	IDPhotoURL          string         `json:"id_photo_url"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+}
+
+// UserFilter selects the users listed by the admins, the empty fields don't filter
+type UserFilter struct {
+	Search      string // Part of the ID or the phone of the users
+	Status      string
+	IsActive    *bool
+	VehicleType string
+	Pagination
 }
 
 type CreateUserRequest struct {
-	Longitude float64 `json:"longitude" validate:"required"`
-	Latitude  float64 `json:"latitude" validate:"required"`
-	IsActive  *bool   `json:"is_active" validate:"required"` // Pointer to a bool so the validation library doesn't complain if the value is false
-	Phone     string  `json:"phone" validate:"required,e164"`
-	Radius    int     `json:"radius" validate:"required"`
-	FCMToken  string  `json:"fcm_token" validate:"required"`
+	Longitude           float64 `json:"longitude" validate:"required"`
+	Latitude            float64 `json:"latitude" validate:"required"`
+	IsActive            *bool   `json:"is_active" validate:"required"` // Pointer to a bool so the validation library doesn't complain if the value is false
+	Phone               string  `json:"phone" validate:"required,e164"`
+	Radius              int     `json:"radius" validate:"required"`
+	FCMToken            string  `json:"fcm_token" validate:"required"`
+	MaxConcurrentOrders int     `json:"max_concurrent_orders" validate:"omitempty,min=1,max=10"` // Optional, drivers carry a single order at a time by default
+	VehicleType         string  `json:"vehicle_type" validate:"omitempty,oneof=BICYCLE MOTORCYCLE CAR VAN"`
+	IDPhotoURL          string  `json:"id_photo_url" validate:"omitempty,url"` // The stored_file_url returned by /users/me/id-photo/uploadurl
 }
 
+// UpdateUserRequest is a partial update of a user, the fields left out keep their current value.
+// The longitude and latitude have to be sent together
 type UpdateUserRequest struct {
-	Longitude float64 `json:"longitude" validate:"required"`
-	Latitude  float64 `json:"latitude" validate:"required"`
-	IsActive  *bool   `json:"is_active" validate:"required"`
-	Phone     string  `json:"phone" validate:"required,e164"`
-	Radius    int     `json:"radius" validate:"required"`
-	FCMToken  string  `json:"fcm_token" validate:"required"`
+	Longitude           *float64 `json:"longitude" validate:"required_with=Latitude,omitnil,longitude"`
+	Latitude            *float64 `json:"latitude" validate:"required_with=Longitude,omitnil,latitude"`
+	IsActive            *bool    `json:"is_active"`
+	Phone               *string  `json:"phone" validate:"omitnil,e164"`
+	Radius              *int     `json:"radius" validate:"omitnil,min=1"`
+	FCMToken            *string  `json:"fcm_token" validate:"omitnil,min=1"`
+	MaxConcurrentOrders *int     `json:"max_concurrent_orders" validate:"omitnil,min=1,max=10"`
+	VehicleType         *string  `json:"vehicle_type" validate:"omitnil,oneof=BICYCLE MOTORCYCLE CAR VAN"`
+	IDPhotoURL          *string  `json:"id_photo_url" validate:"omitnil,url"`
+}
+
+// UserPatch holds the fields of a user to update, the nil ones are left unchanged.
+// The location is only updated if both the longitude and the latitude are set
+type UserPatch struct {
+	Longitude           *float64
+	Latitude            *float64
+	IsActive            *bool
+	Phone               *string
+	Radius              *int
+	FCMToken            *string
+	MaxConcurrentOrders *int
+	VehicleType         *string
+	IDPhotoURL          *string
+	LastOrderReceived   *time.Time // Only set by dispatch, drivers can't change it
 }
 
 type UserResponse struct {
-	ID                string    `json:"id"`
-	Longitude         float64   `json:"longitude"`
-	Latitude          float64   `json:"latitude"`
-	IsActive          bool      `json:"is_active"`
-	Phone             string    `json:"phone"`
-	Radius            int       `json:"radius"`
-	LastOrderReceived time.Time `json:"last_order_received"`
+	ID                  string         `json:"id"`
+	Longitude           float64        `json:"longitude"`
+	Latitude            float64        `json:"latitude"`
+	IsActive            bool           `json:"is_active"`
+	Phone               string         `json:"phone"`
+	Radius              int            `json:"radius"`
+	LastOrderReceived   time.Time      `json:"last_order_received"`
+	LocationUpdatedAt   *time.Time     `json:"location_updated_at"`
+	Timezone            string         `json:"timezone"`
+	MaxConcurrentOrders int            `json:"max_concurrent_orders"`
+	ActivePenalty       *DriverPenalty `json:"active_penalty"`
+	Status              string         `json:"status"`
+	VehicleType         string         `json:"vehicle_type"`
+	IDPhotoURL          string         `json:"id_photo_url"`
+}
+
+// Statuses of a driver. Drivers start pending and only receive orders once an admin approved their documents
+const (
+	UserStatusPendingApproval = "PENDING_APPROVAL"
+	UserStatusApproved        = "APPROVED"
+	UserStatusSuspended       = "SUSPENDED"
+)
+
+// Vehicles a driver can deliver with
+const (
+	VehicleTypeBicycle    = "BICYCLE"
+	VehicleTypeMotorcycle = "MOTORCYCLE"
+	VehicleTypeCar        = "CAR"
+	VehicleTypeVan        = "VAN"
+)
+
+// VehicleTypes are ordered from the smallest to the largest vehicle.
+// A driver can carry the orders that require their vehicle or a smaller one
+var VehicleTypes = []string{VehicleTypeBicycle, VehicleTypeMotorcycle, VehicleTypeCar, VehicleTypeVan}
+
+type UserIDPhotoUploadResponse struct {
+	PresignedURL  string `json:"presigned_url"`
+	StoredFileURL string `json:"stored_file_url"`
+	Description   string `json:"description"`
+}
+
+// UpdateLocationRequest is a position reported by the driver app. Accuracy is the radius in meters reported by the device
+type UpdateLocationRequest struct {
+	Longitude  float64    `json:"longitude" validate:"required,longitude"`
+	Latitude   float64    `json:"latitude" validate:"required,latitude"`
+	Accuracy   *float64   `json:"accuracy" validate:"omitempty,gte=0"`
+	RecordedAt *time.Time `json:"recorded_at"` // When the device recorded the position, defaults to now
+}
+
+// UpdateLocationBatchRequest holds the positions the driver app buffered while offline
+type UpdateLocationBatchRequest struct {
+	Points []*UpdateLocationRequest `json:"points" validate:"required,min=1,max=100,dive,required"`
+}
+
+// LocationPoint is a position of a driver recorded by their device
+type LocationPoint struct {
+	Longitude  float64   `json:"longitude"`
+	Latitude   float64   `json:"latitude"`
+	Accuracy   *float64  `json:"accuracy"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Reasons a location update is not applied
+const (
+	LocationRejectedThrottled   = "THROTTLED"
+	LocationRejectedLowAccuracy = "LOW_ACCURACY"
+	LocationRejectedStale       = "STALE"
+)
+
+type LocationUpdateResponse struct {
+	Accepted          bool       `json:"accepted"`
+	Reason            string     `json:"reason,omitempty"` // Why the location wasn't applied, one of THROTTLED (a location was stored less than the minimum interval before), LOW_ACCURACY or STALE (recorded too long ago)
+	LocationUpdatedAt *time.Time `json:"location_updated_at"`
 }
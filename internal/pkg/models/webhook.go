@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// States of a webhook delivery
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliverySucceeded = "SUCCEEDED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+type WebhookEndpoint struct {
+	ID           int       `json:"id"`
+	RestaurantID string    `json:"restaurant_id"`
+	URL          string    `json:"url"`
+	Secret       string    `json:"-"`
+	EventTypes   []string  `json:"event_types"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type CreateWebhookEndpointRequest struct {
+	// Deliveries are signed but not encrypted, so only https endpoints are accepted
+	URL        string   `json:"url" validate:"required,url,startswith=https://"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=ORDER_CREATED ORDER_ACCEPTED ORDER_REJECTED ORDER_EXPIRED ORDER_CANCELLED ORDER_FULFILLED ORDER_DELIVERED ORDER_SEEN"`
+}
+
+type WebhookEndpointResponse struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreatedWebhookEndpointResponse is only returned when the endpoint is created since it is the only time the secret is shown
+type CreatedWebhookEndpointResponse struct {
+	WebhookEndpointResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDelivery struct {
+	ID                int                       `json:"id"`
+	WebhookEndpointID int                       `json:"webhook_endpoint_id"`
+	EventType         string                    `json:"event_type"`
+	Payload           json.RawMessage           `json:"payload" swaggertype:"object"`
+	State             string                    `json:"state"`
+	AttemptCount      int                       `json:"attempt_count"`
+	NextAttemptAt     *time.Time                `json:"next_attempt_at"`
+	Attempts          []*WebhookDeliveryAttempt `json:"attempts,omitempty"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	UpdatedAt         time.Time                 `json:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID                int       `json:"id"`
+	WebhookDeliveryID int       `json:"webhook_delivery_id"`
+	AttemptNumber     int       `json:"attempt_number"`
+	StatusCode        *int      `json:"status_code"`
+	Error             string    `json:"error,omitempty"`
+	DurationMs        int       `json:"duration_ms"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the body posted to the webhook endpoints. Data holds the stored payload of the delivery, i.e. the OrderEvent
+type WebhookPayload struct {
+	DeliveryID int             `json:"delivery_id"`
+	EventType  string          `json:"event_type"`
+	Data       json.RawMessage `json:"data" swaggertype:"object"`
+}
@@ -3,19 +3,41 @@ package utils
 import (
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
-	Port                  int
-	DBConn                string
-	LogLevel              string
-	FirebaseConfigJSON    string
-	RestaurantLogosBucket string
-	Stage                 string
+	Port                   int
+	DBConn                 string
+	LogLevel               string
+	FirebaseConfigJSON     string
+	RestaurantLogosBucket  string
+	DriverDocumentsBucket  string
+	Stage                  string
+	NotificationProvider   string
+	SMSGatewayURL          string
+	SMSGatewayAPIKey       string
+	SMSSender              string
+	SMSFallbackDelay       time.Duration
+	OrderEventsBackend     string
+	LocationMinInterval    time.Duration
+	LocationMaxAccuracy    int
+	LocationMaxAge         time.Duration
+	LocationTrailRetention time.Duration
+	PenaltyCooldowns       []time.Duration
+	PenaltyWindow          time.Duration
+	DispatchRankByScore    bool
+	AuthProvider           string
+	AuthCheckRevoked       bool
+	AuthRevocationCacheTTL time.Duration
+	LocalAuthSecret        string
+	LocalAuthTokenTTL      time.Duration
 }
 
 func GetConfig() Config {
@@ -27,14 +49,53 @@ func GetConfig() Config {
 	flag.StringVar(&cfg.LogLevel, "log-level", getEnv("LOG_LEVEL", "debug"), "Log level")
 	flag.StringVar(&cfg.FirebaseConfigJSON, "firebase-config-json", getEnv("FIREBASE_CONFIG_JSON", ""), "JSON string of the configuration for Firebase Authentication.")
 	flag.StringVar(&cfg.RestaurantLogosBucket, "restaurant-logos-bucket", getEnv("RESTAURANT_LOGOS_BUCKET", "dev-tamra-restaurant-logos"), "Name of the bucket where restaurant logos are stored")
+	flag.StringVar(&cfg.DriverDocumentsBucket, "driver-documents-bucket", getEnv("DRIVER_DOCUMENTS_BUCKET", "dev-tamra-driver-documents"), "Name of the bucket where the ID photos of the drivers are stored")
 	flag.StringVar(&cfg.Stage, "stage", getEnv("STAGE", "dev"), "Stage of the application")
+	flag.StringVar(&cfg.NotificationProvider, "notification-provider", getEnv("NOTIFICATION_PROVIDER", "fcm"), "Provider used for push notifications. Either fcm or log (for local development)")
+	flag.StringVar(&cfg.SMSGatewayURL, "sms-gateway-url", getEnv("SMS_GATEWAY_URL", ""), "URL of the HTTP SMS gateway. SMS notifications are disabled if empty")
+	flag.StringVar(&cfg.SMSGatewayAPIKey, "sms-gateway-api-key", getEnv("SMS_GATEWAY_API_KEY", ""), "API key sent as a bearer token to the SMS gateway")
+	flag.StringVar(&cfg.SMSSender, "sms-sender", getEnv("SMS_SENDER", "Tamra"), "Sender name or number of the SMS notifications")
+	flag.DurationVar(&cfg.SMSFallbackDelay, "sms-fallback-delay", getEnvAsDuration("SMS_FALLBACK_DELAY", 2*time.Minute), "How long to wait for a driver to acknowledge a new order before sending it by SMS")
+	flag.StringVar(&cfg.OrderEventsBackend, "order-events-backend", getEnv("ORDER_EVENTS_BACKEND", "postgres"), "Backend of the order events streams. Either postgres (LISTEN/NOTIFY, shared between instances) or memory (single instance)")
+	flag.DurationVar(&cfg.LocationMinInterval, "location-min-interval", getEnvAsDuration("LOCATION_MIN_INTERVAL", 10*time.Second), "Minimum time between two stored locations of a driver, more frequent updates are ignored")
+	flag.IntVar(&cfg.LocationMaxAccuracy, "location-max-accuracy", getEnvAsInt("LOCATION_MAX_ACCURACY", 100), "Locations with a reported accuracy above this many meters are ignored")
+	flag.DurationVar(&cfg.LocationMaxAge, "location-max-age", getEnvAsDuration("LOCATION_MAX_AGE", 10*time.Minute), "Locations recorded longer ago than this are ignored")
+	flag.DurationVar(&cfg.LocationTrailRetention, "location-trail-retention", getEnvAsDuration("LOCATION_TRAIL_RETENTION", 72*time.Hour), "How long the location trails of the orders are kept before being purged")
+	penaltyCooldowns := flag.String("penalty-cooldowns", getEnv("PENALTY_COOLDOWNS", "15m,1h,4h,24h"), "Comma separated cooldowns of the successive penalties of a driver within the penalty window")
+	flag.DurationVar(&cfg.PenaltyWindow, "penalty-window", getEnvAsDuration("PENALTY_WINDOW", 24*time.Hour), "Penalties older than this don't count towards the escalation of the cooldown")
+	flag.BoolVar(&cfg.DispatchRankByScore, "dispatch-rank-by-score", getEnvAsBool("DISPATCH_RANK_BY_SCORE", false), "Offer new orders to the drivers with the best reliability score first instead of only taking turns")
+	flag.StringVar(&cfg.AuthProvider, "auth-provider", getEnv("AUTH_PROVIDER", "firebase"), "Provider of the accounts and tokens. Either firebase or local (tokens signed with the local auth secret, for local development and tests)")
+	flag.BoolVar(&cfg.AuthCheckRevoked, "auth-check-revoked", getEnvAsBool("AUTH_CHECK_REVOKED", false), "Reject the Firebase tokens of the accounts that were signed out or deleted before the tokens expire")
+	flag.DurationVar(&cfg.AuthRevocationCacheTTL, "auth-revocation-cache-ttl", getEnvAsDuration("AUTH_REVOCATION_CACHE_TTL", time.Minute), "How long a token that passed the revocation check is trusted without checking it again")
+	flag.StringVar(&cfg.LocalAuthSecret, "local-auth-secret", getEnv("LOCAL_AUTH_SECRET", ""), "Secret used to sign the tokens of the local auth provider")
+	flag.DurationVar(&cfg.LocalAuthTokenTTL, "local-auth-token-ttl", getEnvAsDuration("LOCAL_AUTH_TOKEN_TTL", time.Hour), "How long the tokens of the local auth provider are valid")
 	flag.Parse()
 
-	fmt.Printf("Configuration values: %v\n", cfg)
-	logrus.Warn("firebase config json: ", cfg.FirebaseConfigJSON)
+	cfg.PenaltyCooldowns = parseDurations(*penaltyCooldowns)
+
+	fmt.Printf("Configuration values: %+v\n", cfg.Redacted())
 	return cfg
 }
 
+// redacted replaces the secrets in the printed configuration
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of the configuration that is safe to print, i.e. without the secrets and the database password
+func (c Config) Redacted() Config {
+	if dbURL, err := url.Parse(c.DBConn); err == nil && dbURL.Scheme != "" {
+		c.DBConn = dbURL.Redacted()
+	} else if c.DBConn != "" {
+		// Connection strings in the key=value format can't be redacted without parsing them, so they aren't printed at all
+		c.DBConn = redacted
+	}
+	for _, secret := range []*string{&c.FirebaseConfigJSON, &c.SMSGatewayAPIKey, &c.LocalAuthSecret} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	return c
+}
+
 func getEnv(key string, defaultVal string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -50,3 +111,35 @@ func getEnvAsInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+func getEnvAsBool(key string, defaultVal bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultVal
+}
+
+func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultVal
+}
+
+// parseDurations parses a comma separated list of durations, skipping the invalid ones
+func parseDurations(value string) []time.Duration {
+	durations := []time.Duration{}
+	for _, part := range strings.Split(value, ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			logrus.WithError(err).Warnf("Ignoring invalid duration %q", part)
+			continue
+		}
+		durations = append(durations, duration)
+	}
+	return durations
+}
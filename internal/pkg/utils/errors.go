@@ -6,4 +6,34 @@ var (
 	ErrNotFound         = errors.New("not found")
 	ErrForbidden        = errors.New("forbidden")
 	ErrOrderNotAccepted = errors.New("order not accepted")
+	// ErrInvalidOrderState is returned when an order can't move to a state from the one it is in, e.g. cancelling a fulfilled order
+	ErrInvalidOrderState = errors.New("invalid order state")
+	// ErrInvalidSchedule is returned when a shift of a driver schedule or an opening period of a restaurant starts and ends at the same time
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	// ErrInvalidZone is returned when the polygon of a delivery zone has points out of range, crosses itself or doesn't enclose an area
+	ErrInvalidZone = errors.New("invalid delivery zone")
+	// ErrRestaurantPaused is returned when a paused restaurant creates an order
+	ErrRestaurantPaused = errors.New("restaurant paused")
+	// ErrRestaurantClosed is returned when a restaurant creates an order during one of its closures
+	ErrRestaurantClosed = errors.New("restaurant closed")
+	// ErrOutsideOpeningHours is returned when a restaurant creates an order outside of its opening hours
+	ErrOutsideOpeningHours = errors.New("outside opening hours")
+	// ErrRecipientUnreachable is returned by notification channels when the recipient has no address for the channel
+	ErrRecipientUnreachable = errors.New("recipient unreachable")
+	// ErrMissingDocuments is returned when approving a driver who didn't provide their vehicle type or ID photo
+	ErrMissingDocuments = errors.New("missing documents")
+	// ErrInvalidDocuments is returned when approving a driver whose ID photo wasn't uploaded to the documents bucket
+	ErrInvalidDocuments = errors.New("invalid documents")
+	// ErrRoleAlreadyAssigned is returned when an account that already has a role tries to pick one
+	ErrRoleAlreadyAssigned = errors.New("role already assigned")
+	// ErrRoleMismatch is returned when an account picks a role that contradicts what it already is, e.g. a driver picking the restaurant role
+	ErrRoleMismatch = errors.New("role doesn't match the account")
+	// ErrAlreadyMember is returned when an account that works for a restaurant accepts an invite, accounts work for a single restaurant
+	ErrAlreadyMember = errors.New("already a member of a restaurant")
+	// ErrAlreadyInOrganization is returned when a restaurant that is already a branch of an organization creates one
+	ErrAlreadyInOrganization = errors.New("already in an organization")
+	// ErrEmailNotVerified is returned when an account accepts an invite without a verified email
+	ErrEmailNotVerified = errors.New("email not verified")
+	// ErrInvalidToken is returned when a token is malformed, wrongly signed or expired
+	ErrInvalidToken = errors.New("invalid token")
 )
@@ -4,31 +4,42 @@ package utils
 
 import (
 	"Tamra/internal/pkg/models"
+	"time"
 )
 
 // TODO: is there a better way of mapping these? Maybe use a library like mapstruct?
 // MapCreateUserRequestToUser maps a CreateUserRequest to a User.
 func MapCreateUserRequestToUser(req *models.CreateUserRequest) *models.User {
 	return &models.User{
-		Longitude: req.Longitude,
-		Latitude:  req.Latitude,
-		Phone:     req.Phone,
-		Radius:    req.Radius,
-		FCMToken:  req.FCMToken,
-		IsActive:  *req.IsActive,
+		Longitude:           req.Longitude,
+		Latitude:            req.Latitude,
+		Phone:               req.Phone,
+		Radius:              req.Radius,
+		FCMToken:            req.FCMToken,
+		IsActive:            *req.IsActive,
+		MaxConcurrentOrders: req.MaxConcurrentOrders,
+		VehicleType:         req.VehicleType,
+		IDPhotoURL:          req.IDPhotoURL,
 	}
 }
 
 // MapUserToUserResponse maps a User to a UserResponse.
 func MapUserToUserResponse(user *models.User) *models.UserResponse {
 	return &models.UserResponse{
-		ID:                user.ID,
-		Longitude:         user.Longitude,
-		Latitude:          user.Latitude,
-		IsActive:          user.IsActive,
-		Phone:             user.Phone,
-		Radius:            user.Radius,
-		LastOrderReceived: user.LastOrderReceived,
+		ID:                  user.ID,
+		Longitude:           user.Longitude,
+		Latitude:            user.Latitude,
+		IsActive:            user.IsActive,
+		Phone:               user.Phone,
+		Radius:              user.Radius,
+		LastOrderReceived:   user.LastOrderReceived,
+		LocationUpdatedAt:   user.LocationUpdatedAt,
+		Timezone:            user.Timezone,
+		MaxConcurrentOrders: user.MaxConcurrentOrders,
+		ActivePenalty:       user.ActivePenalty,
+		Status:              user.Status,
+		VehicleType:         user.VehicleType,
+		IDPhotoURL:          user.IDPhotoURL,
 	}
 }
 
@@ -41,37 +52,126 @@ func MapUsersToUserResponses(users []*models.User) []*models.UserResponse {
 	return userResponses
 }
 
-func MapUpdateUserRequestToUser(req *models.UpdateUserRequest) *models.User {
-	return &models.User{
-		Longitude: req.Longitude,
-		Latitude:  req.Latitude,
-		IsActive:  *req.IsActive,
-		Phone:     req.Phone,
-		Radius:    req.Radius,
-		FCMToken:  req.FCMToken,
+// MapUpdateUserRequestToUserPatch maps an UpdateUserRequest to a UserPatch.
+func MapUpdateUserRequestToUserPatch(req *models.UpdateUserRequest) *models.UserPatch {
+	return &models.UserPatch{
+		Longitude:           req.Longitude,
+		Latitude:            req.Latitude,
+		IsActive:            req.IsActive,
+		Phone:               req.Phone,
+		Radius:              req.Radius,
+		FCMToken:            req.FCMToken,
+		MaxConcurrentOrders: req.MaxConcurrentOrders,
+		VehicleType:         req.VehicleType,
+		IDPhotoURL:          req.IDPhotoURL,
+	}
+}
+
+// MapUpdateLocationRequestToLocationPoint maps an UpdateLocationRequest to a LocationPoint.
+// Points without a recording time are considered recorded now.
+func MapUpdateLocationRequestToLocationPoint(req *models.UpdateLocationRequest) *models.LocationPoint {
+	recordedAt := time.Now()
+	if req.RecordedAt != nil {
+		recordedAt = *req.RecordedAt
+	}
+	return &models.LocationPoint{
+		Longitude:  req.Longitude,
+		Latitude:   req.Latitude,
+		Accuracy:   req.Accuracy,
+		RecordedAt: recordedAt,
+	}
+}
+
+func MapUpdateLocationRequestsToLocationPoints(reqs []*models.UpdateLocationRequest) []*models.LocationPoint {
+	points := make([]*models.LocationPoint, len(reqs))
+	for i, req := range reqs {
+		points[i] = MapUpdateLocationRequestToLocationPoint(req)
+	}
+	return points
+}
+
+// MapUpdateDriverScheduleRequestToDriverSchedule maps an UpdateDriverScheduleRequest to a DriverSchedule.
+func MapUpdateDriverScheduleRequestToDriverSchedule(req *models.UpdateDriverScheduleRequest) *models.DriverSchedule {
+	shifts := make([]*models.DriverShift, len(req.Shifts))
+	for i, shift := range req.Shifts {
+		shifts[i] = &models.DriverShift{
+			Weekday:   *shift.Weekday,
+			StartTime: shift.StartTime,
+			EndTime:   shift.EndTime,
+		}
+	}
+	return &models.DriverSchedule{
+		Timezone: req.Timezone,
+		Shifts:   shifts,
+	}
+}
+
+// MapUpdateRestaurantOpeningHoursRequestToRestaurantOpeningHours maps an UpdateRestaurantOpeningHoursRequest to RestaurantOpeningHours.
+func MapUpdateRestaurantOpeningHoursRequestToRestaurantOpeningHours(req *models.UpdateRestaurantOpeningHoursRequest) *models.RestaurantOpeningHours {
+	periods := make([]*models.OpeningPeriod, len(req.Periods))
+	for i, period := range req.Periods {
+		periods[i] = &models.OpeningPeriod{
+			Weekday:   *period.Weekday,
+			OpenTime:  period.OpenTime,
+			CloseTime: period.CloseTime,
+		}
+	}
+	return &models.RestaurantOpeningHours{
+		Timezone: req.Timezone,
+		Periods:  periods,
+	}
+}
+
+// MapCreateRestaurantClosureRequestToRestaurantClosure maps a CreateRestaurantClosureRequest to a RestaurantClosure.
+func MapCreateRestaurantClosureRequestToRestaurantClosure(req *models.CreateRestaurantClosureRequest) *models.RestaurantClosure {
+	return &models.RestaurantClosure{
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	}
+}
+
+// MapSetDeliveryZoneRequestToDeliveryZone maps a SetDeliveryZoneRequest to a DeliveryZone.
+func MapSetDeliveryZoneRequestToDeliveryZone(req *models.SetDeliveryZoneRequest) *models.DeliveryZone {
+	return &models.DeliveryZone{
+		Type:    req.Type,
+		Polygon: req.Polygon,
+		Radius:  req.Radius,
+	}
+}
+
+// MapCreateDriverTimeOffRequestToDriverTimeOff maps a CreateDriverTimeOffRequest to a DriverTimeOff.
+func MapCreateDriverTimeOffRequestToDriverTimeOff(req *models.CreateDriverTimeOffRequest) *models.DriverTimeOff {
+	return &models.DriverTimeOff{
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
 	}
 }
 
 // MapCreateRestaurantRequestToRestaurant maps a CreateRestaurantRequest to a Restaurant.
 func MapCreateRestaurantRequestToRestaurant(req *models.CreateRestaurantRequest) *models.Restaurant {
 	return &models.Restaurant{
-		Longitude: req.Longitude,
-		Latitude:  req.Latitude,
-		LogoURL:   req.LogoURL,
-		Name:      req.Name,
+		Longitude:      req.Longitude,
+		Latitude:       req.Latitude,
+		LogoURL:        req.LogoURL,
+		Name:           req.Name,
+		DefaultVehicle: req.DefaultVehicle,
 	}
 }
 
 // MapRestaurantToRestaurantResponse maps a Restaurant to a RestaurantResponse.
 func MapRestaurantToRestaurantResponse(restaurant *models.Restaurant) *models.RestaurantResponse {
 	return &models.RestaurantResponse{
-		ID:        restaurant.ID,
-		Longitude: restaurant.Longitude,
-		Latitude:  restaurant.Latitude,
-		LogoURL:   restaurant.LogoURL,
-		Name:      restaurant.Name,
-		CreatedAt: restaurant.CreatedAt,
-		UpdatedAt: restaurant.UpdatedAt,
+		ID:             restaurant.ID,
+		Longitude:      restaurant.Longitude,
+		Latitude:       restaurant.Latitude,
+		LogoURL:        restaurant.LogoURL,
+		Name:           restaurant.Name,
+		DefaultVehicle: restaurant.DefaultVehicle,
+		IsPaused:       restaurant.IsPaused,
+		CreatedAt:      restaurant.CreatedAt,
+		UpdatedAt:      restaurant.UpdatedAt,
 	}
 }
 
@@ -84,33 +184,52 @@ func MapRestaurantsToRestaurantResponses(restaurants []*models.Restaurant) []*mo
 	return restaurantResponses
 }
 
-func MapUpdateRestaurantRequestToRestaurant(req *models.UpdateRestaurantRequest) *models.Restaurant {
-	return &models.Restaurant{
-		Longitude: req.Longitude,
-		Latitude:  req.Latitude,
-		LogoURL:   req.LogoURL,
-		Name:      req.Name,
+// MapNearbyRestaurantsToNearbyRestaurantResponses maps NearbyRestaurants to NearbyRestaurantResponses.
+func MapNearbyRestaurantsToNearbyRestaurantResponses(restaurants []*models.NearbyRestaurant) []*models.NearbyRestaurantResponse {
+	restaurantResponses := make([]*models.NearbyRestaurantResponse, len(restaurants))
+	for i, restaurant := range restaurants {
+		restaurantResponses[i] = &models.NearbyRestaurantResponse{RestaurantResponse: MapRestaurantToRestaurantResponse(restaurant.Restaurant), Distance: restaurant.Distance}
+	}
+	return restaurantResponses
+}
+
+// MapUpdateRestaurantRequestToRestaurantPatch maps an UpdateRestaurantRequest to a RestaurantPatch.
+func MapUpdateRestaurantRequestToRestaurantPatch(req *models.UpdateRestaurantRequest) *models.RestaurantPatch {
+	return &models.RestaurantPatch{
+		Longitude:           req.Longitude,
+		Latitude:            req.Latitude,
+		LogoURL:             req.LogoURL,
+		Name:                req.Name,
+		LocationDescription: req.LocationDescription,
+		PhoneNumber:         req.PhoneNumber,
+		DefaultVehicle:      req.DefaultVehicle,
+		IsPaused:            req.IsPaused,
 	}
 }
 
 // MapCreateOrderRequestToOrder maps a CreateOrderRequest to a Order.
 func MapCreateOrderRequestToOrder(req *models.CreateOrderRequest) *models.Order {
 	return &models.Order{
-		Description: req.Description,
+		Description:     req.Description,
+		RequiredVehicle: req.RequiredVehicle,
 	}
 }
 
 // MapOrderToOrderResponse maps a Order to a OrderResponse.
 func MapOrderToOrderResponse(order *models.Order) *models.OrderResponse {
 	return &models.OrderResponse{
-		ID:           order.ID,
-		UserID:       order.UserID,
-		RestaurantID: order.RestaurantID,
-		Code:         order.Code,
-		Description:  order.Description,
-		State:        order.State,
-		CreatedAt:    order.CreatedAt,
-		UpdatedAt:    order.UpdatedAt,
+		ID:              order.ID,
+		UserID:          order.UserID,
+		RestaurantID:    order.RestaurantID,
+		Code:            order.Code,
+		Description:     order.Description,
+		State:           order.State,
+		RequiredVehicle: order.RequiredVehicle,
+		DeliveredAt:     order.DeliveredAt,
+		SeenAt:          order.SeenAt,
+		AcceptedAt:      order.AcceptedAt,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
 	}
 }
 
@@ -132,3 +251,59 @@ func MapUpdateOrderRequestToOrder(req *models.UpdateOrderRequest) *models.Order
 		State:        req.State,
 	}
 }
+
+// MapRegisterRestaurantDeviceRequestToRestaurantDevice maps a RegisterRestaurantDeviceRequest to a RestaurantDevice.
+func MapRegisterRestaurantDeviceRequestToRestaurantDevice(req *models.RegisterRestaurantDeviceRequest) *models.RestaurantDevice {
+	return &models.RestaurantDevice{
+		FCMToken: req.FCMToken,
+		Platform: req.Platform,
+	}
+}
+
+// MapRestaurantDeviceToRestaurantDeviceResponse maps a RestaurantDevice to a RestaurantDeviceResponse.
+// The FCM token is left out on purpose, it is only needed by the server.
+func MapRestaurantDeviceToRestaurantDeviceResponse(device *models.RestaurantDevice) *models.RestaurantDeviceResponse {
+	return &models.RestaurantDeviceResponse{
+		ID:        device.ID,
+		Platform:  device.Platform,
+		CreatedAt: device.CreatedAt,
+		UpdatedAt: device.UpdatedAt,
+	}
+}
+
+func MapRestaurantDevicesToRestaurantDeviceResponses(devices []*models.RestaurantDevice) []*models.RestaurantDeviceResponse {
+	deviceResponses := make([]*models.RestaurantDeviceResponse, len(devices))
+	for i, device := range devices {
+		deviceResponses[i] = MapRestaurantDeviceToRestaurantDeviceResponse(device)
+	}
+	return deviceResponses
+}
+
+// MapCreateWebhookEndpointRequestToWebhookEndpoint maps a CreateWebhookEndpointRequest to a WebhookEndpoint.
+func MapCreateWebhookEndpointRequestToWebhookEndpoint(req *models.CreateWebhookEndpointRequest) *models.WebhookEndpoint {
+	return &models.WebhookEndpoint{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+	}
+}
+
+// MapWebhookEndpointToWebhookEndpointResponse maps a WebhookEndpoint to a WebhookEndpointResponse.
+// The secret is left out on purpose, it is only shown once when the endpoint is created.
+func MapWebhookEndpointToWebhookEndpointResponse(endpoint *models.WebhookEndpoint) *models.WebhookEndpointResponse {
+	return &models.WebhookEndpointResponse{
+		ID:         endpoint.ID,
+		URL:        endpoint.URL,
+		EventTypes: endpoint.EventTypes,
+		IsActive:   endpoint.IsActive,
+		CreatedAt:  endpoint.CreatedAt,
+		UpdatedAt:  endpoint.UpdatedAt,
+	}
+}
+
+func MapWebhookEndpointsToWebhookEndpointResponses(endpoints []*models.WebhookEndpoint) []*models.WebhookEndpointResponse {
+	endpointResponses := make([]*models.WebhookEndpointResponse, len(endpoints))
+	for i, endpoint := range endpoints {
+		endpointResponses[i] = MapWebhookEndpointToWebhookEndpointResponse(endpoint)
+	}
+	return endpointResponses
+}
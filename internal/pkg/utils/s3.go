@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -10,8 +11,11 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
+// s3Region is the region of the buckets
+const s3Region = "eu-central-1"
+
 func GetS3PresignedURL(UID, bucketName string) (string, string, error) {
-	const region = "eu-central-1"
+	const region = s3Region
 	mySession := session.Must(session.NewSession())
 
 	// Create a S3 client with additional configuration
@@ -37,7 +41,29 @@ func GetS3PresignedURL(UID, bucketName string) (string, string, error) {
 		return "", "", err
 	}
 
-	storedFileURL := fmt.Sprintf("https:///%s.s3.%s.amazonaws.com/%s", bucketName, region, fileName)
+	storedFileURL := fmt.Sprintf("https://%s/%s", s3BucketHost(bucketName), fileName)
 
 	return presignedURL, storedFileURL, nil
 }
+
+// IsS3ObjectURL reports whether the URL points to an object of the bucket uploaded with a presigned URL of GetS3PresignedURL for the UID
+func IsS3ObjectURL(rawURL, bucketName, UID string) bool {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	// URLs stored before the host was fixed have an empty host and the host as the first segment of the path
+	host := parsedURL.Host
+	key := strings.TrimPrefix(parsedURL.Path, "/")
+	if host == "" {
+		host, key, _ = strings.Cut(key, "/")
+	}
+
+	return parsedURL.Scheme == "https" && host == s3BucketHost(bucketName) && strings.HasPrefix(key, strings.ReplaceAll(UID, " ", "")+"-")
+}
+
+// s3BucketHost returns the virtual hosted-style host of the bucket
+func s3BucketHost(bucketName string) string {
+	return fmt.Sprintf("%s.s3.%s.amazonaws.com", bucketName, s3Region)
+}
@@ -0,0 +1,62 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Gateway sends text messages. It is an interface so the provider (Twilio, Vonage, a local aggregator...) can be swapped without touching the services.
+type Gateway interface {
+	SendSMS(ctx context.Context, to string, message string) error
+}
+
+// HTTPGateway is a generic gateway that posts the message as JSON to the configured URL.
+// Most SMS providers (or a small proxy in front of them) accept a request of this shape.
+type HTTPGateway struct {
+	url    string
+	apiKey string
+	sender string
+	client *http.Client
+}
+
+type httpGatewayRequest struct {
+	To      string `json:"to"`
+	From    string `json:"from,omitempty"`
+	Message string `json:"message"`
+}
+
+func NewHTTPGateway(url string, apiKey string, sender string) *HTTPGateway {
+	return &HTTPGateway{url: url, apiKey: apiKey, sender: sender, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (g *HTTPGateway) SendSMS(ctx context.Context, to string, message string) error {
+	payload, err := json.Marshal(httpGatewayRequest{To: to, From: g.sender, Message: message})
+	if err != nil {
+		return fmt.Errorf("failed to encode sms request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create sms request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
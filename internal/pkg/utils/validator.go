@@ -1,10 +1,42 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 )
 
 // NewValidator returns a new instance of the validator.
+// Fields are named after their json tag in the validation errors so they match the request bodies
 func NewValidator() *validator.Validate {
-	return validator.New()
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return validate
+}
+
+// FormatValidationError describes which fields of a request body are invalid, e.g. "invalid request body: phone must be e164, radius must be min=1"
+func FormatValidationError(err error) string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return "invalid request body"
+	}
+
+	fields := make([]string, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		rule := fieldError.Tag()
+		if fieldError.Param() != "" {
+			rule += "=" + fieldError.Param()
+		}
+		fields[i] = fmt.Sprintf("%s must be %s", fieldError.Field(), rule)
+	}
+	return "invalid request body: " + strings.Join(fields, ", ")
 }
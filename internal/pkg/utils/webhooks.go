@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookAddressNotAllowed is returned when a webhook endpoint resolves to an address of our own network
+var ErrWebhookAddressNotAllowed = errors.New("webhook endpoint address is not allowed")
+
+// GenerateWebhookSecret returns a random secret used to sign the deliveries of a webhook endpoint
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// SignWebhookPayload returns the signature sent in the X-Tamra-Signature header.
+// The timestamp is signed with the body so receivers can reject replayed requests. Receivers compute
+// HMAC-SHA256(secret, "<X-Tamra-Timestamp>.<body>") and compare it with the hex encoded signature.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewWebhookHTTPClient returns the client used to send the deliveries.
+// Restaurants choose the endpoints, so the client refuses to connect to loopback, private and link-local addresses
+// (e.g. the instance metadata service). The address is checked when dialing, after the host is resolved, so a host
+// that resolves to a public address when the endpoint is created and to a private one later is refused as well.
+// Redirects go through the same dialer and must stay on https.
+func NewWebhookHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: checkWebhookAddress}
+	return &http.Client{
+		Timeout: timeout,
+		// No proxy, otherwise the dialer would only check the address of the proxy
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to %s is not https", req.URL.Redacted())
+			}
+			return nil
+		},
+	}
+}
+
+// checkWebhookAddress is called with the resolved address right before connecting
+func checkWebhookAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, host)
+	}
+	return nil
+}
+
+// nonPublicPrefixes are the ranges net.IP has no helper for: "this network", which reaches the host itself on Linux,
+// and the shared address space of carrier-grade NAT, which VPCs and overlay networks use for internal addresses
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// isPublicIP reports whether the address is reachable on the internet rather than on our own network
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	// IPv4-mapped IPv6 addresses are checked as the IPv4 address they map to
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	public := []string{"8.8.8.8", "1.1.1.1", "100.63.255.255", "100.128.0.0", "2606:4700:4700::1111"}
+	for _, address := range public {
+		assert.True(t, isPublicIP(net.ParseIP(address)), address)
+	}
+
+	notPublic := []string{
+		"127.0.0.1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "0.1.2.3",
+		"100.64.0.1", "100.127.255.255", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1", "::ffff:100.64.0.1", "224.0.0.1",
+	}
+	for _, address := range notPublic {
+		assert.False(t, isPublicIP(net.ParseIP(address)), address)
+	}
+}
+
+func TestCheckWebhookAddress(t *testing.T) {
+	err := checkWebhookAddress("tcp4", "8.8.8.8:443", nil)
+	assert.NoError(t, err)
+
+	err = checkWebhookAddress("tcp4", "100.64.0.1:443", nil)
+	assert.ErrorIs(t, err, ErrWebhookAddressNotAllowed)
+
+	err = checkWebhookAddress("tcp6", "[::1]:443", nil)
+	assert.ErrorIs(t, err, ErrWebhookAddressNotAllowed)
+
+	// Addresses that aren't IPs were not resolved, so they can't be checked
+	err = checkWebhookAddress("tcp4", "localhost:443", nil)
+	assert.ErrorIs(t, err, ErrWebhookAddressNotAllowed)
+}
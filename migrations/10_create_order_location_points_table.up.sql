@@ -0,0 +1,14 @@
+-- Positions of the driver while they carry an order, so the restaurant can follow the delivery.
+-- Points are purged after the retention period for privacy
+CREATE TABLE order_location_points (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    location GEOGRAPHY(Point, 4326) NOT NULL,
+    accuracy DOUBLE PRECISION,
+    recorded_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX order_location_points_order_id_index ON order_location_points (order_id, recorded_at);
+CREATE INDEX order_location_points_recorded_at_index ON order_location_points (recorded_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS driver_time_off;
+DROP TABLE IF EXISTS driver_schedules;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone;
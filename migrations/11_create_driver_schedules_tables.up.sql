@@ -0,0 +1,28 @@
+-- Drivers declare the weekly hours they work in their own timezone. Drivers without a schedule can receive orders at any time
+ALTER TABLE users
+ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- weekday follows EXTRACT(DOW), 0 is Sunday. A shift ends on the day it starts, overnight shifts are split in two
+CREATE TABLE driver_schedules (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL CHECK (end_time > start_time),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX driver_schedules_user_id_index ON driver_schedules (user_id);
+
+-- Time off overrides the weekly schedule, e.g. holidays or sick days
+CREATE TABLE driver_time_off (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL CHECK (ends_at > starts_at),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX driver_time_off_user_id_index ON driver_time_off (user_id, ends_at);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS max_concurrent_orders;
@@ -0,0 +1,3 @@
+-- How many PENDING or ACCEPTED orders a driver can have at the same time. Dispatch skips drivers at capacity
+ALTER TABLE users
+ADD COLUMN max_concurrent_orders INT NOT NULL DEFAULT 1 CHECK (max_concurrent_orders >= 1);
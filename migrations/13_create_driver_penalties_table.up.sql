@@ -0,0 +1,16 @@
+-- Penalties replace the silent deactivation of drivers who let orders expire. A driver with a penalty whose cooldown
+-- isn't over doesn't receive orders, and receives them again once it is over
+CREATE TABLE driver_penalties (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    order_id INT REFERENCES orders(id) ON DELETE SET NULL,
+    reason VARCHAR(50) NOT NULL,
+    offense_number INT NOT NULL,
+    cooldown_until TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE driver_penalties
+ADD CONSTRAINT driver_penalty_reason_check CHECK (reason IN ('ORDER_EXPIRED', 'ORDER_REASSIGNED'));
+
+CREATE INDEX driver_penalties_user_id_index ON driver_penalties (user_id, cooldown_until);
@@ -0,0 +1,4 @@
+DROP VIEW IF EXISTS driver_stats;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS accepted_at;
@@ -0,0 +1,37 @@
+-- When the driver accepted the order, to measure how quickly drivers respond
+ALTER TABLE orders
+ADD COLUMN accepted_at TIMESTAMP;
+
+-- Orders accepted before the column existed get their last update as an approximation
+UPDATE orders SET accepted_at = updated_at WHERE state IN ('ACCEPTED', 'FULFILLED');
+
+-- Performance of every driver over the last 30 days. Every order row is an offer to a single driver.
+-- The score is the acceptance rate times the fulfilment rate, both counted with 3 extra successful offers so new drivers
+-- start at 1 and a single miss doesn't sink their score
+CREATE VIEW driver_stats AS
+SELECT
+    user_id,
+    offers,
+    acceptances,
+    rejections,
+    expiries,
+    fulfilments,
+    median_seconds_to_accept,
+    acceptances::float8 / NULLIF(offers, 0) AS acceptance_rate,
+    fulfilments::float8 / NULLIF(completed_acceptances, 0) AS fulfilment_rate,
+    (acceptances + 3.0) / (offers + 3.0) * (fulfilments + 3.0) / (completed_acceptances + 3.0) AS score
+FROM (
+    SELECT
+        user_id,
+        COUNT(*) AS offers,
+        COUNT(*) FILTER (WHERE accepted_at IS NOT NULL) AS acceptances,
+        COUNT(*) FILTER (WHERE state = 'REJECTED') AS rejections,
+        COUNT(*) FILTER (WHERE state = 'EXPIRED' AND accepted_at IS NULL) AS expiries,
+        COUNT(*) FILTER (WHERE state = 'FULFILLED') AS fulfilments,
+        -- Accepted orders that are still being carried don't count towards the fulfilment rate yet
+        COUNT(*) FILTER (WHERE accepted_at IS NOT NULL AND state <> 'ACCEPTED') AS completed_acceptances,
+        PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM accepted_at - created_at)) AS median_seconds_to_accept
+    FROM orders
+    WHERE user_id IS NOT NULL AND created_at > CURRENT_TIMESTAMP - INTERVAL '30 days'
+    GROUP BY user_id
+) AS counts;
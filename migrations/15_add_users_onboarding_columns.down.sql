@@ -0,0 +1,4 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS id_photo_url,
+DROP COLUMN IF EXISTS vehicle_type,
+DROP COLUMN IF EXISTS status;
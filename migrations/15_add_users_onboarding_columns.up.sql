@@ -0,0 +1,9 @@
+-- Drivers go through an approval before receiving orders. Existing drivers were already receiving orders so they are approved,
+-- the default is changed afterwards so new drivers wait for an admin
+ALTER TABLE users
+ADD COLUMN status TEXT NOT NULL DEFAULT 'APPROVED' CHECK (status IN ('PENDING_APPROVAL', 'APPROVED', 'SUSPENDED')),
+ADD COLUMN vehicle_type TEXT CHECK (vehicle_type IN ('BICYCLE', 'MOTORCYCLE', 'CAR', 'VAN')),
+ADD COLUMN id_photo_url TEXT;
+
+ALTER TABLE users
+ALTER COLUMN status SET DEFAULT 'PENDING_APPROVAL';
@@ -0,0 +1,5 @@
+ALTER TABLE restaurants
+DROP COLUMN IF EXISTS default_vehicle;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS required_vehicle;
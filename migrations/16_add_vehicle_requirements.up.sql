@@ -0,0 +1,7 @@
+-- The smallest vehicle able to carry the order. Drivers with a smaller vehicle don't receive it
+ALTER TABLE orders
+ADD COLUMN required_vehicle TEXT CHECK (required_vehicle IN ('BICYCLE', 'MOTORCYCLE', 'CAR', 'VAN'));
+
+-- Required vehicle of the orders of the restaurant that don't set one
+ALTER TABLE restaurants
+ADD COLUMN default_vehicle TEXT CHECK (default_vehicle IN ('BICYCLE', 'MOTORCYCLE', 'CAR', 'VAN'));
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS restaurant_driver_preferences;
@@ -0,0 +1,14 @@
+-- Drivers a restaurant prefers to work with or blocked. Dispatch offers the orders of the restaurant to its preferred drivers
+-- first and never to the blocked ones
+CREATE TABLE restaurant_driver_preferences (
+    restaurant_id VARCHAR(255) NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    preference VARCHAR(50) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (restaurant_id, user_id)
+);
+
+ALTER TABLE restaurant_driver_preferences
+ADD CONSTRAINT restaurant_driver_preference_check CHECK (preference IN ('PREFERRED', 'BLOCKED'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS restaurant_invites;
+DROP TABLE IF EXISTS restaurant_members;
@@ -0,0 +1,35 @@
+-- Accounts that work for a restaurant. The owner is the account that created the restaurant, the staff join it by accepting an invite.
+-- An account works for a single restaurant, so the restaurant a request acts for is never ambiguous
+CREATE TABLE restaurant_members (
+    restaurant_id VARCHAR(255) NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    uid VARCHAR(255) NOT NULL UNIQUE,
+    role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (restaurant_id, uid)
+);
+
+ALTER TABLE restaurant_members
+ADD CONSTRAINT restaurant_member_role_check CHECK (role IN ('OWNER', 'MANAGER', 'CASHIER'));
+
+-- Until now a restaurant was only used by the account whose UID is its ID
+INSERT INTO restaurant_members (restaurant_id, uid, role)
+SELECT id, id, 'OWNER' FROM restaurants;
+
+-- Invites sent by the owners to their staff. The invited account joins the restaurant by accepting the invite with a verified email
+CREATE TABLE restaurant_invites (
+    id SERIAL PRIMARY KEY,
+    restaurant_id VARCHAR(255) NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    accepted_by VARCHAR(255),
+    accepted_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE restaurant_invites
+ADD CONSTRAINT restaurant_invite_role_check CHECK (role IN ('MANAGER', 'CASHIER'));
+
+CREATE INDEX restaurant_invites_email_idx ON restaurant_invites (email);
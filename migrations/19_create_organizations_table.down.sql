@@ -0,0 +1,4 @@
+ALTER TABLE restaurants
+DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organizations;
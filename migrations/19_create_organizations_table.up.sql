@@ -0,0 +1,15 @@
+-- Organizations group the restaurants of a chain, its branches. The restaurant that created the organization manages it and is its first branch,
+-- the other branches don't have an account of their own. Each branch has its own location so the orders are dispatched from it
+CREATE TABLE organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    owner_restaurant_id VARCHAR(255) NOT NULL UNIQUE REFERENCES restaurants(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Deleting the owner deletes the organization and its branches
+ALTER TABLE restaurants
+ADD COLUMN organization_id INT REFERENCES organizations(id) ON DELETE CASCADE;
+
+CREATE INDEX restaurants_organization_id_index ON restaurants (organization_id);
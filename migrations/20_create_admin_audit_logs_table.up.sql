@@ -0,0 +1,14 @@
+-- Changes made through the admin routes, including the failed attempts. The rows are only ever inserted
+CREATE TABLE admin_audit_logs (
+    id SERIAL PRIMARY KEY,
+    admin_uid VARCHAR(255) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    request_body JSONB,
+    status_code INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX admin_audit_logs_admin_uid_index ON admin_audit_logs (admin_uid);
+CREATE INDEX admin_audit_logs_created_at_index ON admin_audit_logs (created_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS restaurant_closures;
+DROP TABLE IF EXISTS restaurant_opening_hours;
+
+ALTER TABLE restaurants
+DROP COLUMN IF EXISTS is_paused,
+DROP COLUMN IF EXISTS timezone;
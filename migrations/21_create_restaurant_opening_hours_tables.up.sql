@@ -0,0 +1,30 @@
+-- Restaurants declare the weekly hours they take orders in their own timezone. Restaurants without opening hours are open at any time.
+-- A paused restaurant doesn't take orders until it is resumed, e.g. when the kitchen is overwhelmed
+ALTER TABLE restaurants
+ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+ADD COLUMN is_paused BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- weekday follows EXTRACT(DOW), 0 is Sunday. A period ends on the day it opens, overnight periods are split in two
+CREATE TABLE restaurant_opening_hours (
+    id SERIAL PRIMARY KEY,
+    restaurant_id VARCHAR(255) NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    open_time TIME NOT NULL,
+    close_time TIME NOT NULL CHECK (close_time > open_time),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX restaurant_opening_hours_restaurant_id_index ON restaurant_opening_hours (restaurant_id);
+
+-- Closures override the opening hours, e.g. holidays or renovations
+CREATE TABLE restaurant_closures (
+    id SERIAL PRIMARY KEY,
+    restaurant_id VARCHAR(255) NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL CHECK (ends_at > starts_at),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX restaurant_closures_restaurant_id_index ON restaurant_closures (restaurant_id, ends_at);
@@ -0,0 +1,12 @@
+-- Restaurants deliver in a zone, either a polygon or a circle around the restaurant. Dispatch only considers the drivers inside it,
+-- restaurants without a zone rely on the radius of the drivers alone
+CREATE TABLE restaurant_delivery_zones (
+    restaurant_id VARCHAR(255) PRIMARY KEY REFERENCES restaurants(id) ON DELETE CASCADE,
+    zone_type VARCHAR(16) NOT NULL CHECK (zone_type IN ('POLYGON', 'RADIUS')),
+    area GEOGRAPHY(Polygon, 4326),
+    -- The radius in meters follows the restaurant when it moves, so the circle isn't stored as a polygon
+    radius INT CHECK (radius > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((zone_type = 'POLYGON' AND area IS NOT NULL AND radius IS NULL) OR (zone_type = 'RADIUS' AND radius IS NOT NULL AND area IS NULL))
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_location_index;
+DROP INDEX IF EXISTS restaurants_location_index;
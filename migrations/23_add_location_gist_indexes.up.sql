@@ -0,0 +1,3 @@
+-- The distance searches (ST_DWithin) on the locations of the restaurants and of the drivers use these indexes
+CREATE INDEX restaurants_location_index ON restaurants USING GIST (location);
+CREATE INDEX users_location_index ON users USING GIST (location);
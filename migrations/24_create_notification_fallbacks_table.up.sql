@@ -0,0 +1,17 @@
+-- Fallback notifications waiting to be sent if the driver doesn't acknowledge the order in time.
+-- They are stored instead of kept in memory since Lambda instances don't outlive the request that notified the driver
+CREATE TABLE notification_fallbacks (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    channel VARCHAR(50) NOT NULL,
+    event VARCHAR(100) NOT NULL,
+    recipient_id VARCHAR(255) NOT NULL,
+    phone VARCHAR(255),
+    fcm_tokens TEXT[] NOT NULL DEFAULT '{}',
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    send_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX notification_fallbacks_send_at_index ON notification_fallbacks (send_at);
@@ -0,0 +1,4 @@
+-- Overnight shifts can't be stored anymore
+DELETE FROM driver_schedules WHERE end_time < start_time;
+ALTER TABLE driver_schedules DROP CONSTRAINT IF EXISTS driver_schedules_end_time_check;
+ALTER TABLE driver_schedules ADD CONSTRAINT driver_schedules_end_time_check CHECK (end_time > start_time);
@@ -0,0 +1,3 @@
+-- A shift that ends before its start time ends the day after it starts, e.g. 22:00 to 06:00
+ALTER TABLE driver_schedules DROP CONSTRAINT IF EXISTS driver_schedules_end_time_check;
+ALTER TABLE driver_schedules ADD CONSTRAINT driver_schedules_end_time_check CHECK (end_time <> start_time);
@@ -0,0 +1,4 @@
+-- Overnight periods can't be stored anymore
+DELETE FROM restaurant_opening_hours WHERE close_time < open_time;
+ALTER TABLE restaurant_opening_hours DROP CONSTRAINT IF EXISTS restaurant_opening_hours_close_time_check;
+ALTER TABLE restaurant_opening_hours ADD CONSTRAINT restaurant_opening_hours_close_time_check CHECK (close_time > open_time);
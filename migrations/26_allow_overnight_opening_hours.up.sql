@@ -0,0 +1,3 @@
+-- A period that closes before its open time closes the day after it opens, e.g. 18:00 to 02:00
+ALTER TABLE restaurant_opening_hours DROP CONSTRAINT IF EXISTS restaurant_opening_hours_close_time_check;
+ALTER TABLE restaurant_opening_hours ADD CONSTRAINT restaurant_opening_hours_close_time_check CHECK (close_time <> open_time);
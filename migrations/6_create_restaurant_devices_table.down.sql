@@ -0,0 +1 @@
+DROP TABLE IF EXISTS restaurant_devices;
@@ -0,0 +1,15 @@
+-- Devices (FCM tokens) a restaurant registered to receive push notifications on.
+-- FCM tokens are also issued to web push subscriptions through the Firebase JS SDK, so they share the same table.
+CREATE TABLE restaurant_devices (
+    id SERIAL PRIMARY KEY,
+    restaurant_id VARCHAR(255) NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    fcm_token TEXT UNIQUE NOT NULL,
+    platform VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE restaurant_devices
+ADD CONSTRAINT platform_check CHECK (platform IN ('ANDROID', 'IOS', 'WEB'));
+
+CREATE INDEX restaurant_devices_restaurant_id_index ON restaurant_devices (restaurant_id);
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+DROP COLUMN delivered_at,
+DROP COLUMN seen_at;
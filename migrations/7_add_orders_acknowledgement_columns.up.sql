@@ -0,0 +1,5 @@
+-- Every order row is an offer to a single driver (reassigning creates a new order), so the acknowledgement timestamps live on the order.
+-- delivered_at is set when the driver app receives the notification, seen_at when the driver opens the order.
+ALTER TABLE orders
+ADD COLUMN delivered_at TIMESTAMP,
+ADD COLUMN seen_at TIMESTAMP;
@@ -0,0 +1,45 @@
+-- Endpoints restaurants register to be called when their orders change
+CREATE TABLE webhook_endpoints (
+    id SERIAL PRIMARY KEY,
+    restaurant_id VARCHAR(255) NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX webhook_endpoints_restaurant_id_index ON webhook_endpoints (restaurant_id);
+
+-- One delivery per event and endpoint. It is retried until it succeeds or runs out of attempts
+CREATE TABLE webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_endpoint_id INT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    state VARCHAR(50) NOT NULL DEFAULT 'PENDING',
+    attempt_count INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE webhook_deliveries
+ADD CONSTRAINT webhook_delivery_state_check CHECK (state IN ('PENDING', 'SUCCEEDED', 'FAILED'));
+
+CREATE INDEX webhook_deliveries_endpoint_id_index ON webhook_deliveries (webhook_endpoint_id);
+CREATE INDEX webhook_deliveries_pending_index ON webhook_deliveries (next_attempt_at) WHERE state = 'PENDING';
+
+-- Every attempt of a delivery is logged so restaurants can debug their endpoints
+CREATE TABLE webhook_delivery_attempts (
+    id SERIAL PRIMARY KEY,
+    webhook_delivery_id INT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt_number INT NOT NULL,
+    status_code INT,
+    error TEXT,
+    duration_ms INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX webhook_delivery_attempts_delivery_id_index ON webhook_delivery_attempts (webhook_delivery_id);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS location_updated_at;
@@ -0,0 +1,5 @@
+-- When the driver's location was last recorded, so dispatch and the location endpoint can tell how fresh it is
+ALTER TABLE users
+ADD COLUMN location_updated_at TIMESTAMP;
+
+UPDATE users SET location_updated_at = updated_at;
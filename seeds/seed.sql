@@ -1,8 +1,8 @@
 -- Seed data for users table
-INSERT INTO users (id, location, is_active, fcm_token, phone, radius, last_order_received)
+INSERT INTO users (id, location, is_active, fcm_token, phone, radius, last_order_received, status, vehicle_type)
 VALUES
-    ('user1', ST_SetSRID(ST_MakePoint(-77.0364, 38.8951), 4326), true, 'token1', '+09055234232', 10, CURRENT_TIMESTAMP),
-    ('user2', ST_SetSRID(ST_MakePoint(-77.0364, 38.8951), 4326), true, 'token2', '+09055234234', 20, CURRENT_TIMESTAMP);
+    ('user1', ST_SetSRID(ST_MakePoint(-77.0364, 38.8951), 4326), true, 'token1', '+09055234232', 10, CURRENT_TIMESTAMP, 'APPROVED', 'MOTORCYCLE'),
+    ('user2', ST_SetSRID(ST_MakePoint(-77.0364, 38.8951), 4326), true, 'token2', '+09055234234', 20, CURRENT_TIMESTAMP, 'APPROVED', 'CAR');
     
 
 -- Seed data for restaurants table
@@ -11,6 +11,12 @@ VALUES
     ('restaurant1', 'restaurant1', 'https://www.google.com', ST_SetSRID(ST_MakePoint(-75.0364, 38.8951), 4326), '+09055234232', 'restaurant1 location'),
     ('restaurant2', 'restaurant2', 'https://www.google.com', ST_SetSRID(ST_MakePoint(-74.0364, 38.8951), 4326), '+09055234234', 'restaurant2 location');
 
+-- Seed data for restaurant_members table
+INSERT INTO restaurant_members (restaurant_id, uid, role)
+VALUES
+    ('restaurant1', 'restaurant1', 'OWNER'),
+    ('restaurant2', 'restaurant2', 'OWNER');
+
 -- Seed data for orders table
 INSERT INTO orders (id, code, description, state, user_id, restaurant_id)
 VALUES